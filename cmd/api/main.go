@@ -1,20 +1,34 @@
 package main
 
 import (
+	"errors"
 	"log"
+	"os"
 
 	"github.com/Arasy41/go-gin-quiz-api/config"
-	"github.com/Arasy41/go-gin-quiz-api/internal/delivery/middleware"
 	"github.com/Arasy41/go-gin-quiz-api/internal/delivery/router"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/internal/jobs"
 	"github.com/Arasy41/go-gin-quiz-api/pkg/db"
 	"github.com/Arasy41/go-gin-quiz-api/pkg/logger"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/metrics"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/storage"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// Load and validate configuration once; every component receives it explicitly
+	cfg, err := config.InitConfig(os.Args[1:])
+	if errors.Is(err, config.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
 	// Inisialisasi logger dengan file log di dalam folder logs
-	logDir := "logs"
-	if err := logger.InitLogger(logDir); err != nil {
+	if err := logger.InitLogger(cfg.LogDir); err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 	defer logger.CloseLogger()
@@ -22,27 +36,44 @@ func main() {
 	// Setup close handler untuk menangani signal SIGINT/SIGTERM
 	logger.SetupCloseHandler()
 
-	// Inisialisasi konfigurasi dan database
-	cfg := config.InitConfig()
+	// Inisialisasi database
 	db.InitDB(cfg)
+	metrics.InitMetrics(db.DB, repositories.NewAttemptRepository(db.DB).CountLiveAttempts)
 
-	// Initialize Environment
-	environment := cfg.Environment
+	// Storage for question attachments
+	store, err := storage.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize media storage: %v", err)
+	}
 
-	// Initialize router and start the server
-	r := router.InitRouter(db.DB)
+	// Scheduled purge of soft-deleted users, roles and categories
+	jobs.NewTrashPurgeJob(cfg,
+		usecases.NewUserUsecase(repositories.NewUserRepository(db.DB)),
+		usecases.NewRoleUsecase(repositories.NewRoleRepository(db.DB)),
+		usecases.NewCategoryUsecase(repositories.NewCategoryRepository(db.DB)),
+		usecases.NewAuditLogUsecase(repositories.NewAuditLogRepository(db.DB)),
+	).Start()
 
-	// Middleware for logging request
-	r.Use(middleware.RequestLogger())
+	// Scheduled recalibration of question parameters and difficulty
+	jobs.NewRecalibrationJob(cfg,
+		usecases.NewQuizUsecase(repositories.NewQuizRepository(db.DB), repositories.NewCategoryRepository(db.DB), repositories.NewTagRepository(db.DB), repositories.NewSearchRepository(db.DB), store, cfg.QuizReviewRequired),
+	).Start()
 
-	// Run server
-	if environment == "production" {
+	if cfg.Environment == config.EnvProduction {
 		gin.SetMode(gin.ReleaseMode)
-		if err := r.RunTLS(":"+cfg.Port, "cert.pem", "key.pem"); err != nil {
+	} else {
+		gin.SetMode(gin.DebugMode)
+	}
+
+	// Initialize router and start the server
+	r := router.InitRouter(cfg, db.DB, store)
+
+	// Run server
+	if cfg.Environment == config.EnvProduction {
+		if err := r.RunTLS(":"+cfg.Port, cfg.TLSCertFile, cfg.TLSKeyFile); err != nil {
 			log.Fatal("Failed to run server with TLS:", err)
 		}
-	} else if environment == "development" {
-		gin.SetMode(gin.DebugMode)
+	} else {
 		if err := r.Run(":" + cfg.Port); err != nil {
 			log.Fatal("Failed to run server:", err)
 		}
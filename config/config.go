@@ -1,15 +1,44 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// Supported values for ENVIRONMENT and DB_PROVIDER.
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+
+	ProviderSQLite   = "sqlite"
+	ProviderMySQL    = "mysql"
+	ProviderPostgres = "postgres"
+
+	StorageLocal = "local"
+	StorageS3    = "s3"
+
+	MinSecretKeyLength = 32
+)
+
+// ErrHelp is returned by InitConfig when --help was requested; the usage has
+// already been printed.
+var ErrHelp = pflag.ErrHelp
+
 type Config struct {
 	Host        string
 	Environment string
 	Port        string
+	TLSCertFile string
+	TLSKeyFile  string
+	LogDir      string
 
 	DBProvider string
 	DBHost     string
@@ -20,29 +49,313 @@ type Config struct {
 
 	SecretKey     string
 	TokenLifespan int
+
+	PublicURL          string
+	EmailVerifyTTLHour int
+
+	TrashRetentionDays     int
+	TrashPurgeIntervalHour int
+
+	RecalibrationIntervalHour int
+
+	QuizReviewRequired bool
+
+	MediaStorage       string
+	MediaDir           string
+	MediaMaxUploadMB   int
+	MediaURLTTLMinutes int
+	S3Endpoint         string
+	S3Region           string
+	S3Bucket           string
+	S3AccessKey        string
+	S3SecretKey        string
+	S3PathStyle        bool
+}
+
+// option describes a single configuration key: the environment variable
+// name, the command-line flag bound to it, its default and a short help text.
+type option struct {
+	key   string
+	flag  string
+	value string
+	usage string
+}
+
+// options is the documented list of every setting the application reads.
+// Values are resolved in order of precedence: flag, environment variable,
+// config file, default.
+var options = []option{
+	{"HOST", "host", "localhost", "host name the server is reachable at"},
+	{"ENVIRONMENT", "env", EnvDevelopment, "runtime environment (development, production)"},
+	{"PORT", "port", "8080", "port the HTTP server listens on"},
+	{"TLS_CERT_FILE", "tls-cert", "cert.pem", "TLS certificate file, required in production"},
+	{"TLS_KEY_FILE", "tls-key", "key.pem", "TLS private key file, required in production"},
+	{"LOG_DIR", "log-dir", "logs", "directory for daily log files"},
+
+	{"DB_PROVIDER", "db-provider", ProviderSQLite, "database provider (sqlite, mysql, postgres)"},
+	{"DB_HOST", "db-host", "", "database host"},
+	{"DB_USER", "db-user", "", "database user"},
+	{"DB_PASSWORD", "db-password", "", "database password"},
+	{"DB_NAME", "db-name", "quiz.db", "database name, or file path for sqlite"},
+	{"DB_PORT", "db-port", "", "database port"},
+
+	{"SECRET_KEY", "secret-key", "", "JWT signing key, at least 32 characters"},
+	{"TOKEN_HOUR_LIFESPAN", "token-lifespan", "24", "JWT lifespan in hours"},
+
+	{"PUBLIC_URL", "public-url", "http://localhost:8080", "base URL used in links sent by email"},
+	{"EMAIL_VERIFY_TTL_HOUR", "email-verify-ttl", "24", "hours an email change verification link stays valid"},
+
+	{"TRASH_RETENTION_DAYS", "trash-retention", "30", "days a soft-deleted user, role or category is kept before purge"},
+	{"TRASH_PURGE_INTERVAL_HOUR", "trash-purge-interval", "24", "hours between trash purge runs"},
+
+	{"RECALIBRATION_INTERVAL_HOUR", "recalibration-interval", "24", "hours between quiz recalibration runs"},
+
+	{"QUIZ_REVIEW_REQUIRED", "quiz-review-required", "false", "whether quizzes need a reviewer's approval before they are published"},
+
+	{"MEDIA_STORAGE", "media-storage", StorageLocal, "where question attachments are stored (local, s3)"},
+	{"MEDIA_DIR", "media-dir", "media", "directory for attachments with local storage"},
+	{"MEDIA_MAX_UPLOAD_MB", "media-max-upload", "10", "largest attachment accepted, in megabytes"},
+	{"MEDIA_URL_TTL_MINUTES", "media-url-ttl", "15", "minutes a signed attachment URL stays valid"},
+	{"S3_ENDPOINT", "s3-endpoint", "", "S3-compatible endpoint URL, required for s3 storage"},
+	{"S3_REGION", "s3-region", "us-east-1", "S3 region"},
+	{"S3_BUCKET", "s3-bucket", "", "S3 bucket, required for s3 storage"},
+	{"S3_ACCESS_KEY", "s3-access-key", "", "S3 access key, required for s3 storage"},
+	{"S3_SECRET_KEY", "s3-secret-key", "", "S3 secret key, required for s3 storage"},
+	{"S3_PATH_STYLE", "s3-path-style", "true", "address the bucket as endpoint/bucket rather than bucket.endpoint"},
 }
 
-func InitConfig() *Config {
-	viper.SetConfigFile(".env")
+// InitConfig loads the configuration from defaults, an optional config file,
+// environment variables and the given command-line arguments, then validates
+// the result. The config file defaults to .env and is skipped when absent
+// unless it was requested explicitly with --config.
+func InitConfig(args []string) (*Config, error) {
+	v := viper.New()
 
-	err := viper.ReadInConfig()
-	if err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
+	fs := pflag.NewFlagSet("go-gin-quiz-api", pflag.ContinueOnError)
+	configFile := fs.String("config", "", "path to a config file (default .env if present)")
+	for _, o := range options {
+		v.SetDefault(o.key, o.value)
+		fs.String(o.flag, o.value, o.usage+" ["+o.key+"]")
 	}
 
-	return &Config{
-		Host:        viper.GetString("HOST"),
-		Environment: viper.GetString("ENVIRONMENT"),
-		Port:        viper.GetString("PORT"),
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	for _, o := range options {
+		if err := v.BindPFlag(o.key, fs.Lookup(o.flag)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := readConfigFile(v, *configFile); err != nil {
+		return nil, err
+	}
+
+	v.AutomaticEnv()
+
+	cfg := &Config{
+		Host:        v.GetString("HOST"),
+		Environment: v.GetString("ENVIRONMENT"),
+		Port:        v.GetString("PORT"),
+		TLSCertFile: v.GetString("TLS_CERT_FILE"),
+		TLSKeyFile:  v.GetString("TLS_KEY_FILE"),
+		LogDir:      v.GetString("LOG_DIR"),
+
+		DBProvider: v.GetString("DB_PROVIDER"),
+		DBHost:     v.GetString("DB_HOST"),
+		DBUser:     v.GetString("DB_USER"),
+		DBPassword: v.GetString("DB_PASSWORD"),
+		DBName:     v.GetString("DB_NAME"),
+		DBPort:     v.GetString("DB_PORT"),
+
+		SecretKey: v.GetString("SECRET_KEY"),
+
+		PublicURL: strings.TrimRight(v.GetString("PUBLIC_URL"), "/"),
+
+		MediaStorage: v.GetString("MEDIA_STORAGE"),
+		MediaDir:     v.GetString("MEDIA_DIR"),
+		S3Endpoint:   v.GetString("S3_ENDPOINT"),
+		S3Region:     v.GetString("S3_REGION"),
+		S3Bucket:     v.GetString("S3_BUCKET"),
+		S3AccessKey:  v.GetString("S3_ACCESS_KEY"),
+		S3SecretKey:  v.GetString("S3_SECRET_KEY"),
+	}
+
+	// Malformed numbers are left at zero and reported by validate.
+	cfg.TokenLifespan, _ = strconv.Atoi(v.GetString("TOKEN_HOUR_LIFESPAN"))
+	cfg.EmailVerifyTTLHour, _ = strconv.Atoi(v.GetString("EMAIL_VERIFY_TTL_HOUR"))
+	cfg.TrashRetentionDays, _ = strconv.Atoi(v.GetString("TRASH_RETENTION_DAYS"))
+	cfg.TrashPurgeIntervalHour, _ = strconv.Atoi(v.GetString("TRASH_PURGE_INTERVAL_HOUR"))
+	cfg.RecalibrationIntervalHour, _ = strconv.Atoi(v.GetString("RECALIBRATION_INTERVAL_HOUR"))
+	cfg.MediaMaxUploadMB, _ = strconv.Atoi(v.GetString("MEDIA_MAX_UPLOAD_MB"))
+	cfg.MediaURLTTLMinutes, _ = strconv.Atoi(v.GetString("MEDIA_URL_TTL_MINUTES"))
+
+	problems := cfg.validate()
+	reviewRequired := v.GetString("QUIZ_REVIEW_REQUIRED")
+	var err error
+	if cfg.QuizReviewRequired, err = strconv.ParseBool(reviewRequired); err != nil {
+		problems = append(problems, fmt.Sprintf("QUIZ_REVIEW_REQUIRED %q must be true or false", reviewRequired))
+	}
+	pathStyle := v.GetString("S3_PATH_STYLE")
+	if cfg.S3PathStyle, err = strconv.ParseBool(pathStyle); err != nil {
+		problems = append(problems, fmt.Sprintf("S3_PATH_STYLE %q must be true or false", pathStyle))
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
 
-		DBProvider: viper.GetString("DB_PROVIDER"),
-		DBHost:     viper.GetString("DB_HOST"),
-		DBUser:     viper.GetString("DB_USER"),
-		DBPassword: viper.GetString("DB_PASSWORD"),
-		DBName:     viper.GetString("DB_NAME"),
-		DBPort:     viper.GetString("DB_PORT"),
+	return cfg, nil
+}
+
+func readConfigFile(v *viper.Viper, path string) error {
+	explicit := path != ""
+	if !explicit {
+		path = ".env"
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
 
-		SecretKey:     viper.GetString("SECRET_KEY"),
-		TokenLifespan: viper.GetInt("TOKEN_HOUR_LIFESPAN"),
+	v.SetConfigFile(path)
+	if filepath.Ext(path) == "" {
+		v.SetConfigType("env")
 	}
+
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("error loading config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// validate checks every setting and returns all problems found rather than
+// stopping at the first one.
+func (c *Config) validate() []string {
+	var problems []string
+
+	switch c.Environment {
+	case EnvDevelopment, EnvProduction:
+	default:
+		problems = append(problems, fmt.Sprintf("ENVIRONMENT %q is not one of %s, %s", c.Environment, EnvDevelopment, EnvProduction))
+	}
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("PORT %q must be a number between 1 and 65535", c.Port))
+	}
+
+	if c.Environment == EnvProduction {
+		problems = append(problems, checkFile("TLS_CERT_FILE", c.TLSCertFile)...)
+		problems = append(problems, checkFile("TLS_KEY_FILE", c.TLSKeyFile)...)
+	}
+
+	if c.LogDir == "" {
+		problems = append(problems, "LOG_DIR is required")
+	}
+
+	switch c.DBProvider {
+	case ProviderSQLite:
+		if c.DBName == "" {
+			problems = append(problems, "DB_NAME is required")
+		}
+	case ProviderMySQL, ProviderPostgres:
+		for _, kv := range [][2]string{
+			{"DB_HOST", c.DBHost},
+			{"DB_USER", c.DBUser},
+			{"DB_NAME", c.DBName},
+			{"DB_PORT", c.DBPort},
+		} {
+			if kv[1] == "" {
+				problems = append(problems, fmt.Sprintf("%s is required for DB_PROVIDER %s", kv[0], c.DBProvider))
+			}
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("DB_PROVIDER %q is not one of %s, %s, %s", c.DBProvider, ProviderSQLite, ProviderMySQL, ProviderPostgres))
+	}
+
+	if len(c.SecretKey) < MinSecretKeyLength {
+		problems = append(problems, fmt.Sprintf("SECRET_KEY must be at least %d characters", MinSecretKeyLength))
+	}
+
+	if c.TokenLifespan <= 0 {
+		problems = append(problems, "TOKEN_HOUR_LIFESPAN must be a positive whole number of hours")
+	}
+
+	if u, err := url.Parse(c.PublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, fmt.Sprintf("PUBLIC_URL %q must be an absolute http(s) URL", c.PublicURL))
+	}
+
+	if c.EmailVerifyTTLHour <= 0 {
+		problems = append(problems, "EMAIL_VERIFY_TTL_HOUR must be a positive whole number of hours")
+	}
+
+	if c.TrashRetentionDays <= 0 {
+		problems = append(problems, "TRASH_RETENTION_DAYS must be a positive whole number of days")
+	}
+
+	if c.TrashPurgeIntervalHour <= 0 {
+		problems = append(problems, "TRASH_PURGE_INTERVAL_HOUR must be a positive whole number of hours")
+	}
+
+	if c.RecalibrationIntervalHour <= 0 {
+		problems = append(problems, "RECALIBRATION_INTERVAL_HOUR must be a positive whole number of hours")
+	}
+
+	switch c.MediaStorage {
+	case StorageLocal:
+		if c.MediaDir == "" {
+			problems = append(problems, "MEDIA_DIR is required for MEDIA_STORAGE local")
+		}
+	case StorageS3:
+		for _, kv := range [][2]string{
+			{"S3_ENDPOINT", c.S3Endpoint},
+			{"S3_REGION", c.S3Region},
+			{"S3_BUCKET", c.S3Bucket},
+			{"S3_ACCESS_KEY", c.S3AccessKey},
+			{"S3_SECRET_KEY", c.S3SecretKey},
+		} {
+			if kv[1] == "" {
+				problems = append(problems, fmt.Sprintf("%s is required for MEDIA_STORAGE %s", kv[0], c.MediaStorage))
+			}
+		}
+		if c.S3Endpoint != "" {
+			if u, err := url.Parse(c.S3Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				problems = append(problems, fmt.Sprintf("S3_ENDPOINT %q must be an absolute http(s) URL", c.S3Endpoint))
+			}
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("MEDIA_STORAGE %q is not one of %s, %s", c.MediaStorage, StorageLocal, StorageS3))
+	}
+
+	if c.MediaMaxUploadMB <= 0 {
+		problems = append(problems, "MEDIA_MAX_UPLOAD_MB must be a positive whole number of megabytes")
+	}
+
+	if c.MediaURLTTLMinutes <= 0 {
+		problems = append(problems, "MEDIA_URL_TTL_MINUTES must be a positive whole number of minutes")
+	}
+
+	return problems
+}
+
+func checkFile(key, path string) []string {
+	if path == "" {
+		return []string{key + " is required in production"}
+	}
+	if _, err := os.Stat(path); err != nil {
+		return []string{fmt.Sprintf("%s %q is not readable: %v", key, path, err)}
+	}
+	return nil
+}
+
+// ValidationError lists every invalid setting found while loading the config.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
 }
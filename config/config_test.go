@@ -0,0 +1,162 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestInitConfigDefaults(t *testing.T) {
+	cfg, err := InitConfig([]string{"--secret-key", testSecret})
+	if err != nil {
+		t.Fatalf("InitConfig: %v", err)
+	}
+	if cfg.Environment != EnvDevelopment || cfg.Port != "8080" || cfg.DBProvider != ProviderSQLite || cfg.DBName != "quiz.db" {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+	if cfg.TokenLifespan != 24 || cfg.TrashRetentionDays != 30 || cfg.MediaMaxUploadMB != 10 {
+		t.Errorf("unexpected numeric defaults: %+v", cfg)
+	}
+	if cfg.QuizReviewRequired || !cfg.S3PathStyle {
+		t.Errorf("unexpected boolean defaults: %+v", cfg)
+	}
+}
+
+func TestInitConfigPrecedence(t *testing.T) {
+	t.Setenv("SECRET_KEY", testSecret)
+	t.Setenv("PORT", "9000")
+	t.Setenv("PUBLIC_URL", "https://quiz.example.com/")
+
+	cfg, err := InitConfig(nil)
+	if err != nil {
+		t.Fatalf("InitConfig: %v", err)
+	}
+	if cfg.Port != "9000" {
+		t.Errorf("Port = %q, want the environment's 9000", cfg.Port)
+	}
+	if cfg.PublicURL != "https://quiz.example.com" {
+		t.Errorf("PublicURL = %q, want the trailing slash trimmed", cfg.PublicURL)
+	}
+
+	cfg, err = InitConfig([]string{"--port", "9100"})
+	if err != nil {
+		t.Fatalf("InitConfig: %v", err)
+	}
+	if cfg.Port != "9100" {
+		t.Errorf("Port = %q, want the flag's 9100 over the environment", cfg.Port)
+	}
+}
+
+func TestInitConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quiz.env")
+	content := "SECRET_KEY=" + testSecret + "\nPORT=7000\nTRASH_RETENTION_DAYS=7\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := InitConfig([]string{"--config", path, "--port", "7100"})
+	if err != nil {
+		t.Fatalf("InitConfig: %v", err)
+	}
+	if cfg.TrashRetentionDays != 7 {
+		t.Errorf("TrashRetentionDays = %d, want 7 from the file", cfg.TrashRetentionDays)
+	}
+	if cfg.Port != "7100" {
+		t.Errorf("Port = %q, want the flag's 7100 over the file", cfg.Port)
+	}
+
+	if _, err := InitConfig([]string{"--config", filepath.Join(t.TempDir(), "missing.env")}); err == nil {
+		t.Error("InitConfig with a missing --config file succeeded")
+	}
+}
+
+func TestInitConfigHelp(t *testing.T) {
+	if _, err := InitConfig([]string{"--help"}); !errors.Is(err, ErrHelp) {
+		t.Errorf("InitConfig(--help) = %v, want ErrHelp", err)
+	}
+}
+
+func TestInitConfigValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"short secret", []string{"--secret-key", "short"}, []string{"SECRET_KEY must be at least 32 characters"}},
+		{"unknown environment", []string{"--env", "staging"}, []string{`ENVIRONMENT "staging"`}},
+		{"port out of range", []string{"--port", "70000"}, []string{`PORT "70000"`}},
+		{"port not a number", []string{"--port", "http"}, []string{`PORT "http"`}},
+		{"production without TLS files", []string{"--env", "production", "--tls-cert", "", "--tls-key", "/nonexistent/key.pem"}, []string{
+			"TLS_CERT_FILE is required in production",
+			`TLS_KEY_FILE "/nonexistent/key.pem" is not readable`,
+		}},
+		{"unknown provider", []string{"--db-provider", "oracle"}, []string{`DB_PROVIDER "oracle"`}},
+		{"postgres without connection", []string{"--db-provider", "postgres", "--db-name", ""}, []string{
+			"DB_HOST is required for DB_PROVIDER postgres",
+			"DB_USER is required for DB_PROVIDER postgres",
+			"DB_NAME is required for DB_PROVIDER postgres",
+			"DB_PORT is required for DB_PROVIDER postgres",
+		}},
+		{"sqlite without file", []string{"--db-name", ""}, []string{"DB_NAME is required"}},
+		{"token lifespan not a number", []string{"--token-lifespan", "a day"}, []string{"TOKEN_HOUR_LIFESPAN must be a positive"}},
+		{"relative public URL", []string{"--public-url", "quiz.example.com"}, []string{`PUBLIC_URL "quiz.example.com"`}},
+		{"zero retention", []string{"--trash-retention", "0"}, []string{"TRASH_RETENTION_DAYS must be a positive"}},
+		{"negative recalibration", []string{"--recalibration-interval", "-1"}, []string{"RECALIBRATION_INTERVAL_HOUR must be a positive"}},
+		{"bad boolean", []string{"--quiz-review-required", "sometimes"}, []string{`QUIZ_REVIEW_REQUIRED "sometimes" must be true or false`}},
+		{"s3 without bucket", []string{"--media-storage", "s3", "--s3-endpoint", "minio:9000"}, []string{
+			"S3_BUCKET is required for MEDIA_STORAGE s3",
+			"S3_ACCESS_KEY is required for MEDIA_STORAGE s3",
+			"S3_SECRET_KEY is required for MEDIA_STORAGE s3",
+			`S3_ENDPOINT "minio:9000" must be an absolute http(s) URL`,
+		}},
+		{"unknown storage", []string{"--media-storage", "ftp"}, []string{`MEDIA_STORAGE "ftp"`}},
+		{"zero upload size", []string{"--media-max-upload", "0"}, []string{"MEDIA_MAX_UPLOAD_MB must be a positive"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--secret-key", testSecret}, tt.args...)
+			_, err := InitConfig(args)
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("InitConfig(%q) = %v, want a ValidationError", tt.args, err)
+			}
+			if len(verr.Problems) != len(tt.want) {
+				t.Errorf("got %d problems, want %d: %q", len(verr.Problems), len(tt.want), verr.Problems)
+			}
+			for _, want := range tt.want {
+				if !containsProblem(verr.Problems, want) {
+					t.Errorf("no problem mentions %q in %q", want, verr.Problems)
+				}
+			}
+		})
+	}
+}
+
+func TestInitConfigReportsEveryProblem(t *testing.T) {
+	_, err := InitConfig([]string{"--env", "staging", "--port", "0", "--db-provider", "oracle"})
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("InitConfig = %v, want a ValidationError", err)
+	}
+	for _, want := range []string{"ENVIRONMENT", "PORT", "DB_PROVIDER", "SECRET_KEY"} {
+		if !containsProblem(verr.Problems, want) {
+			t.Errorf("no problem mentions %s in %q", want, verr.Problems)
+		}
+	}
+}
+
+func containsProblem(problems []string, want string) bool {
+	for _, p := range problems {
+		if strings.Contains(p, want) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,261 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/gin-gonic/gin"
+)
+
+type AssignmentHandler interface {
+	GetAssignments(c *gin.Context)
+	GetAssignment(c *gin.Context)
+	CreateAssignment(c *gin.Context)
+	UpdateAssignment(c *gin.Context)
+	DeleteAssignment(c *gin.Context)
+	GetGrades(c *gin.Context)
+	GetDueAssignments(c *gin.Context)
+}
+
+type assignmentHandler struct {
+	AssignmentUc usecases.AssignmentUsecase
+	AuditUc      usecases.AuditLogUsecase
+}
+
+func NewAssignmentHandler(uc usecases.AssignmentUsecase, auditUc usecases.AuditLogUsecase) AssignmentHandler {
+	return &assignmentHandler{
+		AssignmentUc: uc,
+		AuditUc:      auditUc,
+	}
+}
+
+// GetAssignments godoc
+// @Summary Get assignments
+// @Description List the assignments created by the teacher, or all of them for an admin, soonest deadline first
+// @Tags Assignment
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {array} models.Assignment
+// @Failure 401 {object} apperror.Problem
+// @Router /teacher/assignments [get]
+func (h *assignmentHandler) GetAssignments(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	assignments, err := h.AssignmentUc.GetAssignments(actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"assignments": assignments})
+}
+
+// GetAssignment godoc
+// @Summary Get assignment by id
+// @Description Get an assignment with its schedule and target
+// @Tags Assignment
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Assignment ID"
+// @Success 200 {object} models.Assignment
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/assignment/{id} [get]
+func (h *assignmentHandler) GetAssignment(c *gin.Context) {
+	actor, id, ok := h.assignmentParams(c)
+	if !ok {
+		return
+	}
+
+	assignment, err := h.AssignmentUc.GetAssignment(actor, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"assignment": assignment})
+}
+
+// CreateAssignment godoc
+// @Summary Create assignment
+// @Description Assign a quiz to a classroom or to a list of students, with an optional window, attempt limit, scoring policy (best, last, average) and late penalty in percent
+// @Tags Assignment
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param Body body models.AssignmentRequest true "the assignment to create"
+// @Success 201 {object} models.Assignment
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/assignment [post]
+func (h *assignmentHandler) CreateAssignment(c *gin.Context) {
+	var req models.AssignmentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	assignment, err := h.AssignmentUc.CreateAssignment(actor, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionCreate,
+		Entity:   constant.AuditEntityAssignment,
+		EntityID: auditID(assignment.ID),
+		After:    assignment,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"assignment": assignment})
+}
+
+// UpdateAssignment godoc
+// @Summary Update assignment
+// @Description Replace an assignment's quiz, target and schedule
+// @Tags Assignment
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Assignment ID"
+// @Param Body body models.AssignmentRequest true "the assignment fields"
+// @Success 200 {object} models.Assignment
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/assignment/{id} [put]
+func (h *assignmentHandler) UpdateAssignment(c *gin.Context) {
+	actor, id, ok := h.assignmentParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.AssignmentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	before, err := h.AssignmentUc.GetAssignment(actor, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	snapshot := *before
+
+	assignment, err := h.AssignmentUc.UpdateAssignment(actor, id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionUpdate,
+		Entity:   constant.AuditEntityAssignment,
+		EntityID: auditID(id),
+		Before:   snapshot,
+		After:    assignment,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"assignment": assignment})
+}
+
+// DeleteAssignment godoc
+// @Summary Delete assignment
+// @Description Delete an assignment. Attempts already made are kept.
+// @Tags Assignment
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Assignment ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/assignment/{id} [delete]
+func (h *assignmentHandler) DeleteAssignment(c *gin.Context) {
+	actor, id, ok := h.assignmentParams(c)
+	if !ok {
+		return
+	}
+
+	assignment, err := h.AssignmentUc.DeleteAssignment(actor, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionDelete,
+		Entity:   constant.AuditEntityAssignment,
+		EntityID: auditID(id),
+		Before:   assignment,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Assignment deleted successfully"})
+}
+
+// GetGrades godoc
+// @Summary Get assignment grades
+// @Description List every targeted student with their attempt count and grade under the scoring policy
+// @Tags Assignment
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Assignment ID"
+// @Success 200 {array} models.StudentGrade
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/assignment/{id}/grades [get]
+func (h *assignmentHandler) GetGrades(c *gin.Context) {
+	actor, id, ok := h.assignmentParams(c)
+	if !ok {
+		return
+	}
+
+	grades, err := h.AssignmentUc.GetGrades(actor, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"grades": grades})
+}
+
+// GetDueAssignments godoc
+// @Summary Get due assignments
+// @Description List the logged-in student's upcoming, open and late-open assignments that still have attempts left
+// @Tags Assignment
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {array} models.DueAssignment
+// @Failure 401 {object} apperror.Problem
+// @Router /student/assignments/due [get]
+func (h *assignmentHandler) GetDueAssignments(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	due, err := h.AssignmentUc.GetDueAssignments(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"assignments": due})
+}
+
+func (h *assignmentHandler) assignmentParams(c *gin.Context) (models.Actor, uint, bool) {
+	id, ok := paramID(c, "id", "assignment")
+	if !ok {
+		return models.Actor{}, 0, false
+	}
+	actor, ok := currentActor(c)
+	return actor, id, ok
+}
@@ -0,0 +1,148 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AttachmentHandler interface {
+	UploadAttachment(c *gin.Context)
+	DeleteAttachment(c *gin.Context)
+}
+
+type attachmentHandler struct {
+	AttachmentUc usecases.AttachmentUsecase
+	AuditUc      usecases.AuditLogUsecase
+	MaxSize      int64
+}
+
+// NewAttachmentHandler returns the attachment handler, refusing request
+// bodies much larger than maxSize bytes before they are read.
+func NewAttachmentHandler(uc usecases.AttachmentUsecase, auditUc usecases.AuditLogUsecase, maxSize int64) AttachmentHandler {
+	return &attachmentHandler{
+		AttachmentUc: uc,
+		AuditUc:      auditUc,
+		MaxSize:      maxSize,
+	}
+}
+
+// UploadAttachment godoc
+// @Summary Upload attachment
+// @Description Attach an image (PNG, JPEG, GIF, WebP) or audio file (MP3, WAV, OGG, M4A) to a question, or to one of its options with option_id. The type is detected from the content. Images get a thumbnail. Attachments change like questions: on drafts and draft revisions, while the quiz has no attempts. The response carries signed URLs that expire.
+// @Tags Quiz
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Param question_id path string true "Question ID"
+// @Param file formData file true "Image or audio file"
+// @Param option_id formData string false "Option ID"
+// @Success 201 {object} models.Attachment
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/question/{question_id}/attachments [post]
+func (h *attachmentHandler) UploadAttachment(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+	questionID, ok := paramUUID(c, "question_id", "question")
+	if !ok {
+		return
+	}
+
+	// Leave room for the multipart framing and the option ID.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.MaxSize+64<<10)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.Error(apperror.Validation("file is too large", apperror.FieldError{Field: "file", Rule: "max", Message: fmt.Sprintf("must be at most %d MB", h.MaxSize>>20)}))
+			return
+		}
+		c.Error(apperror.Validation("file is required", apperror.FieldError{Field: "file", Rule: "required", Message: "must be an image or audio file"}))
+		return
+	}
+
+	upload := &models.AttachmentUpload{Filename: file.Filename, Size: file.Size}
+	if value := c.PostForm("option_id"); value != "" {
+		optionID, err := uuid.Parse(value)
+		if err != nil {
+			c.Error(apperror.Validation("invalid option ID", apperror.FieldError{Field: "option_id", Rule: "uuid", Message: "must be a UUID"}))
+			return
+		}
+		upload.OptionID = &optionID
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer f.Close()
+	upload.File = f
+
+	attachment, err := h.AttachmentUc.UploadAttachment(id, questionID, upload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionCreate,
+		Entity:   constant.AuditEntityAttachment,
+		EntityID: attachment.ID.String(),
+		After:    attachment,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"attachment": attachment})
+}
+
+// DeleteAttachment godoc
+// @Summary Delete attachment
+// @Description Remove an attachment from a question of a draft quiz or draft revision. Its files are deleted unless copies or versions of the question still use them.
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Param attachment_id path string true "Attachment ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/attachment/{attachment_id} [delete]
+func (h *attachmentHandler) DeleteAttachment(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+	attachmentID, ok := paramUUID(c, "attachment_id", "attachment")
+	if !ok {
+		return
+	}
+
+	attachment, err := h.AttachmentUc.DeleteAttachment(id, attachmentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionDelete,
+		Entity:   constant.AuditEntityAttachment,
+		EntityID: attachment.ID.String(),
+		Before:   attachment,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
@@ -0,0 +1,315 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AttemptHandler interface {
+	StartAssignmentAttempt(c *gin.Context)
+	StartPractice(c *gin.Context)
+	RetryMissed(c *gin.Context)
+	GetAttempt(c *gin.Context)
+	GetMyAttempts(c *gin.Context)
+	AnswerQuestion(c *gin.Context)
+	FinishAttempt(c *gin.Context)
+	ReviewAttempt(c *gin.Context)
+}
+
+type attemptHandler struct {
+	AttemptUc usecases.AttemptUsecase
+}
+
+func NewAttemptHandler(uc usecases.AttemptUsecase) AttemptHandler {
+	return &attemptHandler{AttemptUc: uc}
+}
+
+// StartAssignmentAttempt godoc
+// @Summary Start assignment attempt
+// @Description Start an attempt at an assignment while it is open and attempts are left. An attempt already in progress is returned with 200 instead.
+// @Tags Attempt
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Assignment ID"
+// @Success 201 {object} models.AttemptView
+// @Success 200 {object} models.AttemptView
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /student/assignment/{id}/attempts [post]
+func (h *attemptHandler) StartAssignmentAttempt(c *gin.Context) {
+	id, ok := paramID(c, "id", "assignment")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	attempt, resumed, err := h.AttemptUc.StartAssignmentAttempt(userID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if resumed {
+		c.JSON(http.StatusOK, gin.H{"attempt": attempt})
+		return
+	}
+
+	metrics.AttemptsStarted.Inc()
+	c.JSON(http.StatusCreated, gin.H{"attempt": attempt})
+}
+
+// GetAttempt godoc
+// @Summary Get attempt
+// @Description Get one of the logged-in student's attempts with its questions and current answers
+// @Tags Attempt
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Attempt ID"
+// @Success 200 {object} models.AttemptView
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /student/attempt/{id} [get]
+func (h *attemptHandler) GetAttempt(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "attempt")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	attempt, err := h.AttemptUc.GetAttempt(userID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attempt": attempt})
+}
+
+// GetMyAttempts godoc
+// @Summary Get my attempts
+// @Description List the logged-in student's attempts, newest first, with their scores once released
+// @Tags Attempt
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param quiz_id query string false "Quiz ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {array} models.AttemptSummary
+// @Failure 400 {object} apperror.Problem
+// @Failure 401 {object} apperror.Problem
+// @Router /student/attempts [get]
+func (h *attemptHandler) GetMyAttempts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	filter := models.AttemptFilter{}
+	if quizID := c.Query("quiz_id"); quizID != "" {
+		id, err := uuid.Parse(quizID)
+		if err != nil {
+			c.Error(apperror.Validation("invalid quiz ID", apperror.FieldError{Field: "quiz_id", Rule: "uuid", Message: "must be a valid UUID"}))
+			return
+		}
+		filter.QuizID = &id
+	}
+	filter.Page, _ = strconv.Atoi(c.Query("page"))
+	filter.PageSize, _ = strconv.Atoi(c.Query("page_size"))
+
+	attempts, total, err := h.AttemptUc.GetMyAttempts(userID, filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attempts": attempts, "total": total})
+}
+
+// StartPractice godoc
+// @Summary Start practice
+// @Description Start an ungraded practice attempt at the multiple choice questions of a published quiz or one from the student's classrooms or assignments. A practice attempt in progress is returned with 200 instead.
+// @Tags Attempt
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Success 201 {object} models.AttemptView
+// @Success 200 {object} models.AttemptView
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /student/quiz/{id}/practice [post]
+func (h *attemptHandler) StartPractice(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	attempt, resumed, err := h.AttemptUc.StartPractice(userID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if resumed {
+		c.JSON(http.StatusOK, gin.H{"attempt": attempt})
+		return
+	}
+
+	metrics.AttemptsStarted.Inc()
+	c.JSON(http.StatusCreated, gin.H{"attempt": attempt})
+}
+
+// RetryMissed godoc
+// @Summary Retry missed questions
+// @Description Start a practice attempt at the questions missed in a finished practice attempt
+// @Tags Attempt
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Attempt ID"
+// @Success 201 {object} models.AttemptView
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /student/attempt/{id}/retry [post]
+func (h *attemptHandler) RetryMissed(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "attempt")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	attempt, err := h.AttemptUc.RetryMissed(userID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	metrics.AttemptsStarted.Inc()
+	c.JSON(http.StatusCreated, gin.H{"attempt": attempt})
+}
+
+// AnswerQuestion godoc
+// @Summary Answer question
+// @Description Choose an option for a question of a running attempt; answering again replaces the earlier choice. In a practice attempt each question is answered once and the response carries the feedback.
+// @Tags Attempt
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Attempt ID"
+// @Param Body body models.AnswerRequest true "question and chosen option"
+// @Success 200 {object} models.AnswerFeedback
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /student/attempt/{id}/answer [put]
+func (h *attemptHandler) AnswerQuestion(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "attempt")
+	if !ok {
+		return
+	}
+
+	var req models.AnswerRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	feedback, err := h.AttemptUc.AnswerQuestion(userID, id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if feedback != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Answer saved", "feedback": feedback})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Answer saved"})
+}
+
+// FinishAttempt godoc
+// @Summary Finish attempt
+// @Description Submit an attempt for scoring. Submissions after the assignment closes are marked late and penalized. The score is only included when the quiz releases it.
+// @Tags Attempt
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Attempt ID"
+// @Success 200 {object} models.AttemptView
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /student/attempt/{id}/finish [post]
+func (h *attemptHandler) FinishAttempt(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "attempt")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	attempt, err := h.AttemptUc.FinishAttempt(userID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	metrics.AttemptsFinished.Inc()
+	c.JSON(http.StatusOK, gin.H{"attempt": attempt})
+}
+
+// ReviewAttempt godoc
+// @Summary Review attempt
+// @Description Review a finished attempt. The score, which questions were wrong and the correct answers are included as the quiz's release policies allow.
+// @Tags Attempt
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Attempt ID"
+// @Success 200 {object} models.AttemptReview
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /student/attempt/{id}/review [get]
+func (h *attemptHandler) ReviewAttempt(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "attempt")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	review, err := h.AttemptUc.ReviewAttempt(userID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"review": review})
+}
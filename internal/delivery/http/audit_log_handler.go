@@ -0,0 +1,120 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogHandler interface {
+	GetAuditLogs(c *gin.Context)
+}
+
+type auditLogHandler struct {
+	AuditUc usecases.AuditLogUsecase
+}
+
+func NewAuditLogHandler(uc usecases.AuditLogUsecase) AuditLogHandler {
+	return &auditLogHandler{
+		AuditUc: uc,
+	}
+}
+
+// GetAuditLogs godoc
+// @Summary Get audit logs
+// @Description List recorded administrative and auth actions, newest first
+// @Tags audit
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param actor_id query int false "Actor user ID"
+// @Param action query string false "Action, e.g. create, update, delete, login"
+// @Param entity query string false "Entity, e.g. user, role, category, auth"
+// @Param entity_id query string false "Entity ID"
+// @Param from query string false "RFC3339 lower bound on created_at"
+// @Param to query string false "RFC3339 upper bound on created_at"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {array} models.AuditLog
+// @Failure 400 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
+// @Router /cms/audit-logs [get]
+func (h *auditLogHandler) GetAuditLogs(c *gin.Context) {
+	filter := models.AuditLogFilter{
+		Action:   c.Query("action"),
+		Entity:   c.Query("entity"),
+		EntityID: c.Query("entity_id"),
+	}
+
+	if actorID := c.Query("actor_id"); actorID != "" {
+		id, err := strconv.ParseUint(actorID, 10, 64)
+		if err != nil {
+			c.Error(apperror.Validation("invalid actor ID", apperror.FieldError{Field: "actor_id", Rule: "id", Message: "must be a positive integer"}))
+			return
+		}
+		filter.ActorID = uint(id)
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.Error(err)
+		return
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.Error(err)
+		return
+	}
+
+	filter.Page, _ = strconv.Atoi(c.Query("page"))
+	filter.PageSize, _ = strconv.Atoi(c.Query("page_size"))
+
+	logs, total, err := h.AuditUc.GetAuditLogs(filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"audit_logs": logs, "total": total})
+}
+
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, apperror.Validation("invalid "+key+" time", apperror.FieldError{Field: key, Rule: "datetime", Message: "must be an RFC3339 timestamp"})
+	}
+	return &t, nil
+}
+
+// recordAudit stores an audit entry for the current request, filling in the
+// actor, client IP and request ID from the context. Failures are only logged
+// so that auditing never breaks the action being audited.
+func recordAudit(c *gin.Context, uc usecases.AuditLogUsecase, entry models.AuditEntry) {
+	if entry.ActorID == 0 {
+		if userID, ok := c.Get("user_id"); ok {
+			entry.ActorID, _ = userID.(uint)
+		}
+	}
+	if entry.ActorRole == "" {
+		entry.ActorRole = c.GetString("user_role")
+	}
+	entry.IP = c.ClientIP()
+	entry.RequestID = c.GetString("request_id")
+
+	if err := uc.Record(entry); err != nil {
+		log.Printf("Failed to record audit log %s.%s: %v", entry.Entity, entry.Action, err)
+	}
+}
+
+func auditID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
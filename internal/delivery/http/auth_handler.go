@@ -1,15 +1,15 @@
 package http
 
 import (
-	"errors"
 	"log"
 	"net/http"
 
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
 	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
 	"github.com/Arasy41/go-gin-quiz-api/pkg/jwt"
-	"github.com/Arasy41/go-gin-quiz-api/pkg/utils"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/metrics"
 	"github.com/Arasy41/go-gin-quiz-api/pkg/validator"
 	"github.com/gin-gonic/gin"
 )
@@ -22,12 +22,16 @@ type AuthHandler interface {
 }
 
 type authHandler struct {
-	userUsecase usecases.UserUsecase
+	userUsecase  usecases.UserUsecase
+	auditUsecase usecases.AuditLogUsecase
+	tokens       *jwt.JWT
 }
 
-func NewAuthHandler(uc usecases.UserUsecase) AuthHandler {
+func NewAuthHandler(uc usecases.UserUsecase, auditUc usecases.AuditLogUsecase, tokens *jwt.JWT) AuthHandler {
 	return &authHandler{
-		userUsecase: uc,
+		userUsecase:  uc,
+		auditUsecase: auditUc,
+		tokens:       tokens,
 	}
 }
 
@@ -38,43 +42,48 @@ func NewAuthHandler(uc usecases.UserUsecase) AuthHandler {
 // @Param Body body models.LoginRequest true "the body to login a user"
 // @Produce json
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 401 {object} apperror.Problem
 // @Router /api/auth/login [post]
 func (h *authHandler) Login(c *gin.Context) {
-	var user *models.User
 	var req models.LoginRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	validate := validator.NewValidator()
 	if err := validator.ValidateStruct(validate, &req); err != nil {
-		log.Println(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	user, err := h.userUsecase.GetUserByUsername(req.Username)
+	user, err := h.userUsecase.Login(req.Username, req.Password)
 	if err != nil {
-		log.Println(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if apperror.Is(err, apperror.KindUnauthorized) {
+			log.Println("Invalid username or password")
+			metrics.LoginsTotal.WithLabelValues(metrics.LoginFailed).Inc()
+			h.auditLoginFailed(c, req.Username)
+		}
+		c.Error(err)
 		return
 	}
 
-	if user == nil || !utils.CheckPasswordHash(req.Password, user.Password) {
-		log.Println("Invalid username or password")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid username or password"})
-		return
-	}
-
-	token, err := jwt.GenerateToken(user.ID, user.Role.Name)
+	token, err := h.tokens.GenerateToken(user.ID, user.Role.Name)
 	if err != nil {
-		log.Println(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(apperror.Internal(err))
 		return
 	}
 
+	metrics.LoginsTotal.WithLabelValues(metrics.LoginSucceeded).Inc()
+	recordAudit(c, h.auditUsecase, models.AuditEntry{
+		ActorID:   user.ID,
+		ActorRole: user.Role.Name,
+		Action:    constant.AuditActionLogin,
+		Entity:    constant.AuditEntityAuth,
+		EntityID:  auditID(user.ID),
+	})
+
 	c.JSON(http.StatusOK, gin.H{"token": token})
 }
 
@@ -88,9 +97,10 @@ func (h *authHandler) Login(c *gin.Context) {
 // @Param email body string true "Email address for the new user"
 // @Param password body string true "Password for the new user (8-32 characters)"
 // @Param role_name body string true "Role name for the new user (e.g., 'user', 'admin')"
-// @Success 201 {object} models.User
-// @Failure 400 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Success 201 {object} models.UserResponse
+// @Failure 400 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
 // @Router /api/auth/register [post]
 func (h *authHandler) Register(c *gin.Context) {
 	var input struct {
@@ -100,50 +110,32 @@ func (h *authHandler) Register(c *gin.Context) {
 		RoleName string `json:"role_name" binding:"required"`
 	}
 
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	validate := validator.NewValidator()
-	if err := validator.ValidateStruct(validate, &input); err != nil {
-		log.Println(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	_, err := h.userUsecase.GetUserByUsername(input.Username)
-	if err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Username already exists"})
-		return
-	}
-
-	_, err = h.userUsecase.GetUserByEmail(input.Email)
-	if err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email already exists"})
-		return
-	}
-
-	// Check length password
-	if len(input.Password) < constant.MinPasswordLength || len(input.Password) > constant.MaxPasswordLength {
-		log.Println(len(input.Password), "Password must be at least between 8 and 32 characters")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Password must be at least between 8 and 32 characters"})
+	if !bindJSON(c, &input) {
 		return
 	}
 
 	roleId, err := defineRoles(input.RoleName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	user, err := h.userUsecase.CreateUser(input.Username, input.Email, input.Password, roleId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"user": user})
+	recordAudit(c, h.auditUsecase, models.AuditEntry{
+		ActorID:   user.ID,
+		ActorRole: input.RoleName,
+		Action:    constant.AuditActionRegister,
+		Entity:    constant.AuditEntityUser,
+		EntityID:  auditID(user.ID),
+		After:     user,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"user": models.NewUserResponse(user)})
 }
 
 // ChangePassword godoc
@@ -156,8 +148,9 @@ func (h *authHandler) Register(c *gin.Context) {
 // @Param old_password body string true "Old password from user data"
 // @Param new_password body string true "New Password for user""
 // @Success 201 {string} Password changed susccesfully
-// @Failure 400 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 401 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
 // @Router /api/auth/change-password [post]
 func (h *authHandler) ChangePassword(c *gin.Context) {
 	type ChangePasswordInput struct {
@@ -167,23 +160,27 @@ func (h *authHandler) ChangePassword(c *gin.Context) {
 
 	var input ChangePasswordInput
 
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &input) {
 		return
 	}
 
-	userID, exists := c.Get("user_id") // Assuming JWT middleware sets userID in context
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	err := h.userUsecase.ChangePassword(userID.(uint), input.OldPassword, input.NewPassword)
+	err := h.userUsecase.ChangePassword(userID, input.OldPassword, input.NewPassword)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	recordAudit(c, h.auditUsecase, models.AuditEntry{
+		Action:   constant.AuditActionChangePassword,
+		Entity:   constant.AuditEntityUser,
+		EntityID: auditID(userID),
+	})
+
 	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
 }
 
@@ -194,46 +191,50 @@ func (h *authHandler) ChangePassword(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer Token"
-// @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Success 200 {object} models.UserResponse
+// @Failure 401 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
 // @Security ApiKeyAuth
 // @Router /api/detail-user [get]
 func (h *authHandler) GetCurrentUser(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
-		return
-	}
-
-	// Convert userID to uint
-	userIDUint, ok := userID.(uint)
+	userID, ok := currentUserID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID type"})
 		return
 	}
 
 	// Get user data by user ID
-	user, err := h.userUsecase.GetUserByID(userIDUint)
+	user, err := h.userUsecase.GetUserByID(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	// Send user data as response
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	c.JSON(http.StatusOK, gin.H{"user": models.NewUserResponse(user)})
 }
 
-func defineRoles(role string) (uint, error) {
-	var err error
+// auditLoginFailed records a rejected login; the attempted username is kept
+// as the entity ID since there may be no matching user.
+func (h *authHandler) auditLoginFailed(c *gin.Context, username string) {
+	recordAudit(c, h.auditUsecase, models.AuditEntry{
+		Action:   constant.AuditActionLoginFailed,
+		Entity:   constant.AuditEntityAuth,
+		EntityID: username,
+	})
+}
 
+func defineRoles(role string) (uint, error) {
 	switch role {
 	case constant.RoleStudent:
-		return constant.RoleStudentID, err
+		return constant.RoleStudentID, nil
 	case constant.RoleTeacher:
-		return constant.RoleTeacherID, err
+		return constant.RoleTeacherID, nil
 	default:
-		return 0, errors.New("invalid role name")
+		return 0, apperror.Validation("invalid role name", apperror.FieldError{
+			Field:   "role_name",
+			Rule:    "oneof",
+			Message: "must be one of: " + constant.RoleStudent + " " + constant.RoleTeacher,
+		})
 	}
 }
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/gin-gonic/gin"
+)
+
+type CatalogHandler interface {
+	GetCatalog(c *gin.Context)
+	GetCatalogQuiz(c *gin.Context)
+}
+
+type catalogHandler struct {
+	CatalogUc usecases.CatalogUsecase
+}
+
+func NewCatalogHandler(uc usecases.CatalogUsecase) CatalogHandler {
+	return &catalogHandler{CatalogUc: uc}
+}
+
+// GetCatalog godoc
+// @Summary Get quiz catalog
+// @Description List the published quizzes by title with their category, difficulty, duration and question count, optionally limited to a category and its subcategories, to any or all of the given tags and to a difficulty
+// @Tags Catalog
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param category_id query int false "Category ID"
+// @Param category query string false "Category slug"
+// @Param tags query string false "Comma-separated tags"
+// @Param match query string false "any (default) or all of the tags"
+// @Param difficulty query string false "easy, medium or hard"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {array} models.CatalogQuiz
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /student/catalog [get]
+func (h *catalogHandler) GetCatalog(c *gin.Context) {
+	quizFilter, ok := quizFilter(c)
+	if !ok {
+		return
+	}
+	filter := models.CatalogFilter{QuizFilter: quizFilter, Difficulty: c.Query("difficulty")}
+	switch filter.Difficulty {
+	case "", constant.DifficultyEasy, constant.DifficultyMedium, constant.DifficultyHard:
+	default:
+		c.Error(apperror.Validation("invalid difficulty", apperror.FieldError{Field: "difficulty", Rule: "oneof", Message: "must be one of: easy medium hard"}))
+		return
+	}
+	filter.Page, _ = strconv.Atoi(c.Query("page"))
+	filter.PageSize, _ = strconv.Atoi(c.Query("page_size"))
+
+	quizzes, total, err := h.CatalogUc.GetCatalog(filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quizzes": quizzes, "total": total})
+}
+
+// GetCatalogQuiz godoc
+// @Summary Get catalog quiz
+// @Description Get a published quiz with its questions and options, without the correct answers
+// @Tags Catalog
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} models.CatalogQuizDetail
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /student/catalog/quiz/{id} [get]
+func (h *catalogHandler) GetCatalogQuiz(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+
+	quiz, err := h.CatalogUc.GetCatalogQuiz(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quiz": quiz})
+}
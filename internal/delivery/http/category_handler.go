@@ -2,10 +2,10 @@ package http
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,17 +14,24 @@ type CategoryHandler interface {
 	UpdateCategory(c *gin.Context)
 	DeleteCategory(c *gin.Context)
 	GetCategoryByID(c *gin.Context)
-	GetCategoryByName(c *gin.Context)
+	GetCategoryBySlug(c *gin.Context)
 	GetAllCategories(c *gin.Context)
+	MoveCategory(c *gin.Context)
+	MergeCategory(c *gin.Context)
+	GetTrashedCategories(c *gin.Context)
+	RestoreCategory(c *gin.Context)
+	PurgeCategory(c *gin.Context)
 }
 
 type categoryHandler struct {
 	usecase usecases.CategoryUsecase
+	auditUc usecases.AuditLogUsecase
 }
 
-func NewCategoryHandler(uc usecases.CategoryUsecase) CategoryHandler {
+func NewCategoryHandler(uc usecases.CategoryUsecase, auditUc usecases.AuditLogUsecase) CategoryHandler {
 	return &categoryHandler{
 		usecase: uc,
+		auditUc: auditUc,
 	}
 }
 
@@ -35,14 +42,14 @@ func NewCategoryHandler(uc usecases.CategoryUsecase) CategoryHandler {
 // @Accept json
 // @Produce json
 // @Success 200 {array} models.Category
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
 // @Router /cms/categories [get]
 func (h *categoryHandler) GetAllCategories(c *gin.Context) {
 	categories, err := h.usecase.GetAllCategories()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, categories)
@@ -56,21 +63,27 @@ func (h *categoryHandler) GetAllCategories(c *gin.Context) {
 // @Produce json
 // @Param category body models.Category true "Category"
 // @Success 200 {object} models.Category
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
 // @Router /cms/category [post]
 func (h *categoryHandler) CreateCategory(c *gin.Context) {
 	var category *models.Category
-	if err := c.ShouldBindJSON(&category); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &category) {
 		return
 	}
 	category, err := h.usecase.CreateCategory(category)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
+
+	recordAudit(c, h.auditUc, models.AuditEntry{
+		Action:   constant.AuditActionCreate,
+		Entity:   constant.AuditEntityCategory,
+		EntityID: auditID(category.ID),
+		After:    category,
+	})
 	c.JSON(http.StatusOK, category)
 }
 
@@ -82,38 +95,44 @@ func (h *categoryHandler) CreateCategory(c *gin.Context) {
 // @Produce json
 // @Param category body models.Category true "Category"
 // @Success 200 {object} models.Category
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
 // @Router /cms/category [put]
 func (h *categoryHandler) UpdateCategory(c *gin.Context) {
 	var input models.Category
-	id, err := strconv.Atoi(c.Param("id"))
-
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+	id, ok := paramID(c, "id", "category")
+	if !ok {
 		return
 	}
 
-	categoryID, err := h.usecase.GetCategoryByID(uint(id))
+	categoryID, err := h.usecase.GetCategoryByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		c.Error(err)
 		return
 	}
 
+	before := *categoryID
 	input.ID = categoryID.ID
 
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &input) {
 		return
 	}
 
 	category, err := h.usecase.UpdateCategory(&input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	recordAudit(c, h.auditUc, models.AuditEntry{
+		Action:   constant.AuditActionUpdate,
+		Entity:   constant.AuditEntityCategory,
+		EntityID: auditID(category.ID),
+		Before:   before,
+		After:    category,
+	})
+
 	c.JSON(http.StatusOK, gin.H{"category": category})
 }
 
@@ -125,48 +144,140 @@ func (h *categoryHandler) UpdateCategory(c *gin.Context) {
 // @Produce json
 // @Param category_id path int true "Category ID"
 // @Success 200 {object} models.Category
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
 // @Router /cms/category/{category_id} [get]
 func (h *categoryHandler) GetCategoryByID(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
+	id, ok := paramID(c, "id", "category")
+	if !ok {
+		return
+	}
+
+	category, err := h.usecase.GetCategoryByID(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		c.Error(err)
 		return
 	}
 
-	category, err := h.usecase.GetCategoryByID(uint(id))
+	c.JSON(http.StatusOK, gin.H{"category": category})
+}
+
+// Get Category By Slug
+// @Summary Get Category by slug
+// @Description Get Category by slug
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param slug path string true "Category Slug"
+// @Success 200 {object} models.Category
+// @Failure 404 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
+// @Router /cms/category/slug/{slug} [get]
+func (h *categoryHandler) GetCategoryBySlug(c *gin.Context) {
+	category, err := h.usecase.GetCategoryBySlug(c.Param("slug"))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"category": category})
 }
 
-// Get Category By Name
-// @Summary Get Category by name
-// @Description Get Category by name
+// MoveCategory godoc
+// @Summary Move category
+// @Description Move a category, with its subcategories, under another parent or to the top level when parent_id is null
 // @Tags categories
 // @Accept json
 // @Produce json
-// @Param category_name path string true "Category Name"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param id path int true "Category ID"
+// @Param Body body models.MoveCategoryRequest true "the new parent"
 // @Success 200 {object} models.Category
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
-// @Router /cms/category/{category_name} [get]
-func (h *categoryHandler) GetCategoryByName(c *gin.Context) {
-	name := c.Param("name")
-	category, err := h.usecase.GetCategoryByName(name)
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
+// @Router /cms/category/{id}/move [post]
+func (h *categoryHandler) MoveCategory(c *gin.Context) {
+	id, ok := paramID(c, "id", "category")
+	if !ok {
+		return
+	}
+
+	var req models.MoveCategoryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	before, err := h.usecase.GetCategoryByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		c.Error(err)
 		return
 	}
 
+	category, err := h.usecase.MoveCategory(id, req.ParentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.auditUc, models.AuditEntry{
+		Action:   constant.AuditActionMove,
+		Entity:   constant.AuditEntityCategory,
+		EntityID: auditID(category.ID),
+		Before:   before,
+		After:    category,
+	})
+
 	c.JSON(http.StatusOK, gin.H{"category": category})
+}
+
+// MergeCategory godoc
+// @Summary Merge category
+// @Description Move a category's quizzes, templates and subcategories to the target category, then move the category to the trash
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param id path int true "Category ID"
+// @Param Body body models.MergeCategoryRequest true "the target category"
+// @Success 200 {object} models.Category
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
+// @Router /cms/category/{id}/merge [post]
+func (h *categoryHandler) MergeCategory(c *gin.Context) {
+	id, ok := paramID(c, "id", "category")
+	if !ok {
+		return
+	}
+
+	var req models.MergeCategoryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	source, err := h.usecase.GetCategoryByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	target, err := h.usecase.MergeCategory(id, req.TargetID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.auditUc, models.AuditEntry{
+		Action:   constant.AuditActionMerge,
+		Entity:   constant.AuditEntityCategory,
+		EntityID: auditID(source.ID),
+		Before:   source,
+		After:    target,
+	})
 
+	c.JSON(http.StatusOK, gin.H{"category": target})
 }
 
 // Delete Category By ID
@@ -177,28 +288,129 @@ func (h *categoryHandler) GetCategoryByName(c *gin.Context) {
 // @Produce json
 // @Param category_id path int true "Category ID"
 // @Success 200 {object} models.Category
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
 // @Router /cms/category/{category_id} [delete]
 func (h *categoryHandler) DeleteCategory(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+	id, ok := paramID(c, "id", "category")
+	if !ok {
 		return
 	}
 
-	categoryID, err := h.usecase.GetCategoryByID(uint(id))
+	categoryID, err := h.usecase.GetCategoryByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		c.Error(err)
 		return
 	}
 
 	err = h.usecase.DeleteCategory(categoryID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	recordAudit(c, h.auditUc, models.AuditEntry{
+		Action:   constant.AuditActionDelete,
+		Entity:   constant.AuditEntityCategory,
+		EntityID: auditID(categoryID.ID),
+		Before:   categoryID,
+	})
+
 	c.JSON(http.StatusOK, gin.H{"message": "category deleted successfully"})
 }
+
+// GetTrashedCategories godoc
+// @Summary Get trashed categories
+// @Description List soft-deleted categories, most recently deleted first
+// @Tags categories
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {array} models.Category
+// @Failure 500 {object} apperror.Problem
+// @Router /cms/trash/categories [get]
+func (h *categoryHandler) GetTrashedCategories(c *gin.Context) {
+	categories, err := h.usecase.GetTrashedCategories()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": categories})
+}
+
+// RestoreCategory godoc
+// @Summary Restore category
+// @Description Restore a soft-deleted category, optionally renaming it to resolve a unique-name conflict
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param id path int true "Category ID"
+// @Success 200 {object} models.Category
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
+// @Router /cms/trash/category/{id}/restore [post]
+func (h *categoryHandler) RestoreCategory(c *gin.Context) {
+	id, ok := paramID(c, "id", "category")
+	if !ok {
+		return
+	}
+
+	var input restoreInput
+	if !bindOptionalJSON(c, &input) {
+		return
+	}
+
+	category, err := h.usecase.RestoreCategory(id, input.Name)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.auditUc, models.AuditEntry{
+		Action:   constant.AuditActionRestore,
+		Entity:   constant.AuditEntityCategory,
+		EntityID: auditID(category.ID),
+		After:    category,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"category": category})
+}
+
+// PurgeCategory godoc
+// @Summary Purge category
+// @Description Permanently delete a soft-deleted category
+// @Tags categories
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param id path int true "Category ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
+// @Router /cms/trash/category/{id} [delete]
+func (h *categoryHandler) PurgeCategory(c *gin.Context) {
+	id, ok := paramID(c, "id", "category")
+	if !ok {
+		return
+	}
+
+	category, err := h.usecase.PurgeCategory(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.auditUc, models.AuditEntry{
+		Action:   constant.AuditActionPurge,
+		Entity:   constant.AuditEntityCategory,
+		EntityID: auditID(category.ID),
+		Before:   category,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category purged successfully"})
+}
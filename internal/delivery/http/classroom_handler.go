@@ -0,0 +1,614 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/gin-gonic/gin"
+)
+
+type ClassroomHandler interface {
+	GetClassrooms(c *gin.Context)
+	GetClassroom(c *gin.Context)
+	CreateClassroom(c *gin.Context)
+	UpdateClassroom(c *gin.Context)
+	DeleteClassroom(c *gin.Context)
+	RegenerateJoinCode(c *gin.Context)
+	GetRoster(c *gin.Context)
+	EnrollStudents(c *gin.Context)
+	ImportRoster(c *gin.Context)
+	RemoveStudent(c *gin.Context)
+	GetClassroomQuizzes(c *gin.Context)
+	AssignQuiz(c *gin.Context)
+	UnassignQuiz(c *gin.Context)
+	GetResults(c *gin.Context)
+	JoinClassroom(c *gin.Context)
+	GetStudentClassrooms(c *gin.Context)
+	LeaveClassroom(c *gin.Context)
+}
+
+type classroomHandler struct {
+	ClassroomUc usecases.ClassroomUsecase
+	AuditUc     usecases.AuditLogUsecase
+}
+
+func NewClassroomHandler(uc usecases.ClassroomUsecase, auditUc usecases.AuditLogUsecase) ClassroomHandler {
+	return &classroomHandler{
+		ClassroomUc: uc,
+		AuditUc:     auditUc,
+	}
+}
+
+// GetClassrooms godoc
+// @Summary Get classrooms
+// @Description List the teacher's classrooms, or every classroom for an admin
+// @Tags Classroom
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {array} models.ClassroomList
+// @Failure 401 {object} apperror.Problem
+// @Router /teacher/classrooms [get]
+func (h *classroomHandler) GetClassrooms(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	classrooms, err := h.ClassroomUc.GetClassrooms(actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"classrooms": classrooms})
+}
+
+// GetClassroom godoc
+// @Summary Get classroom by id
+// @Description Get a classroom with its join code
+// @Tags Classroom
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Classroom ID"
+// @Success 200 {object} models.Classroom
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/classroom/{id} [get]
+func (h *classroomHandler) GetClassroom(c *gin.Context) {
+	actor, id, ok := h.classroomParams(c)
+	if !ok {
+		return
+	}
+
+	classroom, err := h.ClassroomUc.GetClassroom(actor, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"classroom": classroom})
+}
+
+// CreateClassroom godoc
+// @Summary Create classroom
+// @Description Create a classroom owned by the logged-in teacher. A join code is generated.
+// @Tags Classroom
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param Body body models.ClassroomRequest true "the classroom to create"
+// @Success 201 {object} models.Classroom
+// @Failure 400 {object} apperror.Problem
+// @Router /teacher/classroom [post]
+func (h *classroomHandler) CreateClassroom(c *gin.Context) {
+	var req models.ClassroomRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	classroom, err := h.ClassroomUc.CreateClassroom(actor, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.audit(c, constant.AuditActionCreate, classroom.ID, nil, classroom)
+
+	c.JSON(http.StatusCreated, gin.H{"classroom": classroom})
+}
+
+// UpdateClassroom godoc
+// @Summary Update classroom
+// @Description Rename a classroom or change its description
+// @Tags Classroom
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Classroom ID"
+// @Param Body body models.ClassroomRequest true "the classroom fields"
+// @Success 200 {object} models.Classroom
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/classroom/{id} [put]
+func (h *classroomHandler) UpdateClassroom(c *gin.Context) {
+	actor, id, ok := h.classroomParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.ClassroomRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	before, err := h.ClassroomUc.GetClassroom(actor, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	snapshot := *before
+
+	classroom, err := h.ClassroomUc.UpdateClassroom(actor, id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.audit(c, constant.AuditActionUpdate, id, snapshot, classroom)
+
+	c.JSON(http.StatusOK, gin.H{"classroom": classroom})
+}
+
+// DeleteClassroom godoc
+// @Summary Delete classroom
+// @Description Delete a classroom along with its roster and quiz assignments. Attempts already made are kept.
+// @Tags Classroom
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Classroom ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/classroom/{id} [delete]
+func (h *classroomHandler) DeleteClassroom(c *gin.Context) {
+	actor, id, ok := h.classroomParams(c)
+	if !ok {
+		return
+	}
+
+	classroom, err := h.ClassroomUc.DeleteClassroom(actor, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.audit(c, constant.AuditActionDelete, id, classroom, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Classroom deleted successfully"})
+}
+
+// RegenerateJoinCode godoc
+// @Summary Regenerate join code
+// @Description Replace the classroom's join code; the old one stops working
+// @Tags Classroom
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Classroom ID"
+// @Success 200 {object} models.Classroom
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/classroom/{id}/join-code [post]
+func (h *classroomHandler) RegenerateJoinCode(c *gin.Context) {
+	actor, id, ok := h.classroomParams(c)
+	if !ok {
+		return
+	}
+
+	classroom, err := h.ClassroomUc.RegenerateJoinCode(actor, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.audit(c, constant.AuditActionRegenerateCode, id, nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"classroom": classroom})
+}
+
+// GetRoster godoc
+// @Summary Get roster
+// @Description List the students enrolled in a classroom
+// @Tags Classroom
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Classroom ID"
+// @Success 200 {array} models.RosterEntry
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/classroom/{id}/students [get]
+func (h *classroomHandler) GetRoster(c *gin.Context) {
+	actor, id, ok := h.classroomParams(c)
+	if !ok {
+		return
+	}
+
+	roster, err := h.ClassroomUc.GetRoster(actor, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"students": roster})
+}
+
+// EnrollStudents godoc
+// @Summary Enroll students
+// @Description Enroll students by username or email. Unknown users, non-students and existing members are reported as skipped.
+// @Tags Classroom
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Classroom ID"
+// @Param Body body models.EnrollRequest true "usernames or emails"
+// @Success 200 {object} models.EnrollmentResult
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/classroom/{id}/students [post]
+func (h *classroomHandler) EnrollStudents(c *gin.Context) {
+	actor, id, ok := h.classroomParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.EnrollRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.ClassroomUc.EnrollStudents(actor, id, req.Students)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.auditEnrollment(c, id, result)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ImportRoster godoc
+// @Summary Import roster from CSV
+// @Description Enroll the students listed in a CSV file, one username or email per line in the first column. Send it as the multipart field "file" or as a text/csv body.
+// @Tags Classroom
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Classroom ID"
+// @Param file formData file false "CSV roster"
+// @Success 200 {object} models.EnrollmentResult
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/classroom/{id}/students/import [post]
+func (h *classroomHandler) ImportRoster(c *gin.Context) {
+	actor, id, ok := h.classroomParams(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, constant.MaxRosterCSVSize)
+
+	var csvFile io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.Error(apperror.Validation("CSV file is required", apperror.FieldError{
+				Field:   "file",
+				Rule:    "required",
+				Message: "must be a CSV file of at most 1 MB",
+			}))
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		defer f.Close()
+		csvFile = f
+	}
+
+	result, err := h.ClassroomUc.ImportRoster(actor, id, csvFile)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.auditEnrollment(c, id, result)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RemoveStudent godoc
+// @Summary Remove student
+// @Description Remove a student from the classroom roster
+// @Tags Classroom
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Classroom ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/classroom/{id}/student/{user_id} [delete]
+func (h *classroomHandler) RemoveStudent(c *gin.Context) {
+	actor, id, ok := h.classroomParams(c)
+	if !ok {
+		return
+	}
+	userID, ok := paramID(c, "user_id", "user")
+	if !ok {
+		return
+	}
+
+	if err := h.ClassroomUc.RemoveStudent(actor, id, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.audit(c, constant.AuditActionUnenroll, id, map[string]uint{"user_id": userID}, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Student removed successfully"})
+}
+
+// GetClassroomQuizzes godoc
+// @Summary Get classroom quizzes
+// @Description List the quizzes assigned to a classroom
+// @Tags Classroom
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Classroom ID"
+// @Success 200 {array} models.QuizList
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/classroom/{id}/quizzes [get]
+func (h *classroomHandler) GetClassroomQuizzes(c *gin.Context) {
+	actor, id, ok := h.classroomParams(c)
+	if !ok {
+		return
+	}
+
+	quizzes, err := h.ClassroomUc.GetClassroomQuizzes(actor, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quizzes": quizzes})
+}
+
+// AssignQuiz godoc
+// @Summary Assign quiz
+// @Description Assign a quiz to a classroom
+// @Tags Classroom
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Classroom ID"
+// @Param Body body models.AssignQuizRequest true "the quiz to assign"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /teacher/classroom/{id}/quizzes [post]
+func (h *classroomHandler) AssignQuiz(c *gin.Context) {
+	actor, id, ok := h.classroomParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.AssignQuizRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.ClassroomUc.AssignQuiz(actor, id, req.QuizID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.audit(c, constant.AuditActionAssign, id, nil, req)
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Quiz assigned successfully"})
+}
+
+// UnassignQuiz godoc
+// @Summary Unassign quiz
+// @Description Remove a quiz from a classroom
+// @Tags Classroom
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Classroom ID"
+// @Param quiz_id path string true "Quiz ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/classroom/{id}/quiz/{quiz_id} [delete]
+func (h *classroomHandler) UnassignQuiz(c *gin.Context) {
+	actor, id, ok := h.classroomParams(c)
+	if !ok {
+		return
+	}
+	quizID, ok := paramUUID(c, "quiz_id", "quiz")
+	if !ok {
+		return
+	}
+
+	if err := h.ClassroomUc.UnassignQuiz(actor, id, quizID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.audit(c, constant.AuditActionUnassign, id, models.AssignQuizRequest{QuizID: quizID}, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quiz unassigned successfully"})
+}
+
+// GetResults godoc
+// @Summary Get classroom results
+// @Description List attempts by the classroom's students at the quizzes assigned to it
+// @Tags Classroom
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Classroom ID"
+// @Success 200 {array} models.ClassroomResult
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/classroom/{id}/results [get]
+func (h *classroomHandler) GetResults(c *gin.Context) {
+	actor, id, ok := h.classroomParams(c)
+	if !ok {
+		return
+	}
+
+	results, err := h.ClassroomUc.GetResults(actor, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// JoinClassroom godoc
+// @Summary Join classroom
+// @Description Join a classroom with the code given by the teacher
+// @Tags Classroom
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param Body body models.JoinClassroomRequest true "join code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /student/classrooms/join [post]
+func (h *classroomHandler) JoinClassroom(c *gin.Context) {
+	var req models.JoinClassroomRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	classroom, err := h.ClassroomUc.JoinClassroom(userID, req.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.audit(c, constant.AuditActionJoin, classroom.ID, nil, map[string]uint{"user_id": userID})
+
+	c.JSON(http.StatusOK, gin.H{"classroom": models.ClassroomList{
+		ID:        classroom.ID,
+		Name:      classroom.Name,
+		TeacherID: classroom.TeacherID,
+	}})
+}
+
+// GetStudentClassrooms godoc
+// @Summary Get my classrooms
+// @Description List the classrooms the logged-in student is enrolled in
+// @Tags Classroom
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {array} models.ClassroomList
+// @Failure 401 {object} apperror.Problem
+// @Router /student/classrooms [get]
+func (h *classroomHandler) GetStudentClassrooms(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	classrooms, err := h.ClassroomUc.GetStudentClassrooms(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"classrooms": classrooms})
+}
+
+// LeaveClassroom godoc
+// @Summary Leave classroom
+// @Description Leave a classroom the logged-in student is enrolled in
+// @Tags Classroom
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Classroom ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /student/classroom/{id} [delete]
+func (h *classroomHandler) LeaveClassroom(c *gin.Context) {
+	id, ok := paramID(c, "id", "classroom")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ClassroomUc.LeaveClassroom(userID, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.audit(c, constant.AuditActionLeave, id, map[string]uint{"user_id": userID}, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left classroom successfully"})
+}
+
+func (h *classroomHandler) classroomParams(c *gin.Context) (models.Actor, uint, bool) {
+	id, ok := paramID(c, "id", "classroom")
+	if !ok {
+		return models.Actor{}, 0, false
+	}
+	actor, ok := currentActor(c)
+	return actor, id, ok
+}
+
+func (h *classroomHandler) audit(c *gin.Context, action string, id uint, before, after interface{}) {
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   action,
+		Entity:   constant.AuditEntityClassroom,
+		EntityID: auditID(id),
+		Before:   before,
+		After:    after,
+	})
+}
+
+func (h *classroomHandler) auditEnrollment(c *gin.Context, id uint, result *models.EnrollmentResult) {
+	if len(result.Enrolled) == 0 {
+		return
+	}
+	userIDs := make([]uint, 0, len(result.Enrolled))
+	for _, e := range result.Enrolled {
+		userIDs = append(userIDs, e.UserID)
+	}
+	h.audit(c, constant.AuditActionEnroll, id, nil, map[string][]uint{"user_ids": userIDs})
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/gin-gonic/gin"
+)
+
+type GradingHandler interface {
+	GetQueue(c *gin.Context)
+	GradeAnswer(c *gin.Context)
+	RegradeQuiz(c *gin.Context)
+}
+
+type gradingHandler struct {
+	GradingUc usecases.GradingUsecase
+	AuditUc   usecases.AuditLogUsecase
+}
+
+func NewGradingHandler(uc usecases.GradingUsecase, auditUc usecases.AuditLogUsecase) GradingHandler {
+	return &gradingHandler{
+		GradingUc: uc,
+		AuditUc:   auditUc,
+	}
+}
+
+// GetQueue godoc
+// @Summary Get grading queue
+// @Description List the ungraded essay answers of finished attempts at the teacher's assignments, or at all assignments for an admin, oldest first
+// @Tags Grading
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {array} models.GradingItem
+// @Failure 401 {object} apperror.Problem
+// @Router /teacher/grading [get]
+func (h *gradingHandler) GetQueue(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.GradingUc.GetQueue(actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"answers": items})
+}
+
+// GradeAnswer godoc
+// @Summary Grade essay answer
+// @Description Score an essay answer against every criterion of its rubric. Grading the attempt's last essay releases its score and emails the student.
+// @Tags Grading
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Answer ID"
+// @Param Body body models.GradeAnswerRequest true "the rubric scores"
+// @Success 200 {object} models.Answer
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /teacher/grading/answer/{id} [post]
+func (h *gradingHandler) GradeAnswer(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "answer")
+	if !ok {
+		return
+	}
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+	var req models.GradeAnswerRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	answer, err := h.GradingUc.GradeAnswer(actor, id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionGrade,
+		Entity:   constant.AuditEntityAnswer,
+		EntityID: answer.ID.String(),
+		After:    answer,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"answer": answer})
+}
+
+// RegradeQuiz godoc
+// @Summary Regrade quiz
+// @Description Move the attempts pinned to an earlier version of the quiz onto its latest one, marking their multiple choice answers against the current answer key and scoring finished attempts again
+// @Tags Grading
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} models.RegradeResult
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/regrade [post]
+func (h *gradingHandler) RegradeQuiz(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+
+	result, err := h.GradingUc.RegradeQuiz(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionRegrade,
+		Entity:   constant.AuditEntityQuiz,
+		EntityID: id.String(),
+		After:    result,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"regrade": result})
+}
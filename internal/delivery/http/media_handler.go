@@ -0,0 +1,72 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/media"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/storage"
+	"github.com/gin-gonic/gin"
+)
+
+type MediaHandler interface {
+	ServeMedia(c *gin.Context)
+}
+
+type mediaHandler struct {
+	Store *storage.Local
+}
+
+// NewMediaHandler serves the files of local storage through their signed
+// URLs; buckets serve their own.
+func NewMediaHandler(store *storage.Local) MediaHandler {
+	return &mediaHandler{Store: store}
+}
+
+// ServeMedia godoc
+// @Summary Get attachment file
+// @Description Fetch an attachment or thumbnail through the signed URL returned with its question. No token is needed, but the URL expires.
+// @Tags Media
+// @Produce octet-stream
+// @Param key path string true "Storage key"
+// @Param expires query int true "Expiry as a Unix time"
+// @Param signature query string true "URL signature"
+// @Success 200 {file} file
+// @Failure 403 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /media/{key} [get]
+func (h *mediaHandler) ServeMedia(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if !h.Store.Verify(key, c.Query("expires"), c.Query("signature")) {
+		c.Error(apperror.Forbidden("link is invalid or has expired"))
+		return
+	}
+
+	f, err := h.Store.Open(key)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		c.Error(apperror.NotFound("file not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer f.Close()
+
+	c.Header("Content-Type", media.ContentType(path.Ext(key)))
+	c.Header("Content-Disposition", "inline")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private")
+	if rs, ok := f.(io.ReadSeeker); ok {
+		// Supports range requests, which audio players use to seek.
+		http.ServeContent(c.Writer, c.Request, "", time.Time{}, rs)
+		return
+	}
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, f)
+}
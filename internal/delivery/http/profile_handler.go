@@ -0,0 +1,219 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileHandler interface {
+	GetProfile(c *gin.Context)
+	UpdateProfile(c *gin.Context)
+	RequestEmailChange(c *gin.Context)
+	VerifyEmailChange(c *gin.Context)
+	DeleteAccount(c *gin.Context)
+}
+
+type profileHandler struct {
+	ProfileUc usecases.ProfileUsecase
+	AuditUc   usecases.AuditLogUsecase
+}
+
+func NewProfileHandler(profileUc usecases.ProfileUsecase, auditUc usecases.AuditLogUsecase) ProfileHandler {
+	return &profileHandler{
+		ProfileUc: profileUc,
+		AuditUc:   auditUc,
+	}
+}
+
+// GetProfile godoc
+// @Summary Get own profile
+// @Description Get the display name, avatar, bio, locale and timezone of the logged-in user.
+// @Tags Profile
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {object} models.Profile
+// @Failure 401 {object} apperror.Problem
+// @Router /auth/profile [get]
+func (h *profileHandler) GetProfile(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.ProfileUc.GetProfile(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"profile": profile})
+}
+
+// UpdateProfile godoc
+// @Summary Update own profile
+// @Description Update profile fields of the logged-in user. Omitted fields are left unchanged, an empty string clears a field.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param Body body models.UpdateProfileRequest true "fields to update"
+// @Success 200 {object} models.Profile
+// @Failure 400 {object} apperror.Problem
+// @Failure 401 {object} apperror.Problem
+// @Router /auth/profile [put]
+func (h *profileHandler) UpdateProfile(c *gin.Context) {
+	var req models.UpdateProfileRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	before, err := h.ProfileUc.GetProfile(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	snapshot := *before
+
+	profile, err := h.ProfileUc.UpdateProfile(userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionUpdate,
+		Entity:   constant.AuditEntityProfile,
+		EntityID: auditID(userID),
+		Before:   snapshot,
+		After:    profile,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"profile": profile})
+}
+
+// RequestEmailChange godoc
+// @Summary Change own email
+// @Description Send a verification link to the new address. The email on the account changes once the link is followed.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param Body body models.ChangeEmailRequest true "current password and new email"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 401 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /auth/profile/email [post]
+func (h *profileHandler) RequestEmailChange(c *gin.Context) {
+	var req models.ChangeEmailRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	change, err := h.ProfileUc.RequestEmailChange(userID, req.Password, req.NewEmail)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionRequestEmail,
+		Entity:   constant.AuditEntityUser,
+		EntityID: auditID(userID),
+		After:    change,
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":    "Verification link sent to " + change.NewEmail,
+		"expires_at": change.ExpiresAt,
+	})
+}
+
+// VerifyEmailChange godoc
+// @Summary Confirm an email change
+// @Description Apply a pending email change using the token from the verification link, given as the token query parameter or in the body.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param token query string false "verification token"
+// @Param Body body models.VerifyEmailRequest false "verification token"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /auth/profile/email/verify [post]
+func (h *profileHandler) VerifyEmailChange(c *gin.Context) {
+	req := models.VerifyEmailRequest{Token: c.Query("token")}
+	if req.Token == "" && !bindJSON(c, &req) {
+		return
+	}
+
+	change, err := h.ProfileUc.VerifyEmailChange(req.Token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		ActorID:  change.UserID,
+		Action:   constant.AuditActionVerifyEmail,
+		Entity:   constant.AuditEntityUser,
+		EntityID: auditID(change.UserID),
+		After:    map[string]string{"email": change.NewEmail},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email changed successfully", "email": change.NewEmail})
+}
+
+// DeleteAccount godoc
+// @Summary Delete own account
+// @Description Permanently delete the logged-in user's account. Past quiz attempts are kept anonymized for statistics.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param Body body models.DeleteAccountRequest true "current password"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 401 {object} apperror.Problem
+// @Failure 403 {object} apperror.Problem
+// @Router /auth/account [delete]
+func (h *profileHandler) DeleteAccount(c *gin.Context) {
+	var req models.DeleteAccountRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.ProfileUc.DeleteAccount(userID, req.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionDeleteAccount,
+		Entity:   constant.AuditEntityUser,
+		EntityID: auditID(user.ID),
+		Before:   user,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted successfully"})
+}
@@ -0,0 +1,735 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type QuizHandler interface {
+	GetAllQuizzes(c *gin.Context)
+	GetQuizByID(c *gin.Context)
+	CreateQuiz(c *gin.Context)
+	UpdateQuiz(c *gin.Context)
+	DeleteQuiz(c *gin.Context)
+	CalibrateQuiz(c *gin.Context)
+	GetDifficultySuggestions(c *gin.Context)
+	GetQuestions(c *gin.Context)
+	GetRevision(c *gin.Context)
+	SubmitQuiz(c *gin.Context)
+	ApproveQuiz(c *gin.Context)
+	RejectQuiz(c *gin.Context)
+	PublishQuiz(c *gin.Context)
+	ArchiveQuiz(c *gin.Context)
+	GetVersions(c *gin.Context)
+	GetVersion(c *gin.Context)
+	DiffVersions(c *gin.Context)
+	CorrectAnswerKey(c *gin.Context)
+	DuplicateQuiz(c *gin.Context)
+}
+
+type quizHandler struct {
+	QuizUc  usecases.QuizUsecase
+	AuditUc usecases.AuditLogUsecase
+}
+
+func NewQuizHandler(uc usecases.QuizUsecase, auditUc usecases.AuditLogUsecase) QuizHandler {
+	return &quizHandler{
+		QuizUc:  uc,
+		AuditUc: auditUc,
+	}
+}
+
+// GetAllQuizzes godoc
+// @Summary Get all quizzes
+// @Description List quizzes, newest first, optionally only those in a category and its subcategories or in a status. Draft revisions are left out, and so are other teachers' drafts unless they are shared with the caller.
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param status query string false "draft, in_review, published or archived"
+// @Param category_id query int false "Category ID"
+// @Param category query string false "Category slug"
+// @Param tags query string false "Comma-separated tags"
+// @Param match query string false "any (default) or all of the tags"
+// @Success 200 {array} models.QuizList
+// @Failure 400 {object} apperror.Problem
+// @Failure 401 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/quizzes [get]
+func (h *quizHandler) GetAllQuizzes(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+	filter, ok := quizFilter(c)
+	if !ok {
+		return
+	}
+	filter.Status = c.Query("status")
+	switch filter.Status {
+	case "", constant.QuizDraft, constant.QuizInReview, constant.QuizPublished, constant.QuizArchived:
+	default:
+		c.Error(apperror.Validation("invalid quiz status", apperror.FieldError{Field: "status", Rule: "oneof", Message: "must be one of: draft in_review published archived"}))
+		return
+	}
+
+	quizzes, err := h.QuizUc.GetAllQuizzes(actor, filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quizzes": quizzes})
+}
+
+// GetQuizByID godoc
+// @Summary Get quiz by id
+// @Description Get a quiz with its questions, options and correct answers
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} models.Quiz
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/quiz/{id} [get]
+func (h *quizHandler) GetQuizByID(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+
+	quiz, err := h.QuizUc.GetQuizByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quiz": quiz})
+}
+
+// CreateQuiz godoc
+// @Summary Create quiz
+// @Description Create a quiz with its questions. Every question needs at least two options, exactly one of them correct.
+// @Tags Quiz
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param Body body models.QuizRequest true "the quiz to create"
+// @Success 201 {object} models.Quiz
+// @Failure 400 {object} apperror.Problem
+// @Router /teacher/quiz [post]
+func (h *quizHandler) CreateQuiz(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+	var req models.QuizRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	quiz, err := h.QuizUc.CreateQuiz(actor, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionCreate,
+		Entity:   constant.AuditEntityQuiz,
+		EntityID: quiz.ID.String(),
+		After:    quiz,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"quiz": quiz})
+}
+
+// UpdateQuiz godoc
+// @Summary Update quiz
+// @Description Update quiz details. When questions are sent they replace the existing ones. Changes to a published quiz go to its draft revision, which is returned instead and can change the questions even after the quiz has been attempted; editing a quiz in review takes it back to draft.
+// @Tags Quiz
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Param Body body models.QuizRequest true "the quiz fields"
+// @Success 200 {object} models.Quiz
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /teacher/quiz/{id} [put]
+func (h *quizHandler) UpdateQuiz(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+
+	var req models.QuizRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	before, err := h.QuizUc.GetQuizByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	quiz, err := h.QuizUc.UpdateQuiz(id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionUpdate,
+		Entity:   constant.AuditEntityQuiz,
+		EntityID: quiz.ID.String(),
+		Before:   before,
+		After:    quiz,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"quiz": quiz})
+}
+
+// DeleteQuiz godoc
+// @Summary Delete quiz
+// @Description Delete a quiz that has not been attempted yet
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /teacher/quiz/{id} [delete]
+func (h *quizHandler) DeleteQuiz(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+
+	quiz, err := h.QuizUc.DeleteQuiz(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionDelete,
+		Entity:   constant.AuditEntityQuiz,
+		EntityID: quiz.ID.String(),
+		Before:   quiz,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quiz deleted successfully"})
+}
+
+// CalibrateQuiz godoc
+// @Summary Calibrate quiz
+// @Description Estimate the IRT difficulty and discrimination of every multiple choice question with enough answers in finished, non-adaptive attempts. Adaptive attempts use these parameters to pick questions.
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} models.Quiz
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/calibrate [post]
+func (h *quizHandler) CalibrateQuiz(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+
+	quiz, err := h.QuizUc.CalibrateQuiz(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionCalibrate,
+		Entity:   constant.AuditEntityQuiz,
+		EntityID: quiz.ID.String(),
+		After:    quiz,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"quiz": quiz})
+}
+
+// GetRevision godoc
+// @Summary Get quiz revision
+// @Description Get the draft revision holding the pending changes to a published quiz
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} models.Quiz
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/revision [get]
+func (h *quizHandler) GetRevision(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+
+	revision, err := h.QuizUc.GetRevision(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quiz": revision})
+}
+
+// SubmitQuiz godoc
+// @Summary Submit quiz for review
+// @Description Move a draft quiz or revision to in_review
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} models.Quiz
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/submit [post]
+func (h *quizHandler) SubmitQuiz(c *gin.Context) {
+	h.transition(c, constant.AuditActionSubmit, func(actor models.Actor, id uuid.UUID) (*models.Quiz, error) {
+		return h.QuizUc.SubmitQuiz(actor, id)
+	})
+}
+
+// ApproveQuiz godoc
+// @Summary Approve quiz
+// @Description Publish a quiz in review, or apply a revision in review to its quiz and return that quiz. The quiz's owner and editors and the teacher who submitted it can't approve it.
+// @Tags Quiz
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Param Body body models.ApproveQuizRequest false "an optional comment"
+// @Success 200 {object} models.Quiz
+// @Failure 400 {object} apperror.Problem
+// @Failure 403 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/approve [post]
+func (h *quizHandler) ApproveQuiz(c *gin.Context) {
+	var req models.ApproveQuizRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+	h.transition(c, constant.AuditActionApprove, func(actor models.Actor, id uuid.UUID) (*models.Quiz, error) {
+		return h.QuizUc.ApproveQuiz(actor, id, req.Comment)
+	})
+}
+
+// RejectQuiz godoc
+// @Summary Reject quiz
+// @Description Send a quiz or revision in review back to draft with a comment. The quiz's owner and editors and the teacher who submitted it can't reject it.
+// @Tags Quiz
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Param Body body models.RejectQuizRequest true "why the quiz is rejected"
+// @Success 200 {object} models.Quiz
+// @Failure 400 {object} apperror.Problem
+// @Failure 403 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/reject [post]
+func (h *quizHandler) RejectQuiz(c *gin.Context) {
+	var req models.RejectQuizRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	h.transition(c, constant.AuditActionReject, func(actor models.Actor, id uuid.UUID) (*models.Quiz, error) {
+		return h.QuizUc.RejectQuiz(actor, id, req.Comment)
+	})
+}
+
+// PublishQuiz godoc
+// @Summary Publish quiz
+// @Description Publish a draft quiz or apply a draft revision to its quiz without review, which is refused when QUIZ_REVIEW_REQUIRED is set, or republish an archived quiz
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} models.Quiz
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/publish [post]
+func (h *quizHandler) PublishQuiz(c *gin.Context) {
+	h.transition(c, constant.AuditActionPublish, func(_ models.Actor, id uuid.UUID) (*models.Quiz, error) {
+		return h.QuizUc.PublishQuiz(id)
+	})
+}
+
+// ArchiveQuiz godoc
+// @Summary Archive quiz
+// @Description Retire a published quiz: students no longer see it or start attempts, while attempts in progress can be finished
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} models.Quiz
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/archive [post]
+func (h *quizHandler) ArchiveQuiz(c *gin.Context) {
+	h.transition(c, constant.AuditActionArchive, func(_ models.Actor, id uuid.UUID) (*models.Quiz, error) {
+		return h.QuizUc.ArchiveQuiz(id)
+	})
+}
+
+// transition runs a workflow step on the quiz in the path and records it
+// in the audit log. Steps on a revision may return the quiz it belongs to.
+func (h *quizHandler) transition(c *gin.Context, action string, step func(actor models.Actor, id uuid.UUID) (*models.Quiz, error)) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	before, err := h.QuizUc.GetQuizByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	quiz, err := step(actor, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   action,
+		Entity:   constant.AuditEntityQuiz,
+		EntityID: id.String(),
+		Before:   before,
+		After:    quiz,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"quiz": quiz})
+}
+
+// GetVersions godoc
+// @Summary Get quiz versions
+// @Description List the versions of a quiz, newest first. A version is taken each time the quiz is published or its answer key is corrected.
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Success 200 {array} models.QuizVersion
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/versions [get]
+func (h *quizHandler) GetVersions(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+
+	versions, err := h.QuizUc.GetVersions(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
+}
+
+// GetVersion godoc
+// @Summary Get quiz version
+// @Description Get a version of a quiz with the quiz, its questions and answer key as they were in it
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Param version path int true "Version number"
+// @Success 200 {object} models.QuizVersion
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/versions/{version} [get]
+func (h *quizHandler) GetVersion(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+	number, ok := paramID(c, "version", "quiz version")
+	if !ok {
+		return
+	}
+
+	version, quiz, err := h.QuizUc.GetVersion(id, int(number))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"version": version, "quiz": quiz})
+}
+
+// DiffVersions godoc
+// @Summary Diff quiz versions
+// @Description Compare two versions of a quiz field by field, matching questions by position. to defaults to the latest version and from to the one before it.
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Param from query int false "Earlier version"
+// @Param to query int false "Later version"
+// @Success 200 {object} models.QuizDiff
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/diff [get]
+func (h *quizHandler) DiffVersions(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+	versions := map[string]int{}
+	for _, key := range []string{"from", "to"} {
+		value := c.Query(key)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			c.Error(apperror.Validation("invalid quiz version", apperror.FieldError{Field: key, Rule: "min", Message: "must be a positive integer"}))
+			return
+		}
+		versions[key] = n
+	}
+
+	diff, err := h.QuizUc.DiffVersions(id, versions["from"], versions["to"])
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"diff": diff})
+}
+
+// CorrectAnswerKey godoc
+// @Summary Correct answer key
+// @Description Make another option the correct answer of a multiple choice question of a published quiz. The quiz gets a new version; existing attempts keep their score until the quiz is regraded.
+// @Tags Quiz
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Param question_id path string true "Question ID"
+// @Param Body body models.AnswerKeyRequest true "the correct option"
+// @Success 200 {object} models.Quiz
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/question/{question_id}/answer-key [put]
+func (h *quizHandler) CorrectAnswerKey(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+	questionID, ok := paramUUID(c, "question_id", "question")
+	if !ok {
+		return
+	}
+	var req models.AnswerKeyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	before, err := h.QuizUc.GetQuizByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	quiz, err := h.QuizUc.CorrectAnswerKey(id, questionID, req.OptionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionAnswerKey,
+		Entity:   constant.AuditEntityQuiz,
+		EntityID: id.String(),
+		Before:   before,
+		After:    quiz,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"quiz": quiz})
+}
+
+// DuplicateQuiz godoc
+// @Summary Duplicate quiz
+// @Description Create a draft copy of a quiz with its questions, options, answer key, rubrics and attachments under new IDs. The copy is titled "<title> (copy)" unless a title is given, and keeps the quiz's category unless another is given. Copying a published quiz copies what students see, not its draft revision.
+// @Tags Quiz
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Param Body body models.CopyQuizRequest false "title and category of the copy"
+// @Success 201 {object} models.Quiz
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/duplicate [post]
+func (h *quizHandler) DuplicateQuiz(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+	var req models.CopyQuizRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	quiz, err := h.QuizUc.DuplicateQuiz(actor, id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionCreate,
+		Entity:   constant.AuditEntityQuiz,
+		EntityID: quiz.ID.String(),
+		After:    quiz,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"quiz": quiz})
+}
+
+// GetDifficultySuggestions godoc
+// @Summary Get difficulty suggestions
+// @Description List the quizzes and questions whose success rate in finished attempts points at another difficulty than the one they were given. Success rates are refreshed by calibration.
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {array} models.DifficultySuggestion
+// @Failure 401 {object} apperror.Problem
+// @Router /teacher/quizzes/difficulty-suggestions [get]
+func (h *quizHandler) GetDifficultySuggestions(c *gin.Context) {
+	suggestions, err := h.QuizUc.GetDifficultySuggestions()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestions": suggestions})
+}
+
+// GetQuestions godoc
+// @Summary Get question bank
+// @Description List questions of all quizzes, or of one quiz, by quiz and position, optionally only those with any or all of the given tags
+// @Tags Quiz
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param quiz_id query string false "Quiz ID"
+// @Param tags query string false "Comma-separated tags"
+// @Param match query string false "any (default) or all of the tags"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {array} models.Question
+// @Failure 400 {object} apperror.Problem
+// @Failure 401 {object} apperror.Problem
+// @Router /teacher/questions [get]
+func (h *quizHandler) GetQuestions(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+	filter := models.QuestionFilter{}
+	if quizID := c.Query("quiz_id"); quizID != "" {
+		id, err := uuid.Parse(quizID)
+		if err != nil {
+			c.Error(apperror.Validation("invalid quiz ID", apperror.FieldError{Field: "quiz_id", Rule: "uuid", Message: "must be a valid UUID"}))
+			return
+		}
+		filter.QuizID = &id
+	}
+	if filter.Tags, filter.MatchAllTags, ok = tagFilter(c); !ok {
+		return
+	}
+	filter.Page, _ = strconv.Atoi(c.Query("page"))
+	filter.PageSize, _ = strconv.Atoi(c.Query("page_size"))
+
+	questions, total, err := h.QuizUc.GetQuestions(actor, filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"questions": questions, "total": total})
+}
+
+// quizFilter reads the category (by category_id or category slug) and tag
+// queries shared by the quiz listings.
+func quizFilter(c *gin.Context) (models.QuizFilter, bool) {
+	filter, ok := categoryFilter(c)
+	if !ok {
+		return filter, false
+	}
+	if filter.Tags, filter.MatchAllTags, ok = tagFilter(c); !ok {
+		return filter, false
+	}
+	return filter, true
+}
+
+// categoryFilter reads the category_id or category slug query.
+func categoryFilter(c *gin.Context) (models.QuizFilter, bool) {
+	filter := models.QuizFilter{CategorySlug: c.Query("category")}
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		id, err := strconv.ParseUint(categoryID, 10, 64)
+		if err != nil || id == 0 {
+			c.Error(apperror.Validation("invalid category ID", apperror.FieldError{Field: "category_id", Rule: "id", Message: "must be a positive integer"}))
+			return filter, false
+		}
+		filter.CategoryID = uint(id)
+	}
+	return filter, true
+}
+
+// tagFilter reads the comma-separated tags query and whether match=all
+// requires every one of them.
+func tagFilter(c *gin.Context) ([]string, bool, bool) {
+	var tags []string
+	if value := c.Query("tags"); value != "" {
+		tags = strings.Split(value, ",")
+	}
+
+	switch c.DefaultQuery("match", "any") {
+	case "any":
+		return tags, false, true
+	case "all":
+		return tags, true, true
+	default:
+		c.Error(apperror.Validation("invalid tag match", apperror.FieldError{Field: "match", Rule: "oneof", Message: "must be one of: any all"}))
+		return nil, false, false
+	}
+}
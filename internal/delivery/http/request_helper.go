@@ -0,0 +1,93 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// bindJSON decodes and validates the request body. On failure it attaches a
+// typed validation error for the error middleware and returns false.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		c.Error(validator.ValidationError(err))
+		return false
+	}
+	return true
+}
+
+// bindOptionalJSON is bindJSON for endpoints whose body may be empty.
+func bindOptionalJSON(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(validator.ValidationError(err))
+		return false
+	}
+	return true
+}
+
+// paramID parses a numeric ID path parameter.
+func paramID(c *gin.Context, name, entity string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		c.Error(apperror.Validation("invalid "+entity+" ID", apperror.FieldError{
+			Field:   name,
+			Rule:    "id",
+			Message: "must be a positive integer",
+		}))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// paramUUID parses a UUID path parameter.
+func paramUUID(c *gin.Context, name, entity string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.Error(apperror.Validation("invalid "+entity+" ID", apperror.FieldError{
+			Field:   name,
+			Rule:    "uuid",
+			Message: "must be a UUID",
+		}))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// currentActor returns the authenticated user and role set by the JWT middleware.
+func currentActor(c *gin.Context) (models.Actor, bool) {
+	id, ok := currentUserID(c)
+	if !ok {
+		return models.Actor{}, false
+	}
+	return models.Actor{ID: id, Role: c.GetString("user_role")}, true
+}
+
+// currentUserID returns the authenticated user's ID set by the JWT middleware.
+func currentUserID(c *gin.Context) (uint, bool) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.Error(apperror.Unauthorized("authentication required"))
+		return 0, false
+	}
+
+	id, ok := userID.(uint)
+	if !ok {
+		c.Error(apperror.Unauthorized("authentication required"))
+		return 0, false
+	}
+	return id, true
+}
+
+// restoreInput optionally renames an entity while restoring it, to get past
+// a unique-name conflict with an active record.
+type restoreInput struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Email    string `json:"email" binding:"omitempty,email"`
+}
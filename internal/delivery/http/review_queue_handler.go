@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewQueueHandler interface {
+	GetDueReviews(c *gin.Context)
+	RecordRecall(c *gin.Context)
+}
+
+type reviewQueueHandler struct {
+	ReviewQueueUc usecases.ReviewQueueUsecase
+}
+
+func NewReviewQueueHandler(uc usecases.ReviewQueueUsecase) ReviewQueueHandler {
+	return &reviewQueueHandler{ReviewQueueUc: uc}
+}
+
+// GetDueReviews godoc
+// @Summary Get due reviews
+// @Description List the logged-in student's spaced repetition cards due now, built from the questions they got wrong once the quiz releases mistakes. The correct option is included once the quiz releases answers.
+// @Tags Review Queue
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param limit query int false "maximum number of cards (default 20, max 100)"
+// @Success 200 {array} models.DueReview
+// @Failure 401 {object} apperror.Problem
+// @Router /student/reviews/due [get]
+func (h *reviewQueueHandler) GetDueReviews(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	reviews, err := h.ReviewQueueUc.GetDueReviews(userID, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reviews": reviews})
+}
+
+// RecordRecall godoc
+// @Summary Record recall
+// @Description Grade how well a due card was recalled, from 0 (blackout) to 5 (perfect), and schedule its next review with SM-2
+// @Tags Review Queue
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Card ID"
+// @Param Body body models.RecallRequest true "recall quality"
+// @Success 200 {object} models.ReviewCard
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /student/review/{id} [post]
+func (h *reviewQueueHandler) RecordRecall(c *gin.Context) {
+	id, ok := paramID(c, "id", "review card")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req models.RecallRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	card, err := h.ReviewQueueUc.RecordRecall(userID, id, *req.Quality)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"card": card})
+}
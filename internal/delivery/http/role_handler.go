@@ -2,10 +2,10 @@ package http
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,15 +15,20 @@ type RoleHandler interface {
 	CreateRole(c *gin.Context)
 	UpdateRole(c *gin.Context)
 	DeleteRole(c *gin.Context)
+	GetTrashedRoles(c *gin.Context)
+	RestoreRole(c *gin.Context)
+	PurgeRole(c *gin.Context)
 }
 
 type roleHandler struct {
-	RoleUc usecases.RoleUsecase
+	RoleUc  usecases.RoleUsecase
+	AuditUc usecases.AuditLogUsecase
 }
 
-func NewRoleHandler(uc usecases.RoleUsecase) RoleHandler {
+func NewRoleHandler(uc usecases.RoleUsecase, auditUc usecases.AuditLogUsecase) RoleHandler {
 	return &roleHandler{
-		RoleUc: uc,
+		RoleUc:  uc,
+		AuditUc: auditUc,
 	}
 }
 
@@ -37,7 +42,7 @@ func NewRoleHandler(uc usecases.RoleUsecase) RoleHandler {
 func (h *roleHandler) GetAllRoles(c *gin.Context) {
 	roles, err := h.RoleUc.GetAllRoles()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -52,20 +57,19 @@ func (h *roleHandler) GetAllRoles(c *gin.Context) {
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Param id path string true "Role ID"
 // @Success 200 {object} models.Role
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
 // @Router /cms/role/{id} [get]
 func (h *roleHandler) GetRoleByID(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+	id, ok := paramID(c, "id", "role")
+	if !ok {
 		return
 	}
 
-	role, err := h.RoleUc.GetRoleByID(uint(id))
+	role, err := h.RoleUc.GetRoleByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		c.Error(err)
 		return
 	}
 
@@ -80,76 +84,189 @@ func (h *roleHandler) GetRoleByID(c *gin.Context) {
 // @Produce json
 // @Param name body string true "Input role name"
 // @Success 201 {object} models.Role
-// @Failure 400 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
 func (h *roleHandler) CreateRole(c *gin.Context) {
 	var input models.Role
 
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &input) {
 		return
 	}
 
 	role, err := h.RoleUc.CreateRole(&input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionCreate,
+		Entity:   constant.AuditEntityRole,
+		EntityID: auditID(role.ID),
+		After:    role,
+	})
+
 	c.JSON(http.StatusCreated, gin.H{"role": role})
 }
 
 // Update role
 func (h *roleHandler) UpdateRole(c *gin.Context) {
 	var input models.Role
-	id, err := strconv.Atoi(c.Param("id"))
-
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+	id, ok := paramID(c, "id", "role")
+	if !ok {
 		return
 	}
 
-	roleId, err := h.RoleUc.GetRoleByID(uint(id))
+	roleId, err := h.RoleUc.GetRoleByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		c.Error(err)
 		return
 	}
 
+	before := *roleId
 	input.ID = roleId.ID
 
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &input) {
 		return
 	}
 
 	role, err := h.RoleUc.UpdateRole(&input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionUpdate,
+		Entity:   constant.AuditEntityRole,
+		EntityID: auditID(role.ID),
+		Before:   before,
+		After:    role,
+	})
+
 	c.JSON(http.StatusOK, gin.H{"role": role})
 }
 
 // Delete role
 func (h *roleHandler) DeleteRole(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+	id, ok := paramID(c, "id", "role")
+	if !ok {
 		return
 	}
 
-	role, err := h.RoleUc.GetRoleByID(uint(id))
+	role, err := h.RoleUc.GetRoleByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		c.Error(err)
 		return
 	}
 
 	err = h.RoleUc.DeleteRole(role)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionDelete,
+		Entity:   constant.AuditEntityRole,
+		EntityID: auditID(role.ID),
+		Before:   role,
+	})
+
 	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
 }
+
+// GetTrashedRoles godoc
+// @Summary Get trashed roles
+// @Description List soft-deleted roles, most recently deleted first
+// @Tags Role
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {array} models.Role
+// @Failure 500 {object} apperror.Problem
+// @Router /cms/trash/roles [get]
+func (h *roleHandler) GetTrashedRoles(c *gin.Context) {
+	roles, err := h.RoleUc.GetTrashedRoles()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// RestoreRole godoc
+// @Summary Restore role
+// @Description Restore a soft-deleted role, optionally renaming it to resolve a unique-name conflict
+// @Tags Role
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param id path int true "Role ID"
+// @Success 200 {object} models.Role
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
+// @Router /cms/trash/role/{id}/restore [post]
+func (h *roleHandler) RestoreRole(c *gin.Context) {
+	id, ok := paramID(c, "id", "role")
+	if !ok {
+		return
+	}
+
+	var input restoreInput
+	if !bindOptionalJSON(c, &input) {
+		return
+	}
+
+	role, err := h.RoleUc.RestoreRole(id, input.Name)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionRestore,
+		Entity:   constant.AuditEntityRole,
+		EntityID: auditID(role.ID),
+		After:    role,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"role": role})
+}
+
+// PurgeRole godoc
+// @Summary Purge role
+// @Description Permanently delete a soft-deleted role
+// @Tags Role
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param id path int true "Role ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
+// @Router /cms/trash/role/{id} [delete]
+func (h *roleHandler) PurgeRole(c *gin.Context) {
+	id, ok := paramID(c, "id", "role")
+	if !ok {
+		return
+	}
+
+	role, err := h.RoleUc.PurgeRole(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionPurge,
+		Entity:   constant.AuditEntityRole,
+		EntityID: auditID(role.ID),
+		Before:   role,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role purged successfully"})
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler interface {
+	Search(c *gin.Context)
+}
+
+type searchHandler struct {
+	SearchUc usecases.SearchUsecase
+}
+
+func NewSearchHandler(uc usecases.SearchUsecase) SearchHandler {
+	return &searchHandler{SearchUc: uc}
+}
+
+// Search godoc
+// @Summary Search quizzes and questions
+// @Description Full-text search over quiz titles and descriptions and question and option text. Every word of q must start a word of the result. Results come best match first, with the matches wrapped in <mark> in the HTML-escaped highlight and snippet.
+// @Tags Search
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param q query string true "Search query"
+// @Param limit query int false "Maximum number of results"
+// @Success 200 {array} models.SearchHit
+// @Failure 400 {object} apperror.Problem
+// @Failure 401 {object} apperror.Problem
+// @Router /teacher/search [get]
+func (h *searchHandler) Search(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	hits, err := h.SearchUc.Search(actor, c.Query("q"), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": hits})
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/gin-gonic/gin"
+)
+
+type ShareHandler interface {
+	GetCollaborators(c *gin.Context)
+	ShareQuiz(c *gin.Context)
+	UnshareQuiz(c *gin.Context)
+	TransferQuiz(c *gin.Context)
+}
+
+type shareHandler struct {
+	ShareUc usecases.ShareUsecase
+	AuditUc usecases.AuditLogUsecase
+}
+
+func NewShareHandler(uc usecases.ShareUsecase, auditUc usecases.AuditLogUsecase) ShareHandler {
+	return &shareHandler{
+		ShareUc: uc,
+		AuditUc: auditUc,
+	}
+}
+
+// GetCollaborators godoc
+// @Summary Get quiz collaborators
+// @Description List the quiz's owner, then the teachers it is shared with and their roles. A draft revision has the collaborators of its quiz.
+// @Tags Quiz Sharing
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Success 200 {array} models.Collaborator
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/collaborators [get]
+func (h *shareHandler) GetCollaborators(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+
+	collaborators, err := h.ShareUc.GetCollaborators(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"collaborators": collaborators})
+}
+
+// ShareQuiz godoc
+// @Summary Share quiz
+// @Description Share the quiz with a teacher, identified by username or email, as an editor who can change it or a viewer who can only see it. Sharing again changes the teacher's role. Only the owner can share a quiz.
+// @Tags Quiz Sharing
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Param Body body models.ShareQuizRequest true "the teacher and their role"
+// @Success 200 {object} models.QuizShare
+// @Failure 400 {object} apperror.Problem
+// @Failure 403 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/collaborators [post]
+func (h *shareHandler) ShareQuiz(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+	var req models.ShareQuizRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	share, err := h.ShareUc.ShareQuiz(id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionShare,
+		Entity:   constant.AuditEntityQuiz,
+		EntityID: share.QuizID.String(),
+		After:    share,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"share": share})
+}
+
+// UnshareQuiz godoc
+// @Summary Unshare quiz
+// @Description Take a teacher's access to the quiz away. Only the owner can unshare a quiz.
+// @Tags Quiz Sharing
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 403 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/collaborator/{user_id} [delete]
+func (h *shareHandler) UnshareQuiz(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+	userID, ok := paramID(c, "user_id", "user")
+	if !ok {
+		return
+	}
+
+	share, err := h.ShareUc.UnshareQuiz(id, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionUnshare,
+		Entity:   constant.AuditEntityQuiz,
+		EntityID: share.QuizID.String(),
+		Before:   share,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quiz unshared successfully"})
+}
+
+// TransferQuiz godoc
+// @Summary Transfer quiz
+// @Description Make another teacher, identified by username or email, the owner of the quiz. The previous owner stays on as an editor. Only the owner can transfer a quiz.
+// @Tags Quiz Sharing
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "Quiz ID"
+// @Param Body body models.TransferQuizRequest true "the new owner"
+// @Success 200 {array} models.Collaborator
+// @Failure 400 {object} apperror.Problem
+// @Failure 403 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/quiz/{id}/transfer [post]
+func (h *shareHandler) TransferQuiz(c *gin.Context) {
+	id, ok := paramUUID(c, "id", "quiz")
+	if !ok {
+		return
+	}
+	var req models.TransferQuizRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	before, err := h.ShareUc.GetCollaborators(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	collaborators, err := h.ShareUc.TransferQuiz(id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionTransfer,
+		Entity:   constant.AuditEntityQuiz,
+		EntityID: id.String(),
+		Before:   gin.H{"collaborators": before},
+		After:    gin.H{"collaborators": collaborators},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"collaborators": collaborators})
+}
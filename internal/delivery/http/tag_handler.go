@@ -0,0 +1,133 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler interface {
+	SearchTags(c *gin.Context)
+	RenameTag(c *gin.Context)
+	MergeTag(c *gin.Context)
+}
+
+type tagHandler struct {
+	TagUc   usecases.TagUsecase
+	AuditUc usecases.AuditLogUsecase
+}
+
+func NewTagHandler(uc usecases.TagUsecase, auditUc usecases.AuditLogUsecase) TagHandler {
+	return &tagHandler{
+		TagUc:   uc,
+		AuditUc: auditUc,
+	}
+}
+
+// SearchTags godoc
+// @Summary Autocomplete tags
+// @Description List the tags starting with q, most used first, with how many quizzes and questions carry them
+// @Tags Tag
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param q query string false "Tag prefix"
+// @Param limit query int false "Maximum number of tags"
+// @Success 200 {array} models.TagUsage
+// @Failure 401 {object} apperror.Problem
+// @Router /teacher/tags [get]
+func (h *tagHandler) SearchTags(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	tags, err := h.TagUc.SearchTags(c.Query("q"), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// RenameTag godoc
+// @Summary Rename tag
+// @Description Rename a tag on every quiz and question carrying it. Renaming to the name of another tag is refused; merge them instead.
+// @Tags Tag
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Tag ID"
+// @Param Body body models.RenameTagRequest true "the new name"
+// @Success 200 {object} models.Tag
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Router /cms/tag/{id} [put]
+func (h *tagHandler) RenameTag(c *gin.Context) {
+	id, ok := paramID(c, "id", "tag")
+	if !ok {
+		return
+	}
+
+	var req models.RenameTagRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tag, err := h.TagUc.RenameTag(id, req.Name)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionUpdate,
+		Entity:   constant.AuditEntityTag,
+		EntityID: auditID(tag.ID),
+		After:    tag,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"tag": tag})
+}
+
+// MergeTag godoc
+// @Summary Merge tag
+// @Description Move a tag's quizzes and questions to the target tag and delete it
+// @Tags Tag
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Tag ID"
+// @Param Body body models.MergeTagRequest true "the target tag"
+// @Success 200 {object} models.Tag
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /cms/tag/{id}/merge [post]
+func (h *tagHandler) MergeTag(c *gin.Context) {
+	id, ok := paramID(c, "id", "tag")
+	if !ok {
+		return
+	}
+
+	var req models.MergeTagRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	target, err := h.TagUc.MergeTag(id, req.TargetID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionMerge,
+		Entity:   constant.AuditEntityTag,
+		EntityID: auditID(id),
+		After:    target,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"tag": target})
+}
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/gin-gonic/gin"
+)
+
+type TemplateHandler interface {
+	GetTemplates(c *gin.Context)
+	GetTemplate(c *gin.Context)
+	CreateTemplate(c *gin.Context)
+	UpdateTemplate(c *gin.Context)
+	DeleteTemplate(c *gin.Context)
+	CreateQuiz(c *gin.Context)
+}
+
+type templateHandler struct {
+	TemplateUc usecases.TemplateUsecase
+	AuditUc    usecases.AuditLogUsecase
+}
+
+func NewTemplateHandler(uc usecases.TemplateUsecase, auditUc usecases.AuditLogUsecase) TemplateHandler {
+	return &templateHandler{
+		TemplateUc: uc,
+		AuditUc:    auditUc,
+	}
+}
+
+// GetTemplates godoc
+// @Summary Get quiz templates
+// @Description List the template library by name, optionally only the templates in a category and its subcategories
+// @Tags Template
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param category_id query int false "Category ID"
+// @Param category query string false "Category slug"
+// @Success 200 {array} models.QuizTemplate
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/templates [get]
+func (h *templateHandler) GetTemplates(c *gin.Context) {
+	filter, ok := categoryFilter(c)
+	if !ok {
+		return
+	}
+
+	templates, err := h.TemplateUc.GetTemplates(filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// GetTemplate godoc
+// @Summary Get quiz template
+// @Description Get a template with the quiz it holds: its questions, options and answer key as they were when the template was taken
+// @Tags Template
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Template ID"
+// @Success 200 {object} models.QuizTemplate
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/template/{id} [get]
+func (h *templateHandler) GetTemplate(c *gin.Context) {
+	id, ok := paramID(c, "id", "template")
+	if !ok {
+		return
+	}
+
+	template, quiz, err := h.TemplateUc.GetTemplate(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"template": template, "quiz": quiz})
+}
+
+// CreateTemplate godoc
+// @Summary Create quiz template
+// @Description Add a snapshot of a quiz, with its questions, options and answer key, to the template library. Later edits to the quiz don't change the template.
+// @Tags Template
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param Body body models.QuizTemplateRequest true "the quiz to take the template from"
+// @Success 201 {object} models.QuizTemplate
+// @Failure 400 {object} apperror.Problem
+// @Router /cms/template [post]
+func (h *templateHandler) CreateTemplate(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+	var req models.QuizTemplateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	template, err := h.TemplateUc.CreateTemplate(actor, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionCreate,
+		Entity:   constant.AuditEntityTemplate,
+		EntityID: auditID(template.ID),
+		After:    template,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"template": template})
+}
+
+// UpdateTemplate godoc
+// @Summary Update quiz template
+// @Description Rename a template and, with quiz_id, replace its content with a new snapshot of that quiz
+// @Tags Template
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Template ID"
+// @Param Body body models.UpdateQuizTemplateRequest true "the template's new name"
+// @Success 200 {object} models.QuizTemplate
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /cms/template/{id} [put]
+func (h *templateHandler) UpdateTemplate(c *gin.Context) {
+	id, ok := paramID(c, "id", "template")
+	if !ok {
+		return
+	}
+	var req models.UpdateQuizTemplateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	before, _, err := h.TemplateUc.GetTemplate(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	template, err := h.TemplateUc.UpdateTemplate(id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionUpdate,
+		Entity:   constant.AuditEntityTemplate,
+		EntityID: auditID(id),
+		Before:   before,
+		After:    template,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"template": template})
+}
+
+// DeleteTemplate godoc
+// @Summary Delete quiz template
+// @Description Remove a template from the library. Quizzes created from it are kept.
+// @Tags Template
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Template ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /cms/template/{id} [delete]
+func (h *templateHandler) DeleteTemplate(c *gin.Context) {
+	id, ok := paramID(c, "id", "template")
+	if !ok {
+		return
+	}
+
+	template, err := h.TemplateUc.DeleteTemplate(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionDelete,
+		Entity:   constant.AuditEntityTemplate,
+		EntityID: auditID(id),
+		Before:   template,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
+
+// CreateQuiz godoc
+// @Summary Create quiz from template
+// @Description Create a draft quiz from a template, with its questions, options, answer key, rubrics and attachments under new IDs. The quiz takes the template's title and category unless others are given.
+// @Tags Template
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Template ID"
+// @Param Body body models.CopyQuizRequest false "title and category of the quiz"
+// @Success 201 {object} models.Quiz
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Router /teacher/template/{id}/quiz [post]
+func (h *templateHandler) CreateQuiz(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+	id, ok := paramID(c, "id", "template")
+	if !ok {
+		return
+	}
+	var req models.CopyQuizRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	quiz, err := h.TemplateUc.CreateQuiz(actor, id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionCreate,
+		Entity:   constant.AuditEntityQuiz,
+		EntityID: quiz.ID.String(),
+		After:    quiz,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"quiz": quiz})
+}
@@ -1,13 +1,11 @@
 package http
 
 import (
-	"log"
 	"net/http"
-	"strconv"
 
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
-	"github.com/Arasy41/go-gin-quiz-api/pkg/validator"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,15 +15,20 @@ type UserHandler interface {
 	CreateUser(c *gin.Context)
 	UpdateUser(c *gin.Context)
 	DeleteUser(c *gin.Context)
+	GetTrashedUsers(c *gin.Context)
+	RestoreUser(c *gin.Context)
+	PurgeUser(c *gin.Context)
 }
 
 type userHandler struct {
-	UserUc usecases.UserUsecase
+	UserUc  usecases.UserUsecase
+	AuditUc usecases.AuditLogUsecase
 }
 
-func NewUserHandler(uc usecases.UserUsecase) UserHandler {
+func NewUserHandler(uc usecases.UserUsecase, auditUc usecases.AuditLogUsecase) UserHandler {
 	return &userHandler{
-		UserUc: uc,
+		UserUc:  uc,
+		AuditUc: auditUc,
 	}
 }
 
@@ -39,7 +42,7 @@ func NewUserHandler(uc usecases.UserUsecase) UserHandler {
 func (h *userHandler) GetAllUsers(c *gin.Context) {
 	users, err := h.UserUc.GetAllUsers()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -53,27 +56,24 @@ func (h *userHandler) GetAllUsers(c *gin.Context) {
 // @Produce json
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Param id path string true "User ID"
-// @Success 200 {object} models.User
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
 // @Router /cms/user/{id} [get]
 func (h *userHandler) GetUserByID(c *gin.Context) {
-	var user *models.User
-	userId, _ := strconv.Atoi(c.Param("id"))
-
-	user, err := h.UserUc.GetUserByID(uint(userId))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "record not found"})
+	userId, ok := paramID(c, "id", "user")
+	if !ok {
 		return
 	}
 
-	if user == nil {
-		c.JSON(http.StatusNotFound, gin.H{"message": "record not found"})
+	user, err := h.UserUc.GetUserByID(userId)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": user})
+	c.JSON(http.StatusOK, gin.H{"data": models.NewUserResponse(user)})
 }
 
 // CreateUser godoc
@@ -83,33 +83,32 @@ func (h *userHandler) GetUserByID(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
-// @Param user body models.User true "Create user"
-// @Success 201 {object} models.User
-// @Failure 400 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Param user body models.CreateUserRequest true "Create user"
+// @Success 201 {object} models.UserResponse
+// @Failure 400 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
 // @Router /cms/user [post]
 func (h *userHandler) CreateUser(c *gin.Context) {
-	var input models.User
-
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+	var input models.CreateUserRequest
 
-	validate := validator.NewValidator()
-	if err := validator.ValidateStruct(validate, &input); err != nil {
-		log.Println(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &input) {
 		return
 	}
 
 	user, err := h.UserUc.CreateUser(input.Username, input.Email, input.Password, input.RoleID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"user": user})
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionCreate,
+		Entity:   constant.AuditEntityUser,
+		EntityID: auditID(user.ID),
+		After:    user,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"user": models.NewUserResponse(user)})
 }
 
 // UpdateUser godoc
@@ -120,84 +119,184 @@ func (h *userHandler) CreateUser(c *gin.Context) {
 // @Produce json
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Param id path string true "User ID"
-// @Param user body models.User true "Update user"
-// @Success 200 {object} models.User
-// @Failure 400 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Param user body models.UpdateUserRequest true "Update user"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
 // @Router /cms/user/{id} [put]
 func (h *userHandler) UpdateUser(c *gin.Context) {
-	var input models.User
-	id, err := strconv.Atoi(c.Param("id"))
-
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+	var input models.UpdateUserRequest
+	id, ok := paramID(c, "id", "user")
+	if !ok {
 		return
 	}
 
-	userId, err := h.UserUc.GetUserByID(uint(id))
+	userId, err := h.UserUc.GetUserByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(err)
 		return
 	}
 
-	input.ID = userId.ID
-
-	if input.ID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-		return
-	}
-
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+	before := *userId
 
-	validate := validator.NewValidator()
-	if err := validator.ValidateStruct(validate, &input); err != nil {
-		log.Println(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &input) {
 		return
 	}
 
-	user, err := h.UserUc.UpdateUser(&input)
+	user, err := h.UserUc.UpdateUser(&models.User{
+		ID:       userId.ID,
+		Username: input.Username,
+		Email:    input.Email,
+		Password: input.Password,
+		RoleID:   input.RoleID,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionUpdate,
+		Entity:   constant.AuditEntityUser,
+		EntityID: auditID(user.ID),
+		Before:   before,
+		After:    user,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"user": models.NewUserResponse(user)})
 }
 
 // DeleteUser godoc
 // @Summary Delete user
-// @Description Delete user by user ID
+// @Description Move the user to the trash, handing the quizzes they own on to another teacher
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Param id path string true "User ID"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
 // @Router /cms/user/{id} [delete]
 func (h *userHandler) DeleteUser(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+	id, ok := paramID(c, "id", "user")
+	if !ok {
 		return
 	}
 
-	user, err := h.UserUc.GetUserByID(uint(id))
+	user, err := h.UserUc.GetUserByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(err)
 		return
 	}
 
 	err = h.UserUc.DeleteUser(user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionDelete,
+		Entity:   constant.AuditEntityUser,
+		EntityID: auditID(user.ID),
+		Before:   user,
+	})
+
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
+
+// GetTrashedUsers godoc
+// @Summary Get trashed users
+// @Description List soft-deleted users, most recently deleted first
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {array} models.UserList
+// @Failure 500 {object} apperror.Problem
+// @Router /cms/trash/users [get]
+func (h *userHandler) GetTrashedUsers(c *gin.Context) {
+	users, err := h.UserUc.GetTrashedUsers()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// RestoreUser godoc
+// @Summary Restore user
+// @Description Restore a soft-deleted user, optionally renaming it to resolve a unique-name conflict
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param id path int true "User ID"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
+// @Router /cms/trash/user/{id}/restore [post]
+func (h *userHandler) RestoreUser(c *gin.Context) {
+	id, ok := paramID(c, "id", "user")
+	if !ok {
+		return
+	}
+
+	var input restoreInput
+	if !bindOptionalJSON(c, &input) {
+		return
+	}
+
+	user, err := h.UserUc.RestoreUser(id, input.Username, input.Email)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionRestore,
+		Entity:   constant.AuditEntityUser,
+		EntityID: auditID(user.ID),
+		After:    user,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"user": models.NewUserResponse(user)})
+}
+
+// PurgeUser godoc
+// @Summary Purge user
+// @Description Permanently delete a soft-deleted user
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apperror.Problem
+// @Failure 404 {object} apperror.Problem
+// @Failure 409 {object} apperror.Problem
+// @Failure 500 {object} apperror.Problem
+// @Router /cms/trash/user/{id} [delete]
+func (h *userHandler) PurgeUser(c *gin.Context) {
+	id, ok := paramID(c, "id", "user")
+	if !ok {
+		return
+	}
+
+	user, err := h.UserUc.PurgeUser(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.AuditUc, models.AuditEntry{
+		Action:   constant.AuditActionPurge,
+		Entity:   constant.AuditEntityUser,
+		EntityID: auditID(user.ID),
+		Before:   user,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "User purged successfully"})
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ErrorHandler turns the last error attached with c.Error into an RFC 7807
+// problem+json response. Untyped errors are reported as a generic 500 so raw
+// database messages never reach the client; their cause is only logged.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		appErr := classify(err)
+		if appErr.Kind == apperror.KindInternal {
+			log.Printf("ERROR: %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+
+		problem := apperror.NewProblem(appErr, c.Request.URL.Path, c.GetString("request_id"))
+		c.Header("Content-Type", apperror.ContentTypeProblem)
+		c.JSON(problem.Status, problem)
+	}
+}
+
+// classify maps well-known gorm errors that slipped past a usecase onto
+// typed errors before falling back to internal.
+func classify(err error) *apperror.Error {
+	var appErr *apperror.Error
+	switch {
+	case errors.As(err, &appErr):
+		return appErr
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperror.NotFound("resource not found")
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return apperror.Conflict("resource already exists")
+	default:
+		return apperror.Internal(err)
+	}
+}
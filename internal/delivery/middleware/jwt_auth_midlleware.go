@@ -1,10 +1,10 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
 	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
 	"github.com/Arasy41/go-gin-quiz-api/pkg/jwt"
 	"gorm.io/gorm"
@@ -12,19 +12,19 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func JWTAuthMiddleware(db *gorm.DB, allowedRoles ...string) gin.HandlerFunc {
+func JWTAuthMiddleware(db *gorm.DB, tokens *jwt.JWT, allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader(constant.AuthorizationKey)
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.Error(apperror.Unauthorized("authorization header required"))
 			c.Abort()
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		claims, err := jwt.ParseToken(tokenString)
+		claims, err := tokens.ParseToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.Error(apperror.Unauthorized("invalid token"))
 			c.Abort()
 			return
 		}
@@ -32,7 +32,7 @@ func JWTAuthMiddleware(db *gorm.DB, allowedRoles ...string) gin.HandlerFunc {
 		// Fetch user from database to get the role
 		var user models.User
 		if err := db.Preload("Role").Where("id = ?", claims.UserID).First(&user).Error; err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Error(apperror.Unauthorized("user not found"))
 			c.Abort()
 			return
 		}
@@ -49,7 +49,7 @@ func JWTAuthMiddleware(db *gorm.DB, allowedRoles ...string) gin.HandlerFunc {
 			}
 		}
 
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to access this resource"})
+		c.Error(apperror.Forbidden("you don't have permission to access this resource"))
 		c.Abort()
 	}
 }
@@ -8,10 +8,10 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RequestLogger() gin.HandlerFunc {
+func RequestLogger(logDir string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Rotate log file
-		if err := logger.RotateLogFileIfNeeded("logs"); err != nil {
+		if err := logger.RotateLogFileIfNeeded(logDir); err != nil {
 			log.Printf("Failed to rotate log file: %v", err)
 		}
 		// Catat waktu sebelum request
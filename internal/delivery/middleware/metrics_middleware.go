@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMetrics records request count and latency per route template.
+func RequestMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+
+		c.Next()
+
+		// Unmatched requests share one label so random paths can't grow the series
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		metrics.HTTPRequestsTotal.WithLabelValues(route, c.Request.Method, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(startTime).Seconds())
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// QuizAccessMiddleware lets the request through only if the user has at
+// least the quiz role on the quiz in the :id path parameter. It runs after
+// JWTAuthMiddleware, which sets the user.
+func QuizAccessMiddleware(shareUc usecases.ShareUsecase, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		quizID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.Error(apperror.Validation("invalid quiz ID", apperror.FieldError{
+				Field:   "id",
+				Rule:    "uuid",
+				Message: "must be a UUID",
+			}))
+			c.Abort()
+			return
+		}
+
+		actor := models.Actor{ID: c.GetUint("user_id"), Role: c.GetString("user_role")}
+		if err := shareUc.Authorize(actor, quizID, role); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
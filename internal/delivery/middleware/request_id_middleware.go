@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestID tags every request with an ID, reusing the caller's X-Request-ID
+// when present, and echoes it back in the response headers.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(constant.RequestIDKey)
+		if requestID == "" || len(requestID) > 64 {
+			requestID = uuid.NewString()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(constant.RequestIDKey, requestID)
+
+		c.Next()
+	}
+}
@@ -1,11 +1,20 @@
 package router
 
 import (
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/config"
 	"github.com/Arasy41/go-gin-quiz-api/internal/delivery/http"
 	"github.com/Arasy41/go-gin-quiz-api/internal/delivery/middleware"
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
 	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/jwt"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/mailer"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/metrics"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/storage"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/validator"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -15,7 +24,7 @@ import (
 )
 
 // InitRouter initializes the main router
-func InitRouter(db *gorm.DB) *gin.Engine {
+func InitRouter(cfg *config.Config, db *gorm.DB, store storage.Storage) *gin.Engine {
 	r := gin.Default()
 
 	corsConfig := cors.DefaultConfig()
@@ -30,24 +39,80 @@ func InitRouter(db *gorm.DB) *gin.Engine {
 	r.Use(cors.New(corsConfig))
 
 	// Middleware
-	r.Use(middleware.RequestLogger())
+	r.Use(middleware.RequestID())
+	r.Use(middleware.RequestLogger(cfg.LogDir))
+	r.Use(middleware.RequestMetrics())
+	r.Use(middleware.ErrorHandler())
+
+	validator.RegisterBindingTagNames()
+	r.NoRoute(func(c *gin.Context) {
+		c.Error(apperror.NotFound("route not found"))
+	})
+
+	// Prometheus
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// Swagger
 	r.GET("/api/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Signed attachment URLs of local storage; buckets serve their own
+	if local, ok := store.(*storage.Local); ok {
+		r.GET("/media/*key", http.NewMediaHandler(local).ServeMedia)
+	}
+
+	tokens := jwt.NewJWT(cfg)
+
 	// Initialize usecases
 	userUsecase := usecases.NewUserUsecase(repositories.NewUserRepository(db))
 	roleUsecase := usecases.NewRoleUsecase(repositories.NewRoleRepository(db))
 	categoryUc := usecases.NewCategoryUsecase(repositories.NewCategoryRepository(db))
+	auditUc := usecases.NewAuditLogUsecase(repositories.NewAuditLogRepository(db))
+	quizRepo := repositories.NewQuizRepository(db)
+	tagRepo := repositories.NewTagRepository(db)
+	searchRepo := repositories.NewSearchRepository(db)
+	shareUc := usecases.NewShareUsecase(repositories.NewShareRepository(db), repositories.NewUserRepository(db))
+	quizUc := usecases.NewQuizUsecase(quizRepo, repositories.NewCategoryRepository(db), tagRepo, searchRepo, store, cfg.QuizReviewRequired)
+	classroomRepo := repositories.NewClassroomRepository(db)
+	classroomUc := usecases.NewClassroomUsecase(classroomRepo, repositories.NewUserRepository(db), quizRepo)
+	assignmentRepo := repositories.NewAssignmentRepository(db)
+	attemptRepo := repositories.NewAttemptRepository(db)
+	assignmentUc := usecases.NewAssignmentUsecase(assignmentRepo, attemptRepo, classroomRepo, quizRepo, repositories.NewUserRepository(db))
+	attemptUc := usecases.NewAttemptUsecase(attemptRepo, assignmentRepo, quizRepo, store)
+	reviewQueueUc := usecases.NewReviewQueueUsecase(repositories.NewReviewCardRepository(db), attemptRepo, assignmentRepo, quizRepo)
+	maxUploadSize := int64(cfg.MediaMaxUploadMB) << 20
+	attachmentUc := usecases.NewAttachmentUsecase(repositories.NewAttachmentRepository(db), quizRepo, store, maxUploadSize)
+	mail := mailer.NewLogMailer()
+	gradingUc := usecases.NewGradingUsecase(repositories.NewGradingRepository(db), attemptRepo, assignmentRepo, quizRepo, repositories.NewUserRepository(db), mail)
+	profileUc := usecases.NewProfileUsecase(
+		repositories.NewProfileRepository(db),
+		repositories.NewUserRepository(db),
+		mail,
+		cfg.PublicURL,
+		time.Duration(cfg.EmailVerifyTTLHour)*time.Hour,
+	)
 
 	// Initialize handlers
-	userHandler := http.NewUserHandler(userUsecase)
-	authHandler := http.NewAuthHandler(userUsecase)
-	roleHandler := http.NewRoleHandler(roleUsecase)
-	categoryHandler := http.NewCategoryHandler(categoryUc)
+	userHandler := http.NewUserHandler(userUsecase, auditUc)
+	authHandler := http.NewAuthHandler(userUsecase, auditUc, tokens)
+	roleHandler := http.NewRoleHandler(roleUsecase, auditUc)
+	categoryHandler := http.NewCategoryHandler(categoryUc, auditUc)
+	auditLogHandler := http.NewAuditLogHandler(auditUc)
+	profileHandler := http.NewProfileHandler(profileUc, auditUc)
+	quizHandler := http.NewQuizHandler(quizUc, auditUc)
+	shareHandler := http.NewShareHandler(shareUc, auditUc)
+	attachmentHandler := http.NewAttachmentHandler(attachmentUc, auditUc, maxUploadSize)
+	tagHandler := http.NewTagHandler(usecases.NewTagUsecase(tagRepo), auditUc)
+	searchHandler := http.NewSearchHandler(usecases.NewSearchUsecase(searchRepo))
+	classroomHandler := http.NewClassroomHandler(classroomUc, auditUc)
+	assignmentHandler := http.NewAssignmentHandler(assignmentUc, auditUc)
+	attemptHandler := http.NewAttemptHandler(attemptUc)
+	gradingHandler := http.NewGradingHandler(gradingUc, auditUc)
+	reviewQueueHandler := http.NewReviewQueueHandler(reviewQueueUc)
+	templateHandler := http.NewTemplateHandler(usecases.NewTemplateUsecase(repositories.NewTemplateRepository(db), quizRepo, repositories.NewCategoryRepository(db), tagRepo, searchRepo, store), auditUc)
+	catalogHandler := http.NewCatalogHandler(usecases.NewCatalogUsecase(quizRepo, repositories.NewCategoryRepository(db), store))
 
 	// Routes for Admin
-	adminRoute := r.Group("/cms", middleware.JWTAuthMiddleware(db, constant.RoleAdmin))
+	adminRoute := r.Group("/cms", middleware.JWTAuthMiddleware(db, tokens, constant.RoleAdmin))
 	{
 		// User Admin Routes
 		adminRoute.GET("/users", userHandler.GetAllUsers)
@@ -66,10 +131,145 @@ func InitRouter(db *gorm.DB) *gin.Engine {
 		// Category Admin Routes
 		adminRoute.GET("/categories", categoryHandler.GetAllCategories)
 		adminRoute.GET("/category/:id", categoryHandler.GetCategoryByID)
-		adminRoute.GET("/category/:name", categoryHandler.GetCategoryByName)
+		adminRoute.GET("/category/slug/:slug", categoryHandler.GetCategoryBySlug)
 		adminRoute.POST("/category", categoryHandler.CreateCategory)
 		adminRoute.PUT("/category/:id", categoryHandler.UpdateCategory)
 		adminRoute.DELETE("/category/:id", categoryHandler.DeleteCategory)
+		adminRoute.POST("/category/:id/move", categoryHandler.MoveCategory)
+		adminRoute.POST("/category/:id/merge", categoryHandler.MergeCategory)
+
+		// Tag Admin Routes
+		adminRoute.PUT("/tag/:id", tagHandler.RenameTag)
+		adminRoute.POST("/tag/:id/merge", tagHandler.MergeTag)
+
+		// Quiz Template Admin Routes
+		adminRoute.POST("/template", templateHandler.CreateTemplate)
+		adminRoute.PUT("/template/:id", templateHandler.UpdateTemplate)
+		adminRoute.DELETE("/template/:id", templateHandler.DeleteTemplate)
+
+		// Trash Admin Routes
+		adminRoute.GET("/trash/users", userHandler.GetTrashedUsers)
+		adminRoute.POST("/trash/user/:id/restore", userHandler.RestoreUser)
+		adminRoute.DELETE("/trash/user/:id", userHandler.PurgeUser)
+		adminRoute.GET("/trash/roles", roleHandler.GetTrashedRoles)
+		adminRoute.POST("/trash/role/:id/restore", roleHandler.RestoreRole)
+		adminRoute.DELETE("/trash/role/:id", roleHandler.PurgeRole)
+		adminRoute.GET("/trash/categories", categoryHandler.GetTrashedCategories)
+		adminRoute.POST("/trash/category/:id/restore", categoryHandler.RestoreCategory)
+		adminRoute.DELETE("/trash/category/:id", categoryHandler.PurgeCategory)
+
+		// Audit Log Routes
+		adminRoute.GET("/audit-logs", auditLogHandler.GetAuditLogs)
+	}
+
+	// Routes for Teacher (admins pass every role check)
+	teacherRoute := r.Group("/teacher", middleware.JWTAuthMiddleware(db, tokens, constant.RoleTeacher))
+	{
+		// Quiz routes with an :id check the caller's role on that quiz
+		canView := middleware.QuizAccessMiddleware(shareUc, constant.QuizRoleViewer)
+		canEdit := middleware.QuizAccessMiddleware(shareUc, constant.QuizRoleEditor)
+		isOwner := middleware.QuizAccessMiddleware(shareUc, constant.QuizRoleOwner)
+		canReview := middleware.QuizAccessMiddleware(shareUc, constant.QuizRoleReviewer)
+
+		// Quiz Routes
+		teacherRoute.GET("/quizzes", quizHandler.GetAllQuizzes)
+		teacherRoute.GET("/quizzes/difficulty-suggestions", quizHandler.GetDifficultySuggestions)
+		teacherRoute.GET("/quiz/:id", canView, quizHandler.GetQuizByID)
+		teacherRoute.POST("/quiz", quizHandler.CreateQuiz)
+		teacherRoute.PUT("/quiz/:id", canEdit, quizHandler.UpdateQuiz)
+		teacherRoute.DELETE("/quiz/:id", isOwner, quizHandler.DeleteQuiz)
+		teacherRoute.GET("/questions", quizHandler.GetQuestions)
+		teacherRoute.GET("/tags", tagHandler.SearchTags)
+		teacherRoute.GET("/search", searchHandler.Search)
+		teacherRoute.POST("/quiz/:id/calibrate", canEdit, quizHandler.CalibrateQuiz)
+		teacherRoute.POST("/quiz/:id/duplicate", canView, quizHandler.DuplicateQuiz)
+
+		// Quiz Sharing
+		teacherRoute.GET("/quiz/:id/collaborators", canView, shareHandler.GetCollaborators)
+		teacherRoute.POST("/quiz/:id/collaborators", isOwner, shareHandler.ShareQuiz)
+		teacherRoute.DELETE("/quiz/:id/collaborator/:user_id", isOwner, shareHandler.UnshareQuiz)
+		teacherRoute.POST("/quiz/:id/transfer", isOwner, shareHandler.TransferQuiz)
+
+		// Quiz Templates
+		teacherRoute.GET("/templates", templateHandler.GetTemplates)
+		teacherRoute.GET("/template/:id", templateHandler.GetTemplate)
+		teacherRoute.POST("/template/:id/quiz", templateHandler.CreateQuiz)
+
+		// Quiz Publishing Workflow
+		teacherRoute.GET("/quiz/:id/revision", canView, quizHandler.GetRevision)
+		teacherRoute.POST("/quiz/:id/submit", canEdit, quizHandler.SubmitQuiz)
+		teacherRoute.POST("/quiz/:id/approve", canReview, quizHandler.ApproveQuiz)
+		teacherRoute.POST("/quiz/:id/reject", canReview, quizHandler.RejectQuiz)
+		teacherRoute.POST("/quiz/:id/publish", canEdit, quizHandler.PublishQuiz)
+		teacherRoute.POST("/quiz/:id/archive", canEdit, quizHandler.ArchiveQuiz)
+
+		// Quiz Versions
+		teacherRoute.GET("/quiz/:id/versions", canView, quizHandler.GetVersions)
+		teacherRoute.GET("/quiz/:id/versions/:version", canView, quizHandler.GetVersion)
+		teacherRoute.GET("/quiz/:id/diff", canView, quizHandler.DiffVersions)
+		teacherRoute.PUT("/quiz/:id/question/:question_id/answer-key", canEdit, quizHandler.CorrectAnswerKey)
+		teacherRoute.POST("/quiz/:id/regrade", canEdit, gradingHandler.RegradeQuiz)
+
+		// Question Attachments
+		teacherRoute.POST("/quiz/:id/question/:question_id/attachments", canEdit, attachmentHandler.UploadAttachment)
+		teacherRoute.DELETE("/quiz/:id/attachment/:attachment_id", canEdit, attachmentHandler.DeleteAttachment)
+
+		// Classroom Routes
+		teacherRoute.GET("/classrooms", classroomHandler.GetClassrooms)
+		teacherRoute.GET("/classroom/:id", classroomHandler.GetClassroom)
+		teacherRoute.POST("/classroom", classroomHandler.CreateClassroom)
+		teacherRoute.PUT("/classroom/:id", classroomHandler.UpdateClassroom)
+		teacherRoute.DELETE("/classroom/:id", classroomHandler.DeleteClassroom)
+		teacherRoute.POST("/classroom/:id/join-code", classroomHandler.RegenerateJoinCode)
+		teacherRoute.GET("/classroom/:id/students", classroomHandler.GetRoster)
+		teacherRoute.POST("/classroom/:id/students", classroomHandler.EnrollStudents)
+		teacherRoute.POST("/classroom/:id/students/import", classroomHandler.ImportRoster)
+		teacherRoute.DELETE("/classroom/:id/student/:user_id", classroomHandler.RemoveStudent)
+		teacherRoute.GET("/classroom/:id/quizzes", classroomHandler.GetClassroomQuizzes)
+		teacherRoute.POST("/classroom/:id/quizzes", classroomHandler.AssignQuiz)
+		teacherRoute.DELETE("/classroom/:id/quiz/:quiz_id", classroomHandler.UnassignQuiz)
+		teacherRoute.GET("/classroom/:id/results", classroomHandler.GetResults)
+
+		// Assignment Routes
+		teacherRoute.GET("/assignments", assignmentHandler.GetAssignments)
+		teacherRoute.GET("/assignment/:id", assignmentHandler.GetAssignment)
+		teacherRoute.POST("/assignment", assignmentHandler.CreateAssignment)
+		teacherRoute.PUT("/assignment/:id", assignmentHandler.UpdateAssignment)
+		teacherRoute.DELETE("/assignment/:id", assignmentHandler.DeleteAssignment)
+		teacherRoute.GET("/assignment/:id/grades", assignmentHandler.GetGrades)
+
+		// Grading Routes
+		teacherRoute.GET("/grading", gradingHandler.GetQueue)
+		teacherRoute.POST("/grading/answer/:id", gradingHandler.GradeAnswer)
+	}
+
+	// Routes for Student
+	studentRoute := r.Group("/student", middleware.JWTAuthMiddleware(db, tokens, constant.RoleStudent))
+	{
+		// Catalog
+		studentRoute.GET("/catalog", catalogHandler.GetCatalog)
+		studentRoute.GET("/catalog/quiz/:id", catalogHandler.GetCatalogQuiz)
+
+		studentRoute.GET("/classrooms", classroomHandler.GetStudentClassrooms)
+		studentRoute.POST("/classrooms/join", classroomHandler.JoinClassroom)
+		studentRoute.DELETE("/classroom/:id", classroomHandler.LeaveClassroom)
+
+		// Assignments and Attempts
+		studentRoute.GET("/assignments/due", assignmentHandler.GetDueAssignments)
+		studentRoute.GET("/attempts", attemptHandler.GetMyAttempts)
+		studentRoute.POST("/assignment/:id/attempts", attemptHandler.StartAssignmentAttempt)
+		studentRoute.GET("/attempt/:id", attemptHandler.GetAttempt)
+		studentRoute.PUT("/attempt/:id/answer", attemptHandler.AnswerQuestion)
+		studentRoute.POST("/attempt/:id/finish", attemptHandler.FinishAttempt)
+		studentRoute.GET("/attempt/:id/review", attemptHandler.ReviewAttempt)
+
+		// Practice
+		studentRoute.POST("/quiz/:id/practice", attemptHandler.StartPractice)
+		studentRoute.POST("/attempt/:id/retry", attemptHandler.RetryMissed)
+
+		// Spaced Repetition
+		studentRoute.GET("/reviews/due", reviewQueueHandler.GetDueReviews)
+		studentRoute.POST("/review/:id", reviewQueueHandler.RecordRecall)
 	}
 
 	// Auth Routes
@@ -77,8 +277,16 @@ func InitRouter(db *gorm.DB) *gin.Engine {
 	{
 		authRoute.POST("/login", authHandler.Login)
 		authRoute.POST("/register", authHandler.Register)
-		authRoute.PUT("/change-password", middleware.JWTAuthMiddleware(db, constant.AllRoles...), authHandler.ChangePassword)
-		authRoute.GET("/user", middleware.JWTAuthMiddleware(db, constant.AllRoles...), authHandler.GetCurrentUser)
+		authRoute.PUT("/change-password", middleware.JWTAuthMiddleware(db, tokens, constant.AllRoles...), authHandler.ChangePassword)
+		authRoute.GET("/user", middleware.JWTAuthMiddleware(db, tokens, constant.AllRoles...), authHandler.GetCurrentUser)
+
+		// Self-service profile
+		authRoute.GET("/profile", middleware.JWTAuthMiddleware(db, tokens, constant.AllRoles...), profileHandler.GetProfile)
+		authRoute.PUT("/profile", middleware.JWTAuthMiddleware(db, tokens, constant.AllRoles...), profileHandler.UpdateProfile)
+		authRoute.POST("/profile/email", middleware.JWTAuthMiddleware(db, tokens, constant.AllRoles...), profileHandler.RequestEmailChange)
+		authRoute.GET("/profile/email/verify", profileHandler.VerifyEmailChange)
+		authRoute.POST("/profile/email/verify", profileHandler.VerifyEmailChange)
+		authRoute.DELETE("/account", middleware.JWTAuthMiddleware(db, tokens, constant.AllRoles...), profileHandler.DeleteAccount)
 	}
 
 	return r
@@ -0,0 +1,352 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Arasy41/go-gin-quiz-api/config"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/db"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/storage"
+	"github.com/gin-gonic/gin"
+)
+
+const testPassword = "password123"
+
+// testServer is the API on a fresh sqlite database, with an admin, a
+// teacher and a student signed in.
+type testServer struct {
+	t      *testing.T
+	router *gin.Engine
+	tokens map[string]string
+	ids    map[string]uint
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	cfg := &config.Config{
+		Environment:        config.EnvDevelopment,
+		LogDir:             filepath.Join(dir, "logs"),
+		DBProvider:         config.ProviderSQLite,
+		DBName:             filepath.Join(dir, "quiz.db"),
+		SecretKey:          "0123456789abcdef0123456789abcdef",
+		TokenLifespan:      1,
+		EmailVerifyTTLHour: 1,
+		PublicURL:          "http://localhost",
+		MediaStorage:       config.StorageLocal,
+		MediaDir:           filepath.Join(dir, "media"),
+		MediaMaxUploadMB:   1,
+		MediaURLTTLMinutes: 1,
+	}
+	db.InitDB(cfg)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	roles := []models.Role{
+		{ID: constant.RoleAdminID, Name: constant.RoleAdmin},
+		{ID: constant.RoleStudentID, Name: constant.RoleStudent},
+		{ID: constant.RoleTeacherID, Name: constant.RoleTeacher},
+	}
+	if err := db.DB.Create(&roles).Error; err != nil {
+		t.Fatalf("creating roles: %v", err)
+	}
+
+	store, err := storage.New(cfg)
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+	s := &testServer{
+		t:      t,
+		router: InitRouter(cfg, db.DB, store),
+		tokens: map[string]string{},
+		ids:    map[string]uint{},
+	}
+
+	for _, name := range []string{"admin", "teacher", "student"} {
+		role := constant.RoleStudent
+		if name == "teacher" {
+			role = constant.RoleTeacher
+		}
+		body := s.do(http.MethodPost, "/auth/register", "", gin.H{
+			"username":  name,
+			"email":     name + "@example.com",
+			"password":  testPassword,
+			"role_name": role,
+		}, http.StatusCreated)
+		s.ids[name] = uint(body["user"].(map[string]interface{})["id"].(float64))
+	}
+	if err := db.DB.Model(&models.User{}).Where("id = ?", s.ids["admin"]).Update("role_id", constant.RoleAdminID).Error; err != nil {
+		t.Fatalf("promoting admin: %v", err)
+	}
+	for _, name := range []string{"admin", "teacher", "student"} {
+		body := s.do(http.MethodPost, "/auth/login", "", gin.H{"username": name, "password": testPassword}, http.StatusOK)
+		s.tokens[name] = body["token"].(string)
+	}
+	return s
+}
+
+// do sends the request as the signed-in user, if any, checks the status and
+// that the response leaks no password, and returns the decoded body.
+func (s *testServer) do(method, path, user string, payload interface{}, status int) map[string]interface{} {
+	s.t.Helper()
+
+	var reqBody bytes.Buffer
+	if payload != nil {
+		if err := json.NewEncoder(&reqBody).Encode(payload); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &reqBody)
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req.Header.Set("Authorization", "Bearer "+s.tokens[user])
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+
+	if rec.Code != status {
+		s.t.Fatalf("%s %s = %d, want %d: %s", method, path, rec.Code, status, rec.Body)
+	}
+	body := map[string]interface{}{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		s.t.Fatalf("%s %s: decoding %q: %v", method, path, rec.Body, err)
+	}
+	for _, leak := range findLeaks(body, "", "password") {
+		s.t.Errorf("%s %s leaks %s", method, path, leak)
+	}
+	return body
+}
+
+// findLeaks walks a decoded JSON document and returns the paths of the
+// forbidden keys it has and of the values that look like bcrypt hashes.
+func findLeaks(v interface{}, path string, keys ...string) []string {
+	var leaks []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			for _, forbidden := range keys {
+				if strings.EqualFold(key, forbidden) {
+					leaks = append(leaks, path+"."+key)
+				}
+			}
+			leaks = append(leaks, findLeaks(value, path+"."+key, keys...)...)
+		}
+	case []interface{}:
+		for i, value := range v {
+			leaks = append(leaks, findLeaks(value, fmt.Sprintf("%s[%d]", path, i), keys...)...)
+		}
+	case string:
+		if strings.HasPrefix(v, "$2a$") || strings.HasPrefix(v, "$2b$") {
+			leaks = append(leaks, path+" (bcrypt hash)")
+		}
+	}
+	return leaks
+}
+
+func TestUserEndpointsHidePasswords(t *testing.T) {
+	s := newTestServer(t)
+	student := fmt.Sprintf("/cms/user/%d", s.ids["student"])
+
+	s.do(http.MethodGet, "/auth/user", "student", nil, http.StatusOK)
+	s.do(http.MethodGet, "/cms/users", "admin", nil, http.StatusOK)
+	s.do(http.MethodGet, student, "admin", nil, http.StatusOK)
+	s.do(http.MethodPost, "/cms/user", "admin", gin.H{
+		"username": "student2",
+		"email":    "student2@example.com",
+		"password": testPassword,
+		"role_id":  constant.RoleStudentID,
+	}, http.StatusCreated)
+	s.do(http.MethodPut, student, "admin", gin.H{"password": "another-password", "role_id": constant.RoleTeacherID}, http.StatusOK)
+
+	s.do(http.MethodDelete, student, "admin", nil, http.StatusOK)
+	trash := s.do(http.MethodGet, "/cms/trash/users", "admin", nil, http.StatusOK)
+	if users, _ := trash["users"].([]interface{}); len(users) != 1 {
+		t.Fatalf("trash has %d users, want the deleted student", len(users))
+	}
+	s.do(http.MethodPost, fmt.Sprintf("/cms/trash/user/%d/restore", s.ids["student"]), "admin", nil, http.StatusOK)
+}
+
+func TestAuditLogsHideIgnoredFields(t *testing.T) {
+	s := newTestServer(t)
+	student := fmt.Sprintf("/cms/user/%d", s.ids["student"])
+
+	s.do(http.MethodPut, student, "admin", gin.H{"password": "another-password", "role_id": constant.RoleTeacherID}, http.StatusOK)
+	s.do(http.MethodDelete, student, "admin", nil, http.StatusOK)
+	s.do(http.MethodPost, fmt.Sprintf("/cms/trash/user/%d/restore", s.ids["student"]), "admin", nil, http.StatusOK)
+
+	body := s.do(http.MethodGet, "/cms/audit-logs?entity="+constant.AuditEntityUser+"&page_size=100", "admin", nil, http.StatusOK)
+	logs, _ := body["audit_logs"].([]interface{})
+
+	var updated bool
+	for _, entry := range logs {
+		entry := entry.(map[string]interface{})
+		for _, leak := range findLeaks(entry, "audit_log", "password", "Role") {
+			t.Errorf("audit log %v leaks %s", entry["id"], leak)
+		}
+		if entry["action"] == constant.AuditActionUpdate {
+			changes, _ := entry["changes"].(map[string]interface{})
+			if _, ok := changes["role_id"]; !ok {
+				t.Errorf("update changes %v lack the role_id change", changes)
+			}
+			updated = true
+		}
+	}
+	if !updated {
+		t.Fatalf("no user update among the audit logs %v", logs)
+	}
+}
+
+func TestPurgeUserLeavesNoDanglingRows(t *testing.T) {
+	s := newTestServer(t)
+
+	body := s.do(http.MethodPost, "/teacher/classroom", "teacher", gin.H{"name": "Biology"}, http.StatusCreated)
+	classroomID := uint(body["classroom"].(map[string]interface{})["id"].(float64))
+	s.do(http.MethodPost, fmt.Sprintf("/teacher/classroom/%d/students", classroomID), "teacher", gin.H{"students": []string{"student"}}, http.StatusOK)
+
+	assignment := models.Assignment{
+		Title:         "Homework",
+		ScoringPolicy: constant.ScoringBest,
+		CreatedBy:     s.ids["teacher"],
+		Users:         []models.AssignmentUser{{UserID: s.ids["student"]}},
+	}
+	if err := db.DB.Create(&assignment).Error; err != nil {
+		t.Fatalf("creating assignment: %v", err)
+	}
+
+	for _, name := range []string{"teacher", "student"} {
+		s.do(http.MethodDelete, fmt.Sprintf("/cms/user/%d", s.ids[name]), "admin", nil, http.StatusOK)
+		s.do(http.MethodDelete, fmt.Sprintf("/cms/trash/user/%d", s.ids[name]), "admin", nil, http.StatusOK)
+	}
+
+	var classroom models.Classroom
+	if err := db.DB.First(&classroom, classroomID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if classroom.TeacherID != s.ids["admin"] {
+		t.Errorf("classroom teacher = %d, want the admin %d", classroom.TeacherID, s.ids["admin"])
+	}
+	if err := db.DB.First(&assignment, assignment.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if assignment.CreatedBy != s.ids["admin"] {
+		t.Errorf("assignment creator = %d, want the admin %d", assignment.CreatedBy, s.ids["admin"])
+	}
+	for _, row := range []interface{}{&models.ClassroomMember{}, &models.AssignmentUser{}} {
+		var count int64
+		if err := db.DB.Model(row).Where("user_id = ?", s.ids["student"]).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%T still has %d rows for the purged student", row, count)
+		}
+	}
+}
+
+// object returns the JSON object under key.
+func object(body map[string]interface{}, key string) map[string]interface{} {
+	v, _ := body[key].(map[string]interface{})
+	return v
+}
+
+func TestRegradeKeepsAttemptsAtReplacedQuestions(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.do(http.MethodPost, "/cms/category", "admin", gin.H{"name": "Math"}, http.StatusOK)
+	quizRequest := func(questions ...string) gin.H {
+		qs := []gin.H{}
+		for _, text := range questions {
+			qs = append(qs, gin.H{"text": text, "options": []gin.H{{"text": "right", "correct": true}, {"text": "wrong"}}})
+		}
+		return gin.H{"title": "Sums", "category_id": category["id"], "difficulty": "easy", "duration_minutes": 10, "questions": qs}
+	}
+	quiz := object(s.do(http.MethodPost, "/teacher/quiz", "teacher", quizRequest("1+1", "2+2"), http.StatusCreated), "quiz")
+	quizPath := "/teacher/quiz/" + quiz["id"].(string)
+	s.do(http.MethodPost, quizPath+"/publish", "teacher", nil, http.StatusOK)
+
+	first := quiz["questions"].([]interface{})[0].(map[string]interface{})
+	var wrong string
+	for _, o := range first["options"].([]interface{}) {
+		if id := o.(map[string]interface{})["id"].(string); id != first["answer_id"] {
+			wrong = id
+		}
+	}
+
+	assignment := object(s.do(http.MethodPost, "/teacher/assignment", "teacher", gin.H{
+		"quiz_id":  quiz["id"],
+		"user_ids": []uint{s.ids["student"]},
+	}, http.StatusCreated), "assignment")
+	attempt := object(s.do(http.MethodPost, fmt.Sprintf("/student/assignment/%v/attempts", assignment["id"]), "student", nil, http.StatusCreated), "attempt")
+	attemptPath := "/student/attempt/" + attempt["id"].(string)
+	s.do(http.MethodPut, attemptPath+"/answer", "student", gin.H{"question_id": first["id"], "option_id": wrong}, http.StatusOK)
+	s.do(http.MethodPost, attemptPath+"/finish", "student", nil, http.StatusOK)
+
+	// A corrected answer key keeps the questions, so the attempt moves on.
+	s.do(http.MethodPut, fmt.Sprintf("%s/question/%s/answer-key", quizPath, first["id"]), "teacher", gin.H{"option_id": wrong}, http.StatusOK)
+	regrade := object(s.do(http.MethodPost, quizPath+"/regrade", "teacher", nil, http.StatusOK), "regrade")
+	if regrade["attempts"] != 1.0 || regrade["scores_changed"] != 1.0 || regrade["kept"] != 0.0 {
+		t.Fatalf("regrade after answer key fix = %v, want the attempt rescored", regrade)
+	}
+
+	// A published revision replaces the questions, so the attempt stays.
+	revision := object(s.do(http.MethodPut, quizPath, "teacher", quizRequest("3+3"), http.StatusOK), "quiz")
+	s.do(http.MethodPost, "/teacher/quiz/"+revision["id"].(string)+"/publish", "teacher", nil, http.StatusOK)
+	regrade = object(s.do(http.MethodPost, quizPath+"/regrade", "teacher", nil, http.StatusOK), "regrade")
+	if regrade["attempts"] != 0.0 || regrade["kept"] != 1.0 {
+		t.Fatalf("regrade after revision = %v, want the attempt kept", regrade)
+	}
+
+	view := object(s.do(http.MethodGet, attemptPath, "student", nil, http.StatusOK), "attempt")
+	if view["quiz_version"] != 2.0 || view["score"] != 50.0 {
+		t.Errorf("attempt at version %v with score %v, want version 2 and score 50", view["quiz_version"], view["score"])
+	}
+	questions, _ := view["questions"].([]interface{})
+	if len(questions) != 2 || questions[0].(map[string]interface{})["id"] != first["id"] {
+		t.Errorf("attempt shows questions %v, want the two it was taken with", questions)
+	}
+}
+
+func TestCategoryMergeAndPurgeCoverTemplates(t *testing.T) {
+	s := newTestServer(t)
+
+	math := s.do(http.MethodPost, "/cms/category", "admin", gin.H{"name": "Math"}, http.StatusOK)
+	algebra := s.do(http.MethodPost, "/cms/category", "admin", gin.H{"name": "Algebra"}, http.StatusOK)
+	quiz := object(s.do(http.MethodPost, "/teacher/quiz", "teacher", gin.H{
+		"title": "Equations", "category_id": algebra["id"], "difficulty": "easy", "duration_minutes": 10,
+		"questions": []gin.H{{"text": "x+1=2", "options": []gin.H{{"text": "1", "correct": true}, {"text": "2"}}}},
+	}, http.StatusCreated), "quiz")
+	template := object(s.do(http.MethodPost, "/cms/template", "admin", gin.H{"quiz_id": quiz["id"]}, http.StatusCreated), "template")
+	templatePath := fmt.Sprintf("/cms/template/%v", template["id"])
+	s.do(http.MethodDelete, "/teacher/quiz/"+quiz["id"].(string), "teacher", nil, http.StatusOK)
+
+	// Merging moves the template with the category.
+	s.do(http.MethodPost, fmt.Sprintf("/cms/category/%v/merge", algebra["id"]), "admin", gin.H{"target_id": math["id"]}, http.StatusOK)
+	listed := s.do(http.MethodGet, fmt.Sprintf("/teacher/templates?category_id=%v", math["id"]), "teacher", nil, http.StatusOK)
+	if templates, _ := listed["templates"].([]interface{}); len(templates) != 1 {
+		t.Fatalf("merged category lists %d templates, want 1", len(templates))
+	}
+	created := object(s.do(http.MethodPost, fmt.Sprintf("/teacher/template/%v/quiz", template["id"]), "teacher", gin.H{}, http.StatusCreated), "quiz")
+	if created["category_id"] != math["id"] {
+		t.Errorf("quiz from template in category %v, want the merge target %v", created["category_id"], math["id"])
+	}
+	s.do(http.MethodDelete, "/teacher/quiz/"+created["id"].(string), "teacher", nil, http.StatusOK)
+
+	// A category a template still uses isn't purged.
+	s.do(http.MethodDelete, fmt.Sprintf("/cms/category/%v", math["id"]), "admin", nil, http.StatusOK)
+	purgePath := fmt.Sprintf("/cms/trash/category/%v", math["id"])
+	s.do(http.MethodDelete, purgePath, "admin", nil, http.StatusConflict)
+	s.do(http.MethodDelete, templatePath, "admin", nil, http.StatusOK)
+	s.do(http.MethodDelete, purgePath, "admin", nil, http.StatusOK)
+}
@@ -0,0 +1,14 @@
+package models
+
+import "github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+
+// Actor is the authenticated user performing a request, as far as usecases
+// need to know for ownership checks.
+type Actor struct {
+	ID   uint
+	Role string
+}
+
+func (a Actor) IsAdmin() bool {
+	return a.Role == constant.RoleAdmin
+}
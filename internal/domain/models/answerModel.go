@@ -1,19 +1,69 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// Answer is a participant's response to one question: a chosen option for
+// multiple choice, or Text for an essay, which stays ungraded until a
+// teacher scores it.
 type Answer struct {
-	ID            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-	ParticipantID uuid.UUID `json:"participant_id"`
-	QuestionID    uuid.UUID `json:"question_id"`
-	OptionID      uuid.UUID `json:"option_id"`
-	Correct       bool      `json:"correct"`
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	ParticipantID uuid.UUID  `gorm:"uniqueIndex:idx_participant_question" json:"participant_id"`
+	QuestionID    uuid.UUID  `gorm:"uniqueIndex:idx_participant_question" json:"question_id"`
+	OptionID      uuid.UUID  `json:"option_id"`
+	Text          string     `gorm:"type:text" json:"text,omitempty"`
+	Correct       bool       `json:"correct"`
+	NeedsGrading  bool       `gorm:"index" json:"needs_grading"`
+	Points        int        `json:"points"`
+	MaxPoints     int        `json:"max_points"`
+	Comment       string     `gorm:"type:text" json:"comment,omitempty"`
+	GradedBy      *uint      `json:"graded_by,omitempty"`
+	GradedAt      *time.Time `json:"graded_at,omitempty"`
+	AnsweredAt    time.Time  `json:"answered_at"`
 }
 
 func (answer *Answer) BeforeCreate(tx *gorm.DB) (err error) {
-	answer.ID = uuid.New()
+	if answer.ID == uuid.Nil {
+		answer.ID = uuid.New()
+	}
 	return nil
 }
+
+// RubricScore is the points a grader awarded an answer for one criterion.
+type RubricScore struct {
+	ID          uint      `gorm:"primaryKey" json:"-"`
+	AnswerID    uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_answer_criterion" json:"-"`
+	CriterionID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_answer_criterion" json:"criterion_id"`
+	Points      int       `json:"points"`
+}
+
+// GradingItem is an essay answer waiting in the grading queue.
+type GradingItem struct {
+	AnswerID      uuid.UUID `json:"answer_id"`
+	ParticipantID uuid.UUID `json:"participant_id"`
+	UserID        uint      `json:"user_id"`
+	Username      string    `json:"username"`
+	QuizID        uuid.UUID `json:"quiz_id"`
+	QuizTitle     string    `json:"quiz_title"`
+	QuestionID    uuid.UUID `json:"question_id"`
+	QuestionText  string    `json:"question_text"`
+	// QuestionTextHTML is filled in by the usecase.
+	QuestionTextHTML string            `json:"question_text_html" gorm:"-"`
+	Text             string            `json:"text"`
+	AnsweredAt       time.Time         `json:"answered_at"`
+	Rubric           []RubricCriterion `json:"rubric" gorm:"-"`
+}
+
+type GradeAnswerRequest struct {
+	Scores  []CriterionScore `json:"scores" binding:"required,dive"`
+	Comment string           `json:"comment"`
+}
+
+type CriterionScore struct {
+	CriterionID uuid.UUID `json:"criterion_id" binding:"required"`
+	Points      int       `json:"points" binding:"min=0"`
+}
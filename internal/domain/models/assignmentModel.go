@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Assignment schedules a quiz for a classroom or for an explicit set of
+// students. Submissions after ClosesAt are accepted until LateUntil with
+// LatePenalty percent taken off the score.
+type Assignment struct {
+	ID            uint             `gorm:"primaryKey" json:"id"`
+	QuizID        uuid.UUID        `gorm:"type:uuid;not null;index" json:"quiz_id"`
+	ClassroomID   *uint            `gorm:"index" json:"classroom_id"`
+	Title         string           `gorm:"type:varchar(255);not null" json:"title"`
+	OpensAt       *time.Time       `json:"opens_at"`
+	ClosesAt      *time.Time       `json:"closes_at"`
+	LateUntil     *time.Time       `json:"late_until"`
+	LatePenalty   int              `gorm:"not null;default:0" json:"late_penalty"`
+	MaxAttempts   int              `gorm:"not null;default:0" json:"max_attempts"`
+	ScoringPolicy string           `gorm:"type:varchar(16);not null" json:"scoring_policy"`
+	CreatedBy     uint             `gorm:"not null;index" json:"created_by"`
+	Users         []AssignmentUser `json:"-"`
+	UserIDs       []uint           `gorm:"-" json:"user_ids,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt   `gorm:"index" json:"-"`
+}
+
+// AssignmentUser targets an assignment at a single student.
+type AssignmentUser struct {
+	ID           uint `gorm:"primaryKey" json:"id"`
+	AssignmentID uint `gorm:"not null;uniqueIndex:idx_assignment_user" json:"assignment_id"`
+	UserID       uint `gorm:"not null;uniqueIndex:idx_assignment_user;index" json:"user_id"`
+}
+
+// Deadline is the last moment an attempt is accepted, late or not.
+func (a *Assignment) Deadline() *time.Time {
+	if a.LateUntil != nil {
+		return a.LateUntil
+	}
+	return a.ClosesAt
+}
+
+// AssignmentRequest creates or updates an assignment. Exactly one of
+// ClassroomID and UserIDs must be given.
+type AssignmentRequest struct {
+	QuizID        uuid.UUID  `json:"quiz_id" binding:"required"`
+	ClassroomID   *uint      `json:"classroom_id"`
+	UserIDs       []uint     `json:"user_ids"`
+	Title         string     `json:"title" binding:"max=255"`
+	OpensAt       *time.Time `json:"opens_at"`
+	ClosesAt      *time.Time `json:"closes_at"`
+	LateUntil     *time.Time `json:"late_until"`
+	LatePenalty   int        `json:"late_penalty" binding:"min=0,max=100"`
+	MaxAttempts   int        `json:"max_attempts" binding:"min=0"`
+	ScoringPolicy string     `json:"scoring_policy" binding:"omitempty,oneof=best last average"`
+}
+
+// DueAssignment is an assignment as listed to a student.
+type DueAssignment struct {
+	ID            uint       `json:"id"`
+	QuizID        uuid.UUID  `json:"quiz_id"`
+	Title         string     `json:"title"`
+	Status        string     `json:"status"`
+	OpensAt       *time.Time `json:"opens_at"`
+	ClosesAt      *time.Time `json:"closes_at"`
+	LateUntil     *time.Time `json:"late_until"`
+	LatePenalty   int        `json:"late_penalty"`
+	MaxAttempts   int        `json:"max_attempts"`
+	AttemptsUsed  int        `json:"attempts_used"`
+	ScoringPolicy string     `json:"scoring_policy"`
+	Grade         *int       `json:"grade"`
+}
+
+// StudentGrade is a student's standing on an assignment, with Grade
+// computed from their finished, fully graded attempts by the scoring policy.
+type StudentGrade struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Attempts int    `json:"attempts"`
+	Grade    *int   `json:"grade"`
+}
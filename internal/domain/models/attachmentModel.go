@@ -0,0 +1,47 @@
+package models
+
+import (
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Attachment is an image or audio file shown with a question, or with one
+// of its options when OptionID is set. Copies of a question share the
+// stored files of their attachments. URL and ThumbnailURL are signed when
+// the question is shown and expire after a while. Students see neither the
+// storage keys nor the filename, which might give an answer away.
+type Attachment struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	QuestionID   uuid.UUID  `gorm:"type:uuid;index" json:"question_id"`
+	OptionID     *uuid.UUID `gorm:"type:uuid" json:"option_id"`
+	Kind         string     `gorm:"type:varchar(16);not null" json:"kind"`
+	ContentType  string     `gorm:"type:varchar(64);not null" json:"content_type"`
+	Filename     string     `gorm:"type:varchar(255)" json:"filename,omitempty"`
+	Size         int64      `json:"size"`
+	Width        int        `json:"width,omitempty"`
+	Height       int        `json:"height,omitempty"`
+	StorageKey   string     `gorm:"type:varchar(255);not null;index" json:"storage_key,omitempty"`
+	ThumbnailKey string     `gorm:"type:varchar(255)" json:"thumbnail_key,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	URL          string     `gorm:"-" json:"url,omitempty"`
+	ThumbnailURL string     `gorm:"-" json:"thumbnail_url,omitempty"`
+}
+
+func (attachment *Attachment) BeforeCreate(tx *gorm.DB) (err error) {
+	if attachment.ID == uuid.Nil {
+		attachment.ID = uuid.New()
+	}
+	return nil
+}
+
+// AttachmentUpload is a file uploaded for a question, or for one of its
+// options when OptionID is set.
+type AttachmentUpload struct {
+	OptionID *uuid.UUID
+	Filename string
+	Size     int64
+	File     io.ReadSeeker
+}
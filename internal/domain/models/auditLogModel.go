@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+type AuditLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ActorID   uint      `gorm:"index" json:"actor_id"`
+	ActorRole string    `json:"actor_role"`
+	Action    string    `gorm:"index;not null" json:"action"`
+	Entity    string    `gorm:"index;not null" json:"entity"`
+	EntityID  string    `gorm:"index" json:"entity_id"`
+	Before    JSONText  `gorm:"type:text" json:"before,omitempty"`
+	After     JSONText  `gorm:"type:text" json:"after,omitempty"`
+	Changes   JSONText  `gorm:"type:text" json:"changes,omitempty"`
+	IP        string    `json:"ip"`
+	RequestID string    `gorm:"index" json:"request_id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// AuditEntry is what callers hand to the audit usecase; Before and After are
+// the entity snapshots around the change and may be nil for create/delete.
+type AuditEntry struct {
+	ActorID   uint
+	ActorRole string
+	Action    string
+	Entity    string
+	EntityID  string
+	Before    interface{}
+	After     interface{}
+	IP        string
+	RequestID string
+}
+
+type AuditLogFilter struct {
+	ActorID  uint
+	Action   string
+	Entity   string
+	EntityID string
+	From     *time.Time
+	To       *time.Time
+	Page     int
+	PageSize int
+}
+
+// JSONText is a JSON document stored as text and emitted as-is in responses.
+type JSONText string
+
+func (j JSONText) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CatalogQuiz is a published quiz as listed to students.
+type CatalogQuiz struct {
+	ID              uuid.UUID `json:"id"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description"`
+	CategoryID      uint      `json:"category_id"`
+	Category        string    `json:"category"`
+	CategorySlug    string    `json:"category_slug"`
+	Difficulty      string    `json:"difficulty"`
+	DurationMinutes int       `json:"duration_minutes"`
+	QuestionCount   int       `json:"question_count"`
+	Adaptive        bool      `json:"adaptive"`
+	Tags            []Tag     `json:"tags"`
+}
+
+// CatalogQuizDetail is a published quiz with its questions, leaving out
+// the correct answers, explanations and rubrics.
+type CatalogQuizDetail struct {
+	CatalogQuiz
+	Questions []CatalogQuestion `json:"questions"`
+}
+
+type CatalogQuestion struct {
+	ID          uuid.UUID       `json:"id"`
+	Position    int             `json:"position"`
+	Type        string          `json:"type"`
+	Text        string          `json:"text"`
+	TextHTML    string          `json:"text_html"`
+	Options     []AttemptOption `json:"options"`
+	Attachments []Attachment    `json:"attachments,omitempty"`
+}
+
+// CatalogFilter narrows the catalog like QuizFilter does the quiz listing,
+// and optionally to one difficulty.
+type CatalogFilter struct {
+	QuizFilter
+	Difficulty string
+	Page       int
+	PageSize   int
+}
+
+// AttemptSummary is one of a student's attempts in their history. Score is
+// nil until the quiz releases it.
+type AttemptSummary struct {
+	ID             uuid.UUID  `json:"id"`
+	QuizID         uuid.UUID  `json:"quiz_id"`
+	QuizTitle      string     `json:"quiz_title"`
+	AssignmentID   *uint      `json:"assignment_id"`
+	Practice       bool       `json:"practice"`
+	Adaptive       bool       `json:"adaptive"`
+	Finished       bool       `json:"finished"`
+	GradingPending bool       `json:"grading_pending"`
+	Late           bool       `json:"late"`
+	Score          *int       `json:"score"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// AttemptFilter narrows a student's attempt history, optionally to a quiz.
+type AttemptFilter struct {
+	QuizID   *uuid.UUID
+	Page     int
+	PageSize int
+}
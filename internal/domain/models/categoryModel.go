@@ -6,15 +6,32 @@ import (
 	"gorm.io/gorm"
 )
 
+// Category is a node in the category tree. Slug is unique across all
+// categories, including trashed ones, and identifies it in URLs.
 type Category struct {
-	ID   uint   `gorm:"not null" json:"id"`
-	Name string `gorm:"not null" json:"name"`
+	ID        uint           `gorm:"not null" json:"id"`
+	ParentID  *uint          `gorm:"index" json:"parent_id"`
+	Name      string         `gorm:"not null" json:"name"`
+	Slug      string         `gorm:"type:varchar(255)" json:"slug"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
 
 type CategoryList struct {
-	ID   uint   `gorm:"not null" json:"id"`
-	Name string `gorm:"not null" json:"name"`
-}
\ No newline at end of file
+	ID       uint   `gorm:"not null" json:"id"`
+	ParentID *uint  `json:"parent_id"`
+	Name     string `gorm:"not null" json:"name"`
+	Slug     string `json:"slug"`
+}
+
+// MoveCategoryRequest moves a category under ParentID, or to the top level
+// when it is null.
+type MoveCategoryRequest struct {
+	ParentID *uint `json:"parent_id"`
+}
+
+// MergeCategoryRequest merges a category into TargetID.
+type MergeCategoryRequest struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Classroom groups students under a teacher. Students join with JoinCode or
+// are enrolled by the teacher.
+type Classroom struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Name        string         `gorm:"type:varchar(100);not null" json:"name"`
+	Description string         `gorm:"type:text" json:"description"`
+	TeacherID   uint           `gorm:"not null;index" json:"teacher_id"`
+	JoinCode    string         `gorm:"type:varchar(16);not null;uniqueIndex" json:"join_code"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type ClassroomList struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	TeacherID    uint   `json:"teacher_id"`
+	StudentCount int64  `json:"student_count"`
+}
+
+// ClassroomMember is a student enrolled in a classroom.
+type ClassroomMember struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ClassroomID uint      `gorm:"not null;uniqueIndex:idx_classroom_member" json:"classroom_id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_classroom_member;index" json:"user_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ClassroomQuiz assigns a quiz to a classroom.
+type ClassroomQuiz struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ClassroomID uint      `gorm:"not null;uniqueIndex:idx_classroom_quiz" json:"classroom_id"`
+	QuizID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_classroom_quiz;index" json:"quiz_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RosterEntry struct {
+	UserID     uint      `json:"user_id"`
+	Username   string    `json:"username"`
+	Email      string    `json:"email"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+}
+
+// ClassroomResult is one student's attempt at a quiz assigned to the classroom.
+type ClassroomResult struct {
+	ParticipantID uuid.UUID `json:"participant_id"`
+	UserID        uint      `json:"user_id"`
+	Username      string    `json:"username"`
+	QuizID        uuid.UUID `json:"quiz_id"`
+	QuizTitle     string    `json:"quiz_title"`
+	Score         int       `json:"score"`
+	Finished      bool      `json:"finished"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type ClassroomRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+}
+
+type JoinClassroomRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// EnrollRequest enrolls students identified by username or email.
+type EnrollRequest struct {
+	Students []string `json:"students" binding:"required,min=1,dive,required"`
+}
+
+type AssignQuizRequest struct {
+	QuizID uuid.UUID `json:"quiz_id" binding:"required"`
+}
+
+// EnrollmentResult reports the outcome of a bulk enrollment.
+type EnrollmentResult struct {
+	Enrolled []RosterEntry    `json:"enrolled"`
+	Skipped  []EnrollmentSkip `json:"skipped"`
+}
+
+type EnrollmentSkip struct {
+	Line   int    `json:"line,omitempty"`
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
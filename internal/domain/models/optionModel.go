@@ -1,17 +1,42 @@
 package models
 
 import (
+	"github.com/Arasy41/go-gin-quiz-api/pkg/richtext"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type Option struct {
-	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
-	QuestionID uuid.UUID `json:"question_id"`
+	ID         uuid.UUID `gorm:"type:uuid" json:"id"`
+	QuestionID uuid.UUID `gorm:"index" json:"question_id"`
+	Position   int       `json:"position"`
 	Text       string    `json:"text"`
+	TextHTML   string    `gorm:"-" json:"text_html"`
+	// Explanation says why this option is right or wrong.
+	Explanation     string `gorm:"type:text" json:"explanation,omitempty"`
+	ExplanationHTML string `gorm:"-" json:"explanation_html,omitempty"`
 }
 
 func (option *Option) BeforeCreate(tx *gorm.DB) (err error) {
-	option.ID = uuid.New()
+	if option.ID == uuid.Nil {
+		option.ID = uuid.New()
+	}
 	return nil
 }
+
+func (option *Option) AfterFind(tx *gorm.DB) (err error) {
+	option.RenderHTML()
+	return nil
+}
+
+// RenderHTML renders the option's rich text, like Question.RenderHTML.
+func (option *Option) RenderHTML() {
+	option.TextHTML = richtext.Render(option.Text)
+	option.ExplanationHTML = richtext.Render(option.Explanation)
+}
+
+type OptionRequest struct {
+	Text        string `json:"text" binding:"required"`
+	Correct     bool   `json:"correct"`
+	Explanation string `json:"explanation"`
+}
@@ -8,16 +8,158 @@ import (
 )
 
 type Participant struct {
-    ID       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-    QuizID   uuid.UUID `json:"quiz_id"`
-    UserID   uint      `json:"user_id"`
-    Score    int       `json:"score"`
-    Finished bool      `json:"finished"`
-    CreatedAt time.Time `json:"created_at"`
-    UpdatedAt time.Time `json:"updated_at"`
+	ID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	QuizID uuid.UUID `json:"quiz_id"`
+	UserID uint      `gorm:"index" json:"user_id"`
+	// QuizVersion is the version of the quiz the attempt is shown and
+	// scored against, until a regrade moves it to the latest one.
+	QuizVersion int  `gorm:"not null;default:0" json:"quiz_version"`
+	Score       int  `json:"score"`
+	Finished    bool `json:"finished"`
+	// Anonymized is set when the owning account was deleted; UserID is then 0
+	// and the attempt only counts towards aggregate statistics.
+	Anonymized bool `json:"anonymized"`
+	// AssignmentID is set for attempts made through an assignment.
+	AssignmentID *uint `gorm:"index" json:"assignment_id"`
+	// Practice attempts give feedback after every answer, are not graded and
+	// are left out of results. They cover only QuestionIDs, which are the
+	// quiz's multiple choice questions or, on a retry, the ones missed.
+	Practice    bool        `gorm:"index" json:"practice"`
+	QuestionIDs []uuid.UUID `gorm:"-" json:"question_ids,omitempty"`
+	// Adaptive attempts serve CurrentQuestionID next and track the ability
+	// estimate after each answer; CurrentQuestionID is nil once the test
+	// has stopped. Score is then the ability as a percentage.
+	Adaptive          bool       `json:"adaptive"`
+	CurrentQuestionID *uuid.UUID `gorm:"type:uuid" json:"current_question_id"`
+	Ability           *float64   `json:"ability"`
+	AbilitySE         *float64   `json:"ability_se"`
+	// RawScore is the percentage of questions answered correctly; Score is
+	// RawScore minus any late penalty. Both are final only once
+	// GradingPending is false, i.e. every essay answer has been graded.
+	RawScore       int        `json:"raw_score"`
+	GradingPending bool       `json:"grading_pending"`
+	Late           bool       `json:"late"`
+	Penalty        int        `json:"penalty"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// AttemptView is an attempt as shown to the student taking it; correct
+// answers are left out. Score, RawScore and the ability shadow the
+// participant's and are nil until the quiz releases the score.
+type AttemptView struct {
+	Participant
+	Score     *int              `json:"score"`
+	RawScore  *int              `json:"raw_score"`
+	Ability   *float64          `json:"ability"`
+	AbilitySE *float64          `json:"ability_se"`
+	QuizTitle string            `json:"quiz_title"`
+	Questions []AttemptQuestion `json:"questions"`
+}
+
+// ResultRelease tells which parts of a finished attempt's results the
+// student may see. ResultsAt is when the parts released after close become
+// visible, while that is still ahead.
+type ResultRelease struct {
+	Score     bool       `json:"score"`
+	Mistakes  bool       `json:"mistakes"`
+	Answers   bool       `json:"answers"`
+	ResultsAt *time.Time `json:"results_at,omitempty"`
+}
+
+// AttemptReview is a finished attempt as the student may review it under
+// the quiz's release policies; parts not released yet are left out.
+type AttemptReview struct {
+	AttemptID      uuid.UUID        `json:"attempt_id"`
+	QuizID         uuid.UUID        `json:"quiz_id"`
+	QuizTitle      string           `json:"quiz_title"`
+	AssignmentID   *uint            `json:"assignment_id"`
+	FinishedAt     *time.Time       `json:"finished_at"`
+	Late           bool             `json:"late"`
+	Penalty        int              `json:"penalty"`
+	GradingPending bool             `json:"grading_pending"`
+	Score          *int             `json:"score"`
+	RawScore       *int             `json:"raw_score"`
+	Ability        *float64         `json:"ability,omitempty"`
+	AbilitySE      *float64         `json:"ability_se,omitempty"`
+	Release        ResultRelease    `json:"release"`
+	Questions      []ReviewQuestion `json:"questions"`
+}
+
+// ReviewQuestion is a question with the student's answer. Correct, Points
+// and Comment are set once mistakes are released, CorrectOptionID and the
+// explanations once answers are.
+type ReviewQuestion struct {
+	ID               uuid.UUID       `json:"id"`
+	Position         int             `json:"position"`
+	Type             string          `json:"type"`
+	Text             string          `json:"text"`
+	TextHTML         string          `json:"text_html"`
+	Options          []AttemptOption `json:"options"`
+	SelectedOptionID *uuid.UUID      `json:"selected_option_id"`
+	AnswerText       string          `json:"answer_text,omitempty"`
+	Correct          *bool           `json:"correct"`
+	Points           *int            `json:"points,omitempty"`
+	MaxPoints        *int            `json:"max_points,omitempty"`
+	Comment          string          `json:"comment,omitempty"`
+	CorrectOptionID  *uuid.UUID      `json:"correct_option_id,omitempty"`
+	Explanation      string          `json:"explanation,omitempty"`
+	ExplanationHTML  string          `json:"explanation_html,omitempty"`
+	Attachments      []Attachment    `json:"attachments,omitempty"`
+}
+
+type AttemptQuestion struct {
+	ID               uuid.UUID       `json:"id"`
+	Position         int             `json:"position"`
+	Type             string          `json:"type"`
+	Text             string          `json:"text"`
+	TextHTML         string          `json:"text_html"`
+	Options          []AttemptOption `json:"options"`
+	SelectedOptionID *uuid.UUID      `json:"selected_option_id"`
+	AnswerText       string          `json:"answer_text,omitempty"`
+	Attachments      []Attachment    `json:"attachments,omitempty"`
+}
+
+type AttemptOption struct {
+	ID              uuid.UUID `json:"id"`
+	Position        int       `json:"position"`
+	Text            string    `json:"text"`
+	TextHTML        string    `json:"text_html"`
+	Explanation     string    `json:"explanation,omitempty"`
+	ExplanationHTML string    `json:"explanation_html,omitempty"`
+}
+
+// PracticeQuestion is one of the questions a practice attempt covers.
+type PracticeQuestion struct {
+	ParticipantID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	QuestionID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Position      int
+}
+
+// AnswerFeedback is returned right after answering in practice mode.
+type AnswerFeedback struct {
+	QuestionID            uuid.UUID `json:"question_id"`
+	Correct               bool      `json:"correct"`
+	CorrectOptionID       uuid.UUID `json:"correct_option_id"`
+	Explanation           string    `json:"explanation,omitempty"`
+	ExplanationHTML       string    `json:"explanation_html,omitempty"`
+	OptionExplanation     string    `json:"option_explanation,omitempty"`
+	OptionExplanationHTML string    `json:"option_explanation_html,omitempty"`
+}
+
+// AnswerRequest answers one question: OptionID for multiple choice, Text
+// for an essay.
+type AnswerRequest struct {
+	QuestionID uuid.UUID `json:"question_id" binding:"required"`
+	OptionID   uuid.UUID `json:"option_id"`
+	Text       string    `json:"text" binding:"max=20000"`
 }
 
 func (participant *Participant) BeforeCreate(tx *gorm.DB) (err error) {
-    participant.ID = uuid.New()
-    return nil
-}
\ No newline at end of file
+	if participant.ID == uuid.Nil {
+		participant.ID = uuid.New()
+	}
+	return nil
+}
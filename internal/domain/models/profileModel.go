@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+type Profile struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"not null;uniqueIndex" json:"user_id"`
+	DisplayName string    `gorm:"type:varchar(100)" json:"display_name"`
+	AvatarURL   string    `gorm:"type:varchar(500)" json:"avatar_url"`
+	Bio         string    `gorm:"type:text" json:"bio"`
+	Locale      string    `gorm:"type:varchar(35)" json:"locale"`
+	Timezone    string    `gorm:"type:varchar(64)" json:"timezone"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,max=100"`
+	AvatarURL   *string `json:"avatar_url" binding:"omitempty,max=500"`
+	Bio         *string `json:"bio" binding:"omitempty,max=500"`
+	Locale      *string `json:"locale" binding:"omitempty,max=35"`
+	Timezone    *string `json:"timezone" binding:"omitempty,max=64"`
+}
+
+// EmailChange is a pending email address change, applied once the owner
+// follows the link sent to the new address. Only a hash of the token is kept.
+type EmailChange struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	NewEmail  string    `gorm:"not null" json:"new_email"`
+	TokenHash string    `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ChangeEmailRequest struct {
+	Password string `json:"password" binding:"required"`
+	NewEmail string `json:"new_email" binding:"required,email"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
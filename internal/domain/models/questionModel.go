@@ -1,19 +1,101 @@
 package models
 
 import (
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/pkg/richtext"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type Question struct {
-    ID       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
-    QuizID   uuid.UUID `json:"quiz_id"`
-    Text     string    `json:"text"`
-    Options  []Option  `json:"options"`
-    AnswerID uuid.UUID `json:"answer_id"`  // ID jawaban yang benar
+	ID       uuid.UUID `gorm:"type:uuid" json:"id"`
+	QuizID   uuid.UUID `gorm:"index" json:"quiz_id"`
+	Position int       `json:"position"`
+	// Type is constant.QuestionMultipleChoice or constant.QuestionEssay.
+	// Essay answers are scored by a teacher against the Rubric.
+	Type string `gorm:"type:varchar(32);not null;default:'multiple_choice'" json:"type"`
+	// Text and Explanation are Markdown with TeX math; TextHTML and
+	// ExplanationHTML are their sanitized HTML, rendered when loaded.
+	Text       string `json:"text"`
+	TextHTML   string `gorm:"-" json:"text_html"`
+	Difficulty string `gorm:"type:varchar(16);not null;default:'medium'" json:"difficulty"`
+	// Explanation is shown to students after they answer in practice mode
+	// and when reviewing an attempt whose answers are released.
+	Explanation     string            `gorm:"type:text" json:"explanation,omitempty"`
+	ExplanationHTML string            `gorm:"-" json:"explanation_html,omitempty"`
+	Options         []Option          `json:"options"`
+	Rubric          []RubricCriterion `json:"rubric,omitempty"`
+	Tags            []Tag             `gorm:"many2many:question_tags" json:"tags"`
+	Attachments     []Attachment      `gorm:"foreignKey:QuestionID" json:"attachments,omitempty"`
+	AnswerID        uuid.UUID         `json:"answer_id"` // ID jawaban yang benar
+	// IRT parameters (2PL) used by adaptive quizzes, estimated from past
+	// answers by calibration.
+	IRTDiscrimination float64    `gorm:"not null;default:1" json:"irt_discrimination"`
+	IRTDifficulty     float64    `gorm:"not null;default:0" json:"irt_difficulty"`
+	IRTResponses      int        `json:"irt_responses"`
+	CalibratedAt      *time.Time `json:"calibrated_at"`
+	// SuccessRate and SuggestedDifficulty are refreshed by calibration, like
+	// the quiz's.
+	SuccessRate         *float64 `json:"success_rate"`
+	SuggestedDifficulty string   `gorm:"type:varchar(16)" json:"suggested_difficulty,omitempty"`
 }
 
 func (question *Question) BeforeCreate(tx *gorm.DB) (err error) {
-    question.ID = uuid.New()
-    return nil
-}
\ No newline at end of file
+	if question.ID == uuid.Nil {
+		question.ID = uuid.New()
+	}
+	return nil
+}
+
+func (question *Question) AfterFind(tx *gorm.DB) (err error) {
+	question.RenderHTML()
+	return nil
+}
+
+// RenderHTML renders the question's and its options' rich text.
+func (question *Question) RenderHTML() {
+	question.TextHTML = richtext.Render(question.Text)
+	question.ExplanationHTML = richtext.Render(question.Explanation)
+	for i := range question.Options {
+		question.Options[i].RenderHTML()
+	}
+}
+
+// RubricCriterion is one line of an essay question's marking scheme.
+type RubricCriterion struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	QuestionID  uuid.UUID `gorm:"type:uuid;index" json:"question_id"`
+	Position    int       `json:"position"`
+	Description string    `gorm:"type:text;not null" json:"description"`
+	Points      int       `gorm:"not null" json:"points"`
+}
+
+func (criterion *RubricCriterion) BeforeCreate(tx *gorm.DB) (err error) {
+	if criterion.ID == uuid.Nil {
+		criterion.ID = uuid.New()
+	}
+	return nil
+}
+
+type QuestionRequest struct {
+	Type        string             `json:"type" binding:"omitempty,oneof=multiple_choice essay"`
+	Text        string             `json:"text" binding:"required"`
+	Difficulty  string             `json:"difficulty" binding:"omitempty,oneof=easy medium hard"`
+	Explanation string             `json:"explanation"`
+	Options     []OptionRequest    `json:"options" binding:"omitempty,dive"`
+	Rubric      []CriterionRequest `json:"rubric" binding:"omitempty,dive"`
+	Tags        []string           `json:"tags" binding:"omitempty,max=20"`
+}
+
+type CriterionRequest struct {
+	Description string `json:"description" binding:"required"`
+	Points      int    `json:"points" binding:"required,min=1"`
+}
+
+// CalibrationAnswer is one past answer used to calibrate a quiz's questions.
+type CalibrationAnswer struct {
+	ParticipantID uuid.UUID
+	QuestionID    uuid.UUID
+	Correct       bool
+}
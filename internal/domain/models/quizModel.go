@@ -8,27 +8,129 @@ import (
 )
 
 type Quiz struct {
-	ID          uuid.UUID     `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	ID          uuid.UUID     `gorm:"type:uuid;primaryKey" json:"id"`
 	Title       string        `gorm:"type:varchar(255);not null" json:"title"`
 	Description string        `gorm:"type:text" json:"description"`
 	CategoryID  uint          `gorm:"not null" json:"category_id"`
-	Difficulty  string        `gorm:"not null" json:"difficulty"`
+	Difficulty  string        `gorm:"type:varchar(16);not null" json:"difficulty"`
 	Duration    time.Duration `gorm:"not null" json:"duration"`
-	CreatedAt   time.Time     `json:"created_at"`
-	UpdatedAt   time.Time     `json:"updated_at"`
-	Category    Category      `gorm:"foreignKey:CategoryID;references:ID" json:"category,omitempty"`
-	Questions   []Question    `gorm:"foreignKey:QuizID" json:"questions"`
+	// OwnerID is the teacher who created the quiz, or was handed it, and
+	// may share it with other teachers as a QuizShare. It is nil only when
+	// no admin was left to take the quiz over; only admins can edit those.
+	OwnerID *uint `gorm:"index" json:"owner_id"`
+	// Status is draft, in_review, published or archived; students only see
+	// published quizzes. Edits to a published quiz go to its draft revision,
+	// a quiz of its own with RevisionOf set, which replaces the published
+	// content once it is published itself; the questions only when
+	// QuestionsChanged.
+	Status           string     `gorm:"type:varchar(16);not null;default:'draft';index" json:"status"`
+	RevisionOf       *uuid.UUID `gorm:"type:uuid;index" json:"revision_of"`
+	QuestionsChanged bool       `json:"questions_changed"`
+	// SubmittedBy asked for the review that ReviewedBy approved or rejected,
+	// with ReviewComment.
+	SubmittedBy   *uint      `json:"submitted_by"`
+	ReviewedBy    *uint      `json:"reviewed_by"`
+	ReviewComment string     `gorm:"type:text" json:"review_comment,omitempty"`
+	PublishedAt   *time.Time `json:"published_at"`
+	// Version is the number of the quiz's latest QuizVersion, 0 until it is
+	// first published.
+	Version int `gorm:"not null;default:0" json:"version"`
+	// SuccessRate is the share of correct answers in finished attempts, and
+	// SuggestedDifficulty the level it points at; both are refreshed by
+	// calibration once enough attempts are in.
+	SuccessRate         *float64 `json:"success_rate"`
+	SuggestedDifficulty string   `gorm:"type:varchar(16)" json:"suggested_difficulty,omitempty"`
+	// The release policies decide when students reviewing a finished
+	// attempt see their score, which questions they got wrong, and the
+	// correct answers: immediately, after_close or never.
+	ReleaseScore    string `gorm:"type:varchar(16);not null;default:'immediately'" json:"release_score"`
+	ReleaseMistakes string `gorm:"type:varchar(16);not null;default:'immediately'" json:"release_mistakes"`
+	ReleaseAnswers  string `gorm:"type:varchar(16);not null;default:'after_close'" json:"release_answers"`
+	// Adaptive quizzes serve one multiple choice question at a time, picked
+	// for the participant's current ability estimate, until its standard
+	// error is at most AdaptiveMaxSE or AdaptiveMaxItems were asked.
+	Adaptive         bool       `json:"adaptive"`
+	AdaptiveMaxSE    float64    `json:"adaptive_max_se"`
+	AdaptiveMaxItems int        `json:"adaptive_max_items"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	Category         Category   `gorm:"foreignKey:CategoryID;references:ID" json:"category,omitempty"`
+	Tags             []Tag      `gorm:"many2many:quiz_tags" json:"tags"`
+	Questions        []Question `gorm:"foreignKey:QuizID" json:"questions"`
 }
 
 type QuizList struct {
-	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
 	Title       string    `gorm:"type:varchar(255);not null" json:"title"`
 	Description string    `gorm:"type:text" json:"description"`
-	Category    string    `gorm:"not null" json:"category_id"`
+	Category    string    `gorm:"not null" json:"category"`
 	Difficulty  string    `gorm:"not null" json:"difficulty"`
+	Status      string    `json:"status"`
+	OwnerID     *uint     `json:"owner_id"`
+	Tags        []Tag     `gorm:"-" json:"tags"`
+}
+
+// QuizFilter narrows a quiz listing. CategoryID or CategorySlug selects a
+// category together with all its subcategories, resolved into CategoryIDs.
+// With MatchAllTags a quiz needs every tag, otherwise any of them.
+// Status limits the teachers' listing to quizzes in that status, and
+// ViewerID, unless 0, to the quizzes that teacher may see.
+type QuizFilter struct {
+	CategoryID   uint
+	CategorySlug string
+	CategoryIDs  []uint
+	Tags         []string
+	MatchAllTags bool
+	Status       string
+	ViewerID     uint
+}
+
+// DifficultySuggestion is a quiz, or one of its questions when QuestionID
+// is set, whose success rate points at another difficulty than its own.
+type DifficultySuggestion struct {
+	QuizID              uuid.UUID  `json:"quiz_id"`
+	QuizTitle           string     `json:"quiz_title"`
+	QuestionID          *uuid.UUID `json:"question_id,omitempty"`
+	Position            int        `json:"position,omitempty"`
+	Text                string     `json:"text,omitempty"`
+	Difficulty          string     `json:"difficulty"`
+	SuggestedDifficulty string     `json:"suggested_difficulty"`
+	SuccessRate         float64    `json:"success_rate"`
+}
+
+// QuizRequest creates or updates a quiz. On update, Questions replaces the
+// existing questions when present and leaves them untouched when omitted;
+// likewise, omitted release policies and tags keep their current value.
+// Questions without a difficulty take the quiz's.
+type QuizRequest struct {
+	Title            string            `json:"title" binding:"required,max=255"`
+	Description      string            `json:"description"`
+	CategoryID       uint              `json:"category_id" binding:"required"`
+	Difficulty       string            `json:"difficulty" binding:"required,oneof=easy medium hard"`
+	DurationMinutes  int               `json:"duration_minutes" binding:"required,min=1"`
+	ReleaseScore     string            `json:"release_score" binding:"omitempty,oneof=immediately after_close never"`
+	ReleaseMistakes  string            `json:"release_mistakes" binding:"omitempty,oneof=immediately after_close never"`
+	ReleaseAnswers   string            `json:"release_answers" binding:"omitempty,oneof=immediately after_close never"`
+	Adaptive         bool              `json:"adaptive"`
+	AdaptiveMaxSE    float64           `json:"adaptive_max_se" binding:"omitempty,gt=0,lte=2"`
+	AdaptiveMaxItems int               `json:"adaptive_max_items" binding:"min=0"`
+	Tags             []string          `json:"tags" binding:"omitempty,max=20"`
+	Questions        []QuestionRequest `json:"questions" binding:"omitempty,dive"`
+}
+
+// ApproveQuizRequest approves a quiz in review, optionally with a comment.
+type ApproveQuizRequest struct {
+	Comment string `json:"comment" binding:"max=2000"`
+}
+
+// RejectQuizRequest sends a quiz in review back to draft, saying why.
+type RejectQuizRequest struct {
+	Comment string `json:"comment" binding:"required,max=2000"`
 }
 
 func (quiz *Quiz) BeforeCreate(tx *gorm.DB) (err error) {
-	quiz.ID = uuid.New()
+	if quiz.ID == uuid.Nil {
+		quiz.ID = uuid.New()
+	}
 	return nil
 }
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuizShare gives a teacher other than the owner access to a quiz and its
+// draft revision. Role is constant.QuizRoleEditor or constant.QuizRoleViewer.
+type QuizShare struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	QuizID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_quiz_share" json:"quiz_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_quiz_share;index" json:"user_id"`
+	Role      string    `gorm:"type:varchar(16);not null" json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// QuizAccess is what a teacher may do with a quiz: Role is their own role
+// on it, or "" without one.
+type QuizAccess struct {
+	QuizID     uuid.UUID
+	OwnerID    *uint
+	Status     string
+	RevisionOf *uuid.UUID
+	Role       string
+}
+
+// Collaborator is the owner of a quiz or a teacher it is shared with.
+type Collaborator struct {
+	UserID   uint       `json:"user_id"`
+	Username string     `json:"username"`
+	Email    string     `json:"email"`
+	Role     string     `json:"role"`
+	SharedAt *time.Time `json:"shared_at,omitempty"`
+}
+
+// ShareQuizRequest shares a quiz with the teacher identified by username
+// or email, or changes their role on it.
+type ShareQuizRequest struct {
+	User string `json:"user" binding:"required"`
+	Role string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// TransferQuizRequest hands a quiz to the teacher identified by username
+// or email.
+type TransferQuizRequest struct {
+	User string `json:"user" binding:"required"`
+}
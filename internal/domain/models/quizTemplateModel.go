@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuizTemplate is a quiz in the template library: a snapshot of a quiz's
+// content, questions and answer key, taken when an admin adds it, from which
+// teachers create new draft quizzes. Later edits to the source quiz don't
+// reach the template until an admin takes the snapshot again.
+type QuizTemplate struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Name         string     `gorm:"type:varchar(255);not null" json:"name"`
+	Description  string     `gorm:"type:text" json:"description"`
+	CategoryID   uint       `gorm:"index" json:"category_id"`
+	Difficulty   string     `gorm:"type:varchar(16);not null" json:"difficulty"`
+	Questions    int        `json:"questions"`
+	SourceQuizID *uuid.UUID `gorm:"type:uuid;index" json:"source_quiz_id"`
+	CreatedBy    uint       `json:"created_by"`
+	Content      string     `gorm:"type:text;not null" json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// SetQuiz takes the quiz as the template's content.
+func (t *QuizTemplate) SetQuiz(quiz *Quiz) error {
+	content, err := quiz.content()
+	if err != nil {
+		return err
+	}
+	t.Content = content
+	t.CategoryID = quiz.CategoryID
+	t.Difficulty = quiz.Difficulty
+	t.Questions = len(quiz.Questions)
+	t.SourceQuizID = &quiz.ID
+	return nil
+}
+
+// Quiz returns the quiz the template was taken from, as it was then, but
+// in the template's category, which follows category merges.
+func (t *QuizTemplate) Quiz() (*Quiz, error) {
+	quiz, err := contentQuiz(t.Content)
+	if err != nil {
+		return nil, err
+	}
+	quiz.CategoryID = t.CategoryID
+	return quiz, nil
+}
+
+// QuizTemplateRequest adds the quiz QuizID to the template library, named
+// Name or, when empty, after the quiz.
+type QuizTemplateRequest struct {
+	QuizID      uuid.UUID `json:"quiz_id" binding:"required"`
+	Name        string    `json:"name" binding:"max=255"`
+	Description string    `json:"description"`
+}
+
+// UpdateQuizTemplateRequest renames a template and, with QuizID, takes its
+// content from that quiz again.
+type UpdateQuizTemplateRequest struct {
+	Name        string     `json:"name" binding:"required,max=255"`
+	Description string     `json:"description"`
+	QuizID      *uuid.UUID `json:"quiz_id"`
+}
+
+// CopyQuizRequest names the draft created by duplicating a quiz or from a
+// template. Empty fields keep the source's title and category.
+type CopyQuizRequest struct {
+	Title      string `json:"title" binding:"max=255"`
+	CategoryID uint   `json:"category_id"`
+}
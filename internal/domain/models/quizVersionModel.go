@@ -0,0 +1,112 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuizVersion is an immutable snapshot of a quiz, taken each time it is
+// published and each time its answer key is corrected. Attempts are pinned
+// to the version current when they started.
+type QuizVersion struct {
+	ID      uint      `gorm:"primaryKey" json:"id"`
+	QuizID  uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_quiz_version" json:"quiz_id"`
+	Version int       `gorm:"uniqueIndex:idx_quiz_version" json:"version"`
+	// Reason is constant.VersionPublished or constant.VersionAnswerKey.
+	Reason    string    `gorm:"type:varchar(16);not null" json:"reason"`
+	Title     string    `gorm:"type:varchar(255);not null" json:"title"`
+	Content   string    `gorm:"type:text;not null" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Snapshot returns the quiz as version number version, with its questions,
+// options, answer key, rubrics and attachments as they are now.
+func (quiz *Quiz) Snapshot(version int, reason string) (*QuizVersion, error) {
+	content, err := quiz.content()
+	if err != nil {
+		return nil, err
+	}
+	return &QuizVersion{
+		QuizID:  quiz.ID,
+		Version: version,
+		Reason:  reason,
+		Title:   quiz.Title,
+		Content: content,
+	}, nil
+}
+
+// Quiz returns the quiz as it was in this version.
+func (v *QuizVersion) Quiz() (*Quiz, error) {
+	return contentQuiz(v.Content)
+}
+
+// content encodes the quiz with its questions for a snapshot. Signed
+// attachment URLs are left out since they expire.
+func (quiz *Quiz) content() (string, error) {
+	snapshot := *quiz
+	snapshot.Questions = make([]Question, len(quiz.Questions))
+	for i, q := range quiz.Questions {
+		q.Attachments = append([]Attachment{}, q.Attachments...)
+		for j := range q.Attachments {
+			q.Attachments[j].URL, q.Attachments[j].ThumbnailURL = "", ""
+		}
+		snapshot.Questions[i] = q
+	}
+	content, err := json.Marshal(&snapshot)
+	return string(content), err
+}
+
+// contentQuiz decodes a snapshot, rendering its rich text again.
+func contentQuiz(content string) (*Quiz, error) {
+	quiz := &Quiz{}
+	if err := json.Unmarshal([]byte(content), quiz); err != nil {
+		return nil, err
+	}
+	for i := range quiz.Questions {
+		quiz.Questions[i].RenderHTML()
+	}
+	return quiz, nil
+}
+
+// QuizDiff lists what changed between two versions of a quiz. Questions
+// are matched by position.
+type QuizDiff struct {
+	QuizID    uuid.UUID      `json:"quiz_id"`
+	From      int            `json:"from"`
+	To        int            `json:"to"`
+	Changes   []FieldChange  `json:"changes"`
+	Questions []QuestionDiff `json:"questions"`
+}
+
+// FieldChange is one field with different values in two versions.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// QuestionDiff is a question added, removed or changed at Position.
+type QuestionDiff struct {
+	Position int           `json:"position"`
+	Change   string        `json:"change"`
+	Changes  []FieldChange `json:"changes,omitempty"`
+}
+
+// AnswerKeyRequest corrects which option of a question is the right one.
+type AnswerKeyRequest struct {
+	OptionID uuid.UUID `json:"option_id" binding:"required"`
+}
+
+// RegradeResult tells how many attempts a regrade brought up to Version,
+// and in how many of them answers and scores changed. Kept counts the
+// attempts left on an older version whose questions were replaced since.
+type RegradeResult struct {
+	QuizID         uuid.UUID `json:"quiz_id"`
+	Version        int       `json:"version"`
+	Attempts       int       `json:"attempts"`
+	AnswersChanged int       `json:"answers_changed"`
+	ScoresChanged  int       `json:"scores_changed"`
+	Kept           int       `json:"kept"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewCard schedules a question the user got wrong for spaced repetition
+// with the SM-2 algorithm. Missing the question again in a later attempt
+// puts the card back at the start.
+type ReviewCard struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"uniqueIndex:idx_review_card_user_question" json:"user_id"`
+	QuestionID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_review_card_user_question" json:"question_id"`
+	QuizID     uuid.UUID `gorm:"type:uuid;index" json:"quiz_id"`
+	// AttemptID is the latest attempt in which the question was missed.
+	AttemptID      uuid.UUID  `gorm:"type:uuid" json:"attempt_id"`
+	Repetitions    int        `json:"repetitions"`
+	IntervalDays   int        `json:"interval_days"`
+	EaseFactor     float64    `json:"ease_factor"`
+	DueAt          time.Time  `gorm:"index" json:"due_at"`
+	LastMissedAt   time.Time  `json:"last_missed_at"`
+	LastReviewedAt *time.Time `json:"last_reviewed_at"`
+	LastQuality    *int       `json:"last_quality"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// MissedAnswer is an incorrect answer not yet taken into the user's review
+// cards.
+type MissedAnswer struct {
+	ParticipantID uuid.UUID
+	QuestionID    uuid.UUID
+	QuizID        uuid.UUID
+	AnsweredAt    time.Time
+}
+
+// DueReview is a review card as shown to the student. The correct option
+// and explanation are only included once the quiz releases its answers.
+type DueReview struct {
+	CardID          uint            `json:"card_id"`
+	QuizID          uuid.UUID       `json:"quiz_id"`
+	QuizTitle       string          `json:"quiz_title"`
+	QuestionID      uuid.UUID       `json:"question_id"`
+	Text            string          `json:"text"`
+	TextHTML        string          `json:"text_html"`
+	Options         []AttemptOption `json:"options"`
+	CorrectOptionID *uuid.UUID      `json:"correct_option_id,omitempty"`
+	Explanation     string          `json:"explanation,omitempty"`
+	ExplanationHTML string          `json:"explanation_html,omitempty"`
+	Repetitions     int             `json:"repetitions"`
+	IntervalDays    int             `json:"interval_days"`
+	DueAt           time.Time       `json:"due_at"`
+}
+
+type RecallRequest struct {
+	Quality *int `json:"quality" binding:"required,min=0,max=5"`
+}
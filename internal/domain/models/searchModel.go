@@ -0,0 +1,30 @@
+package models
+
+import "github.com/google/uuid"
+
+// SearchDocument is the searchable text of a quiz (its title and
+// description) or of a question (its text and options), indexed by the
+// database's full-text engine.
+type SearchDocument struct {
+	ID       uint      `gorm:"primaryKey"`
+	Entity   string    `gorm:"type:varchar(16);not null;uniqueIndex:idx_search_entity"`
+	EntityID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_search_entity"`
+	QuizID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	Title    string    `gorm:"type:text"`
+	Body     string    `gorm:"type:text"`
+}
+
+// SearchHit is a quiz or question matching a search, best match first.
+// Highlight and Snippet are HTML with the matched words in <mark>; Snippet
+// is an excerpt of the description or options, when they matched.
+type SearchHit struct {
+	Type      string    `json:"type"`
+	ID        uuid.UUID `json:"id"`
+	QuizID    uuid.UUID `json:"quiz_id"`
+	QuizTitle string    `json:"quiz_title"`
+	Text      string    `json:"text"`
+	Body      string    `json:"-"`
+	Score     float64   `json:"score"`
+	Highlight string    `json:"highlight"`
+	Snippet   string    `json:"snippet,omitempty"`
+}
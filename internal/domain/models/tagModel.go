@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag is a free-form label on quizzes and questions. Names are stored
+// normalized: trimmed, lowercase, with single spaces.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"type:varchar(64);not null;uniqueIndex" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TagUsage is a tag suggested by autocomplete, with how often it is used.
+type TagUsage struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Quizzes   int64  `json:"quizzes"`
+	Questions int64  `json:"questions"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+// MergeTagRequest merges a tag into TargetID.
+type MergeTagRequest struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
+
+// QuestionFilter narrows the question bank listing. With MatchAllTags a
+// question needs every tag, otherwise any of them. ViewerID, unless 0,
+// leaves out the questions of quizzes that teacher may not see.
+type QuestionFilter struct {
+	QuizID       *uuid.UUID
+	Tags         []string
+	MatchAllTags bool
+	ViewerID     uint
+	Page         int
+	PageSize     int
+}
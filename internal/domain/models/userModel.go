@@ -6,13 +6,15 @@ import (
 	"gorm.io/gorm"
 )
 
+// User is the stored account. Password holds the bcrypt hash and is never
+// serialized; handlers respond with UserResponse or UserList instead.
 type User struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
 	Username  string         `gorm:"not null;unique" json:"username"`
 	Email     string         `gorm:"not null;unique" json:"email"`
-	Password  string         `gorm:"not null" json:"password"`
+	Password  string         `gorm:"not null" json:"-"`
 	RoleID    uint           `gorm:"not null" json:"role_id"`
-	Role      Role           `gorm:"foreignKey:RoleID;references:ID"`
+	Role      Role           `gorm:"foreignKey:RoleID;references:ID" json:"-"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
@@ -22,10 +24,53 @@ type UserList struct {
 	ID       uint   `json:"id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
-	Password string `json:"password"`
 	RoleName string `json:"role_name"`
 }
 
+// UserResponse is the public representation of a single user.
+type UserResponse struct {
+	ID        uint       `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	RoleID    uint       `json:"role_id"`
+	RoleName  string     `json:"role_name,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func NewUserResponse(user *User) UserResponse {
+	res := UserResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		RoleID:    user.RoleID,
+		RoleName:  user.Role.Name,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+	if user.DeletedAt.Valid {
+		res.DeletedAt = &user.DeletedAt.Time
+	}
+	return res
+}
+
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+	RoleID   uint   `json:"role_id" binding:"required"`
+}
+
+// UpdateUserRequest changes only the fields that are set; an empty password
+// keeps the current one.
+type UpdateUserRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email" binding:"omitempty,email"`
+	Password string `json:"password"`
+	RoleID   uint   `json:"role_id"`
+}
+
 type LoginRequest struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
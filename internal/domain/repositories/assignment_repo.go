@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type AssignmentRepository interface {
+	CreateAssignment(assignment *models.Assignment) (*models.Assignment, error)
+	UpdateAssignment(assignment *models.Assignment, replaceUsers bool) (*models.Assignment, error)
+	DeleteAssignment(assignment *models.Assignment) error
+	FindAssignmentByID(id uint) (*models.Assignment, error)
+	FindAssignmentsByCreator(userID uint) ([]models.Assignment, error)
+	FindAssignmentsForStudent(userID uint) ([]models.Assignment, error)
+	IsAssignedTo(assignment *models.Assignment, userID uint) (bool, error)
+	FindAssignees(assignment *models.Assignment) ([]models.User, error)
+}
+
+type assignmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAssignmentRepository(db *gorm.DB) AssignmentRepository {
+	return &assignmentRepository{db: db}
+}
+
+// CreateAssignment inserts the assignment with its target users. A
+// classroom assignment also makes the quiz part of the classroom, so its
+// attempts show up in the classroom results.
+func (r *assignmentRepository) CreateAssignment(assignment *models.Assignment) (*models.Assignment, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(assignment).Error; err != nil {
+			return err
+		}
+		return linkClassroomQuiz(tx, assignment)
+	})
+	return assignment, err
+}
+
+// UpdateAssignment saves the assignment's schedule and target. When
+// replaceUsers is set its target users are replaced by assignment.Users.
+func (r *assignmentRepository) UpdateAssignment(assignment *models.Assignment, replaceUsers bool) (*models.Assignment, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(assignment).
+			Select("quiz_id", "classroom_id", "title", "opens_at", "closes_at", "late_until", "late_penalty", "max_attempts", "scoring_policy", "updated_at").
+			Updates(assignment).Error
+		if err != nil {
+			return err
+		}
+
+		if replaceUsers {
+			if err := tx.Where("assignment_id = ?", assignment.ID).Delete(&models.AssignmentUser{}).Error; err != nil {
+				return err
+			}
+			for i := range assignment.Users {
+				assignment.Users[i].ID = 0
+				assignment.Users[i].AssignmentID = assignment.ID
+			}
+			if len(assignment.Users) > 0 {
+				if err := tx.Create(&assignment.Users).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return linkClassroomQuiz(tx, assignment)
+	})
+	return assignment, err
+}
+
+func (r *assignmentRepository) DeleteAssignment(assignment *models.Assignment) error {
+	return r.db.Delete(assignment).Error
+}
+
+func (r *assignmentRepository) FindAssignmentByID(id uint) (*models.Assignment, error) {
+	assignment := &models.Assignment{}
+	return assignment, r.db.Preload("Users").Where("id = ?", id).First(assignment).Error
+}
+
+func (r *assignmentRepository) FindAssignmentsByCreator(userID uint) ([]models.Assignment, error) {
+	query := r.db.Preload("Users").Order("closes_at IS NULL, closes_at, id")
+	if userID != 0 {
+		query = query.Where("created_by = ?", userID)
+	}
+	assignments := []models.Assignment{}
+	return assignments, query.Find(&assignments).Error
+}
+
+// FindAssignmentsForStudent returns the assignments targeting the student
+// directly or through a classroom they are enrolled in.
+func (r *assignmentRepository) FindAssignmentsForStudent(userID uint) ([]models.Assignment, error) {
+	classrooms := r.db.Model(&models.ClassroomMember{}).Select("classroom_id").Where("user_id = ?", userID)
+	direct := r.db.Model(&models.AssignmentUser{}).Select("assignment_id").Where("user_id = ?", userID)
+
+	assignments := []models.Assignment{}
+	err := r.db.
+		Where("classroom_id IN (?) OR id IN (?)", classrooms, direct).
+		Order("closes_at IS NULL, closes_at, id").
+		Find(&assignments).Error
+	return assignments, err
+}
+
+func (r *assignmentRepository) IsAssignedTo(assignment *models.Assignment, userID uint) (bool, error) {
+	var count int64
+	var err error
+	if assignment.ClassroomID != nil {
+		err = r.db.Model(&models.ClassroomMember{}).
+			Where("classroom_id = ? AND user_id = ?", *assignment.ClassroomID, userID).
+			Count(&count).Error
+	} else {
+		err = r.db.Model(&models.AssignmentUser{}).
+			Where("assignment_id = ? AND user_id = ?", assignment.ID, userID).
+			Count(&count).Error
+	}
+	return count > 0, err
+}
+
+// FindAssignees returns the active users the assignment targets.
+func (r *assignmentRepository) FindAssignees(assignment *models.Assignment) ([]models.User, error) {
+	var targets *gorm.DB
+	if assignment.ClassroomID != nil {
+		targets = r.db.Model(&models.ClassroomMember{}).Select("user_id").Where("classroom_id = ?", *assignment.ClassroomID)
+	} else {
+		targets = r.db.Model(&models.AssignmentUser{}).Select("user_id").Where("assignment_id = ?", assignment.ID)
+	}
+
+	users := []models.User{}
+	return users, r.db.Where("id IN (?)", targets).Order("username").Find(&users).Error
+}
+
+func linkClassroomQuiz(tx *gorm.DB, assignment *models.Assignment) error {
+	if assignment.ClassroomID == nil {
+		return nil
+	}
+	link := models.ClassroomQuiz{ClassroomID: *assignment.ClassroomID, QuizID: assignment.QuizID}
+	return tx.Where(link).FirstOrCreate(&link).Error
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository interface {
+	CreateAttachment(attachment *models.Attachment) error
+	FindAttachmentByID(id uuid.UUID) (*models.Attachment, error)
+	DeleteAttachment(attachment *models.Attachment) error
+	CountKeyReferences(key string) (int64, error)
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) CreateAttachment(attachment *models.Attachment) error {
+	return r.db.Create(attachment).Error
+}
+
+func (r *attachmentRepository) FindAttachmentByID(id uuid.UUID) (*models.Attachment, error) {
+	attachment := &models.Attachment{}
+	if err := r.db.Where("id = ?", id).First(attachment).Error; err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (r *attachmentRepository) DeleteAttachment(attachment *models.Attachment) error {
+	return r.db.Delete(attachment).Error
+}
+
+// CountKeyReferences counts the attachments, quiz versions and templates
+// that still use the stored file: copies of a question share their
+// attachments' files, and snapshots keep the keys of the attachments they
+// were taken with.
+func (r *attachmentRepository) CountKeyReferences(key string) (int64, error) {
+	var attachments, versions, templates int64
+	err := r.db.Model(&models.Attachment{}).Where("storage_key = ? OR thumbnail_key = ?", key, key).Count(&attachments).Error
+	if err != nil {
+		return 0, err
+	}
+	err = r.db.Model(&models.QuizVersion{}).Where("content LIKE ?", "%"+key+"%").Count(&versions).Error
+	if err != nil {
+		return 0, err
+	}
+	err = r.db.Model(&models.QuizTemplate{}).Where("content LIKE ?", "%"+key+"%").Count(&templates).Error
+	return attachments + versions + templates, err
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AttemptRepository stores quiz attempts (participants) and their answers.
+type AttemptRepository interface {
+	CreateAttempt(attempt *models.Participant) (*models.Participant, error)
+	UpdateAttempt(attempt *models.Participant) (*models.Participant, error)
+	FindAttemptByID(id uuid.UUID) (*models.Participant, error)
+	FindUnfinishedAttempt(assignmentID, userID uint) (*models.Participant, error)
+	FindUnfinishedPractice(quizID uuid.UUID, userID uint) (*models.Participant, error)
+	FindAssignmentAttempts(assignmentID uint, userID uint) ([]models.Participant, error)
+	FindUserAttempts(userID uint, filter models.AttemptFilter) ([]models.Participant, int64, error)
+	FindOutdatedAttempts(quizID uuid.UUID, version int) ([]models.Participant, error)
+	FindAnswers(attemptID uuid.UUID) ([]models.Answer, error)
+	SaveAnswer(answer *models.Answer) error
+	SaveRegrade(attempt *models.Participant, answers []models.Answer) error
+	CountLiveAttempts() (int64, error)
+}
+
+type attemptRepository struct {
+	db *gorm.DB
+}
+
+func NewAttemptRepository(db *gorm.DB) AttemptRepository {
+	return &attemptRepository{db: db}
+}
+
+// CreateAttempt inserts the attempt together with the questions a practice
+// attempt covers.
+func (r *attemptRepository) CreateAttempt(attempt *models.Participant) (*models.Participant, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+		if len(attempt.QuestionIDs) == 0 {
+			return nil
+		}
+		questions := []models.PracticeQuestion{}
+		for i, id := range attempt.QuestionIDs {
+			questions = append(questions, models.PracticeQuestion{ParticipantID: attempt.ID, QuestionID: id, Position: i + 1})
+		}
+		return tx.Create(&questions).Error
+	})
+	return attempt, err
+}
+
+func (r *attemptRepository) UpdateAttempt(attempt *models.Participant) (*models.Participant, error) {
+	return attempt, r.db.Save(attempt).Error
+}
+
+// FindAttemptByID returns the attempt with QuestionIDs filled in for
+// practice attempts.
+func (r *attemptRepository) FindAttemptByID(id uuid.UUID) (*models.Participant, error) {
+	attempt := &models.Participant{}
+	if err := r.db.Where("id = ?", id).First(attempt).Error; err != nil {
+		return attempt, err
+	}
+	return attempt, r.fillQuestionIDs(attempt)
+}
+
+// FindUnfinishedPractice returns nil, nil when the user has no practice
+// attempt at the quiz in progress.
+func (r *attemptRepository) FindUnfinishedPractice(quizID uuid.UUID, userID uint) (*models.Participant, error) {
+	attempt := &models.Participant{}
+	err := r.db.Where("quiz_id = ? AND user_id = ? AND practice = ? AND finished = ?", quizID, userID, true, false).
+		Order("created_at DESC").
+		First(attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return attempt, r.fillQuestionIDs(attempt)
+}
+
+func (r *attemptRepository) fillQuestionIDs(attempt *models.Participant) error {
+	if !attempt.Practice {
+		return nil
+	}
+	attempt.QuestionIDs = []uuid.UUID{}
+	return r.db.Model(&models.PracticeQuestion{}).
+		Where("participant_id = ?", attempt.ID).
+		Order("position").
+		Pluck("question_id", &attempt.QuestionIDs).Error
+}
+
+// FindUnfinishedAttempt returns nil, nil when the user has no attempt in
+// progress for the assignment.
+func (r *attemptRepository) FindUnfinishedAttempt(assignmentID, userID uint) (*models.Participant, error) {
+	attempt := &models.Participant{}
+	err := r.db.Where("assignment_id = ? AND user_id = ? AND finished = ?", assignmentID, userID, false).First(attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// FindAssignmentAttempts lists attempts at an assignment in the order they
+// were started, for one user or for everyone when userID is 0.
+func (r *attemptRepository) FindAssignmentAttempts(assignmentID uint, userID uint) ([]models.Participant, error) {
+	query := r.db.Where("assignment_id = ?", assignmentID)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	attempts := []models.Participant{}
+	return attempts, query.Order("created_at, id").Find(&attempts).Error
+}
+
+// FindUserAttempts returns a page of the user's attempts, newest first, and
+// how many match in total.
+func (r *attemptRepository) FindUserAttempts(userID uint, filter models.AttemptFilter) ([]models.Participant, int64, error) {
+	query := r.db.Model(&models.Participant{}).Where("user_id = ?", userID)
+	if filter.QuizID != nil {
+		query = query.Where("quiz_id = ?", *filter.QuizID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	attempts := []models.Participant{}
+	err := query.
+		Order("created_at DESC, id").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&attempts).Error
+	return attempts, total, err
+}
+
+// FindOutdatedAttempts returns the attempts at the quiz pinned to an
+// earlier version than version, with QuestionIDs filled in for practice
+// attempts.
+func (r *attemptRepository) FindOutdatedAttempts(quizID uuid.UUID, version int) ([]models.Participant, error) {
+	attempts := []models.Participant{}
+	err := r.db.Where("quiz_id = ? AND quiz_version < ?", quizID, version).Order("created_at, id").Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range attempts {
+		if err := r.fillQuestionIDs(&attempts[i]); err != nil {
+			return nil, err
+		}
+	}
+	return attempts, nil
+}
+
+func (r *attemptRepository) FindAnswers(attemptID uuid.UUID) ([]models.Answer, error) {
+	answers := []models.Answer{}
+	return answers, r.db.Where("participant_id = ?", attemptID).Order("answered_at, id").Find(&answers).Error
+}
+
+// SaveAnswer records the answer, replacing an earlier one to the same question.
+func (r *attemptRepository) SaveAnswer(answer *models.Answer) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		existing := &models.Answer{}
+		err := tx.Where("participant_id = ? AND question_id = ?", answer.ParticipantID, answer.QuestionID).First(existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(answer).Error
+		}
+		if err != nil {
+			return err
+		}
+		answer.ID = existing.ID
+		return tx.Save(answer).Error
+	})
+}
+
+// SaveRegrade saves the regraded answers together with the attempt.
+func (r *attemptRepository) SaveRegrade(attempt *models.Participant, answers []models.Answer) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range answers {
+			if err := tx.Model(&answers[i]).Update("correct", answers[i].Correct).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(attempt).Error
+	})
+}
+
+// CountLiveAttempts counts the attempts started but not yet finished.
+func (r *attemptRepository) CountLiveAttempts() (int64, error) {
+	var count int64
+	return count, r.db.Model(&models.Participant{}).Where("finished = ?", false).Count(&count).Error
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository interface {
+	CreateAuditLog(log *models.AuditLog) error
+	FindAuditLogs(filter models.AuditLogFilter) ([]models.AuditLog, int64, error)
+}
+
+type auditLogRepository struct {
+	DB *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{DB: db}
+}
+
+func (r *auditLogRepository) CreateAuditLog(log *models.AuditLog) error {
+	return r.DB.Create(log).Error
+}
+
+func (r *auditLogRepository) FindAuditLogs(filter models.AuditLogFilter) ([]models.AuditLog, int64, error) {
+	query := r.DB.Model(&models.AuditLog{})
+
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	logs := []models.AuditLog{}
+	err := query.Order("created_at DESC, id DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
 	"gorm.io/gorm"
 )
@@ -11,7 +13,18 @@ type CategoryRepository interface {
 	DeleteCategory(category *models.Category) error
 	GetCategoryByID(id uint) (*models.Category, error)
 	GetCategoryByName(name string) (*models.Category, error)
+	GetCategoryBySlug(slug string) (*models.Category, error)
+	SlugTaken(slug string, exceptID uint) (bool, error)
 	GetAllCategories() ([]models.Category, error)
+	GetDeletedCategories() ([]models.Category, error)
+	GetDeletedCategoryByID(id uint) (*models.Category, error)
+	RestoreCategory(category *models.Category) error
+	PurgeCategory(category *models.Category) error
+	GetDeletedCategoriesBefore(before time.Time) ([]models.Category, error)
+	CountQuizzesByCategoryID(id uint) (int64, error)
+	CountTemplatesByCategoryID(id uint) (int64, error)
+	CountChildren(id uint) (int64, error)
+	MergeCategory(source, target *models.Category) error
 }
 
 type categoryRepository struct {
@@ -44,7 +57,79 @@ func (r *categoryRepository) GetCategoryByName(name string) (*models.Category, e
 	return category, r.db.Where("name = ?", name).First(category).Error
 }
 
+func (r *categoryRepository) GetCategoryBySlug(slug string) (*models.Category, error) {
+	category := &models.Category{}
+	return category, r.db.Where("slug = ?", slug).First(category).Error
+}
+
+// SlugTaken reports whether another category, trashed ones included, uses
+// the slug.
+func (r *categoryRepository) SlugTaken(slug string, exceptID uint) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.Category{}).Where("slug = ? AND id <> ?", slug, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
 func (r *categoryRepository) GetAllCategories() ([]models.Category, error) {
 	categories := []models.Category{}
-	return categories, r.db.Find(&categories).Error
+	return categories, r.db.Order("name").Find(&categories).Error
+}
+
+func (r *categoryRepository) GetDeletedCategories() ([]models.Category, error) {
+	categories := []models.Category{}
+	return categories, r.db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&categories).Error
+}
+
+func (r *categoryRepository) GetDeletedCategoryByID(id uint) (*models.Category, error) {
+	category := &models.Category{}
+	return category, r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(category).Error
+}
+
+func (r *categoryRepository) RestoreCategory(category *models.Category) error {
+	return r.db.Unscoped().Model(category).Updates(map[string]interface{}{
+		"name":       category.Name,
+		"parent_id":  category.ParentID,
+		"deleted_at": nil,
+	}).Error
+}
+
+func (r *categoryRepository) PurgeCategory(category *models.Category) error {
+	return r.db.Unscoped().Delete(category).Error
+}
+
+func (r *categoryRepository) GetDeletedCategoriesBefore(before time.Time) ([]models.Category, error) {
+	categories := []models.Category{}
+	return categories, r.db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Find(&categories).Error
+}
+
+func (r *categoryRepository) CountQuizzesByCategoryID(id uint) (int64, error) {
+	var count int64
+	return count, r.db.Model(&models.Quiz{}).Where("category_id = ?", id).Count(&count).Error
+}
+
+func (r *categoryRepository) CountTemplatesByCategoryID(id uint) (int64, error) {
+	var count int64
+	return count, r.db.Model(&models.QuizTemplate{}).Where("category_id = ?", id).Count(&count).Error
+}
+
+func (r *categoryRepository) CountChildren(id uint) (int64, error) {
+	var count int64
+	return count, r.db.Model(&models.Category{}).Where("parent_id = ?", id).Count(&count).Error
+}
+
+// MergeCategory moves the source category's quizzes, templates and
+// subcategories to the target and trashes the source.
+func (r *categoryRepository) MergeCategory(source, target *models.Category) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Quiz{}).Where("category_id = ?", source.ID).Update("category_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.QuizTemplate{}).Where("category_id = ?", source.ID).Update("category_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", source.ID).Update("parent_id", target.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(source).Error
+	})
 }
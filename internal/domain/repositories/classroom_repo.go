@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ClassroomRepository interface {
+	CreateClassroom(classroom *models.Classroom) (*models.Classroom, error)
+	UpdateClassroom(classroom *models.Classroom) (*models.Classroom, error)
+	DeleteClassroom(classroom *models.Classroom) error
+	FindClassroomByID(id uint) (*models.Classroom, error)
+	FindClassroomByJoinCode(code string) (*models.Classroom, error)
+	FindClassrooms(teacherID uint) ([]models.ClassroomList, error)
+	FindClassroomsByStudentID(userID uint) ([]models.ClassroomList, error)
+	AddMember(member *models.ClassroomMember) error
+	RemoveMember(classroomID, userID uint) (int64, error)
+	FindRoster(classroomID uint) ([]models.RosterEntry, error)
+	AssignQuiz(link *models.ClassroomQuiz) error
+	UnassignQuiz(classroomID uint, quizID uuid.UUID) (int64, error)
+	FindClassroomQuizzes(classroomID uint) ([]models.QuizList, error)
+	FindResults(classroomID uint) ([]models.ClassroomResult, error)
+}
+
+type classroomRepository struct {
+	db *gorm.DB
+}
+
+func NewClassroomRepository(db *gorm.DB) ClassroomRepository {
+	return &classroomRepository{db: db}
+}
+
+func (r *classroomRepository) CreateClassroom(classroom *models.Classroom) (*models.Classroom, error) {
+	return classroom, r.db.Create(classroom).Error
+}
+
+func (r *classroomRepository) UpdateClassroom(classroom *models.Classroom) (*models.Classroom, error) {
+	return classroom, r.db.Save(classroom).Error
+}
+
+// DeleteClassroom soft-deletes the classroom and drops its roster and quiz
+// assignments; attempts already made are kept.
+func (r *classroomRepository) DeleteClassroom(classroom *models.Classroom) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("classroom_id = ?", classroom.ID).Delete(&models.ClassroomMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("classroom_id = ?", classroom.ID).Delete(&models.ClassroomQuiz{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(classroom).Error
+	})
+}
+
+func (r *classroomRepository) FindClassroomByID(id uint) (*models.Classroom, error) {
+	classroom := &models.Classroom{}
+	return classroom, r.db.Where("id = ?", id).First(classroom).Error
+}
+
+func (r *classroomRepository) FindClassroomByJoinCode(code string) (*models.Classroom, error) {
+	classroom := &models.Classroom{}
+	return classroom, r.db.Where("join_code = ?", code).First(classroom).Error
+}
+
+// FindClassrooms lists the classrooms of a teacher, or all of them when
+// teacherID is 0.
+func (r *classroomRepository) FindClassrooms(teacherID uint) ([]models.ClassroomList, error) {
+	query := r.listQuery()
+	if teacherID != 0 {
+		query = query.Where("classrooms.teacher_id = ?", teacherID)
+	}
+	classrooms := []models.ClassroomList{}
+	return classrooms, query.Scan(&classrooms).Error
+}
+
+func (r *classroomRepository) FindClassroomsByStudentID(userID uint) ([]models.ClassroomList, error) {
+	enrolled := r.db.Model(&models.ClassroomMember{}).Select("classroom_id").Where("user_id = ?", userID)
+	classrooms := []models.ClassroomList{}
+	return classrooms, r.listQuery().Where("classrooms.id IN (?)", enrolled).Scan(&classrooms).Error
+}
+
+func (r *classroomRepository) listQuery() *gorm.DB {
+	return r.db.Model(&models.Classroom{}).
+		Select("classrooms.id, classrooms.name, classrooms.teacher_id, COUNT(classroom_members.id) AS student_count").
+		Joins("LEFT JOIN classroom_members ON classroom_members.classroom_id = classrooms.id").
+		Group("classrooms.id, classrooms.name, classrooms.teacher_id").
+		Order("classrooms.name")
+}
+
+func (r *classroomRepository) AddMember(member *models.ClassroomMember) error {
+	return r.db.Create(member).Error
+}
+
+func (r *classroomRepository) RemoveMember(classroomID, userID uint) (int64, error) {
+	res := r.db.Where("classroom_id = ? AND user_id = ?", classroomID, userID).Delete(&models.ClassroomMember{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *classroomRepository) FindRoster(classroomID uint) ([]models.RosterEntry, error) {
+	roster := []models.RosterEntry{}
+	err := r.db.Model(&models.ClassroomMember{}).
+		Select("users.id AS user_id, users.username, users.email, classroom_members.created_at AS enrolled_at").
+		Joins("JOIN users ON users.id = classroom_members.user_id AND users.deleted_at IS NULL").
+		Where("classroom_members.classroom_id = ?", classroomID).
+		Order("users.username").
+		Scan(&roster).Error
+	return roster, err
+}
+
+func (r *classroomRepository) AssignQuiz(link *models.ClassroomQuiz) error {
+	return r.db.Create(link).Error
+}
+
+func (r *classroomRepository) UnassignQuiz(classroomID uint, quizID uuid.UUID) (int64, error) {
+	res := r.db.Where("classroom_id = ? AND quiz_id = ?", classroomID, quizID).Delete(&models.ClassroomQuiz{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *classroomRepository) FindClassroomQuizzes(classroomID uint) ([]models.QuizList, error) {
+	quizzes := []models.QuizList{}
+	err := r.db.Model(&models.ClassroomQuiz{}).
+		Select("quizzes.id, quizzes.title, quizzes.description, categories.name AS category, quizzes.difficulty").
+		Joins("JOIN quizzes ON quizzes.id = classroom_quizzes.quiz_id").
+		Joins("LEFT JOIN categories ON categories.id = quizzes.category_id").
+		Where("classroom_quizzes.classroom_id = ?", classroomID).
+		Order("classroom_quizzes.created_at").
+		Scan(&quizzes).Error
+	return quizzes, err
+}
+
+// FindResults returns attempts by the classroom's students at the quizzes
+// assigned to it, newest first. Practice attempts are left out.
+func (r *classroomRepository) FindResults(classroomID uint) ([]models.ClassroomResult, error) {
+	members := r.db.Model(&models.ClassroomMember{}).Select("user_id").Where("classroom_id = ?", classroomID)
+	quizzes := r.db.Model(&models.ClassroomQuiz{}).Select("quiz_id").Where("classroom_id = ?", classroomID)
+
+	results := []models.ClassroomResult{}
+	err := r.db.Model(&models.Participant{}).
+		Select("participants.id AS participant_id, participants.user_id, users.username, participants.quiz_id, quizzes.title AS quiz_title, participants.score, participants.finished, participants.created_at").
+		Joins("JOIN users ON users.id = participants.user_id").
+		Joins("JOIN quizzes ON quizzes.id = participants.quiz_id").
+		Where("participants.user_id IN (?) AND participants.quiz_id IN (?)", members, quizzes).
+		Where("participants.practice = ?", false).
+		Order("participants.created_at DESC").
+		Scan(&results).Error
+	return results, err
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GradingRepository reads and stores the manual grading of essay answers.
+type GradingRepository interface {
+	FindGradingQueue(creatorID uint) ([]models.GradingItem, error)
+	FindAnswerByID(id uuid.UUID) (*models.Answer, error)
+	SaveGrade(answer *models.Answer, scores []models.RubricScore) error
+	CountUngradedAnswers(attemptID uuid.UUID) (int64, error)
+}
+
+type gradingRepository struct {
+	db *gorm.DB
+}
+
+func NewGradingRepository(db *gorm.DB) GradingRepository {
+	return &gradingRepository{db: db}
+}
+
+// FindGradingQueue returns the ungraded essay answers of finished attempts
+// at assignments created by creatorID, or at any assignment when creatorID
+// is 0, oldest first and with each question's rubric.
+func (r *gradingRepository) FindGradingQueue(creatorID uint) ([]models.GradingItem, error) {
+	query := r.db.Model(&models.Answer{}).
+		Select("answers.id AS answer_id, answers.participant_id, participants.user_id, users.username, participants.quiz_id, quizzes.title AS quiz_title, answers.question_id, questions.text AS question_text, answers.text, answers.answered_at").
+		Joins("JOIN participants ON participants.id = answers.participant_id").
+		Joins("JOIN assignments ON assignments.id = participants.assignment_id").
+		Joins("JOIN quizzes ON quizzes.id = participants.quiz_id").
+		Joins("JOIN questions ON questions.id = answers.question_id").
+		Joins("LEFT JOIN users ON users.id = participants.user_id").
+		Where("answers.needs_grading = ? AND participants.finished = ?", true, true)
+	if creatorID != 0 {
+		query = query.Where("assignments.created_by = ?", creatorID)
+	}
+
+	items := []models.GradingItem{}
+	if err := query.Order("answers.answered_at, answers.id").Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	questionIDs := []uuid.UUID{}
+	for _, item := range items {
+		questionIDs = append(questionIDs, item.QuestionID)
+	}
+	criteria := []models.RubricCriterion{}
+	if err := r.db.Where("question_id IN ?", questionIDs).Order("position").Find(&criteria).Error; err != nil {
+		return nil, err
+	}
+	for i := range items {
+		for _, c := range criteria {
+			if c.QuestionID == items[i].QuestionID {
+				items[i].Rubric = append(items[i].Rubric, c)
+			}
+		}
+	}
+	return items, nil
+}
+
+func (r *gradingRepository) FindAnswerByID(id uuid.UUID) (*models.Answer, error) {
+	answer := &models.Answer{}
+	return answer, r.db.Where("id = ?", id).First(answer).Error
+}
+
+// SaveGrade stores the answer's grade, replacing any earlier rubric scores.
+func (r *gradingRepository) SaveGrade(answer *models.Answer, scores []models.RubricScore) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("answer_id = ?", answer.ID).Delete(&models.RubricScore{}).Error; err != nil {
+			return err
+		}
+		if len(scores) > 0 {
+			if err := tx.Create(&scores).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(answer).Error
+	})
+}
+
+func (r *gradingRepository) CountUngradedAnswers(attemptID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Answer{}).Where("participant_id = ? AND needs_grading = ?", attemptID, true).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type ProfileRepository interface {
+	FindProfileByUserID(userID uint) (*models.Profile, error)
+	SaveProfile(profile *models.Profile) (*models.Profile, error)
+	CreateEmailChange(change *models.EmailChange) error
+	FindEmailChangeByTokenHash(tokenHash string) (*models.EmailChange, error)
+	ApplyEmailChange(change *models.EmailChange) error
+}
+
+type profileRepository struct {
+	DB *gorm.DB
+}
+
+func NewProfileRepository(db *gorm.DB) ProfileRepository {
+	return &profileRepository{DB: db}
+}
+
+// FindProfileByUserID returns nil, nil when the user never saved a profile.
+func (r *profileRepository) FindProfileByUserID(userID uint) (*models.Profile, error) {
+	profile := &models.Profile{}
+	err := r.DB.Where("user_id = ?", userID).First(profile).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (r *profileRepository) SaveProfile(profile *models.Profile) (*models.Profile, error) {
+	err := r.DB.Save(profile).Error
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// CreateEmailChange stores a pending change, replacing any earlier one the
+// user did not confirm so only the latest link works.
+func (r *profileRepository) CreateEmailChange(change *models.EmailChange) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", change.UserID).Delete(&models.EmailChange{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+}
+
+func (r *profileRepository) FindEmailChangeByTokenHash(tokenHash string) (*models.EmailChange, error) {
+	change := &models.EmailChange{}
+	err := r.DB.Where("token_hash = ? AND expires_at > ?", tokenHash, time.Now()).First(change).Error
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// ApplyEmailChange updates the user's email and drops the pending change.
+func (r *profileRepository) ApplyEmailChange(change *models.EmailChange) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", change.UserID).Updates(map[string]interface{}{
+			"email":      change.NewEmail,
+			"updated_at": time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", change.UserID).Delete(&models.EmailChange{}).Error
+	})
+}
@@ -0,0 +1,420 @@
+package repositories
+
+import (
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type QuizRepository interface {
+	CreateQuiz(quiz *models.Quiz) (*models.Quiz, error)
+	UpdateQuiz(quiz *models.Quiz) (*models.Quiz, error)
+	ReplaceQuestions(quiz *models.Quiz) error
+	DeleteQuiz(quiz *models.Quiz) error
+	FindQuizByID(id uuid.UUID) (*models.Quiz, error)
+	FindRevision(quizID uuid.UUID) (*models.Quiz, error)
+	PublishQuiz(quiz *models.Quiz, version *models.QuizVersion) error
+	PublishRevision(quiz, revision *models.Quiz, version *models.QuizVersion) error
+	CorrectAnswerKey(question *models.Question, version *models.QuizVersion) error
+	FindVersions(quizID uuid.UUID) ([]models.QuizVersion, error)
+	FindVersion(quizID uuid.UUID, version int) (*models.QuizVersion, error)
+	FindAllQuizzes(filter models.QuizFilter) ([]models.Quiz, error)
+	FindCatalog(filter models.CatalogFilter) ([]models.Quiz, int64, error)
+	CountQuestions(quizIDs []uuid.UUID) (map[uuid.UUID]int, error)
+	FindQuestions(filter models.QuestionFilter) ([]models.Question, int64, error)
+	ReplaceQuizTags(quiz *models.Quiz) error
+	CountParticipantsByQuizID(id uuid.UUID) (int64, error)
+	FindCalibrationAnswers(quizID uuid.UUID) ([]models.CalibrationAnswer, error)
+	SaveCalibration(quiz *models.Quiz, questions []models.Question) error
+	FindDifficultySuggestions() ([]models.DifficultySuggestion, error)
+}
+
+type quizRepository struct {
+	db *gorm.DB
+}
+
+func NewQuizRepository(db *gorm.DB) QuizRepository {
+	return &quizRepository{db: db}
+}
+
+// CreateQuiz inserts the quiz together with its questions and options.
+func (r *quizRepository) CreateQuiz(quiz *models.Quiz) (*models.Quiz, error) {
+	return quiz, r.db.Omit("Category").Create(quiz).Error
+}
+
+// quizColumns are the quiz's own columns saved by UpdateQuiz.
+var quizColumns = []interface{}{
+	"description", "category_id", "difficulty", "duration", "release_score", "release_mistakes", "release_answers",
+	"adaptive", "adaptive_max_se", "adaptive_max_items", "status", "questions_changed", "submitted_by", "reviewed_by",
+	"review_comment", "published_at", "version", "updated_at",
+}
+
+// UpdateQuiz saves the quiz's own columns; questions are left as they are.
+func (r *quizRepository) UpdateQuiz(quiz *models.Quiz) (*models.Quiz, error) {
+	return quiz, r.db.Model(quiz).Select("title", quizColumns...).Updates(quiz).Error
+}
+
+// ReplaceQuestions deletes the quiz's questions and options and inserts
+// quiz.Questions in their place.
+func (r *quizRepository) ReplaceQuestions(quiz *models.Quiz) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteQuestions(tx, quiz.ID); err != nil {
+			return err
+		}
+		for i := range quiz.Questions {
+			quiz.Questions[i].QuizID = quiz.ID
+		}
+		if len(quiz.Questions) == 0 {
+			return nil
+		}
+		return tx.Create(&quiz.Questions).Error
+	})
+}
+
+func (r *quizRepository) DeleteQuiz(quiz *models.Quiz) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return deleteQuiz(tx, quiz)
+	})
+}
+
+// FindRevision returns nil, nil when the quiz has no draft revision.
+func (r *quizRepository) FindRevision(quizID uuid.UUID) (*models.Quiz, error) {
+	var ids []uuid.UUID
+	if err := r.db.Model(&models.Quiz{}).Where("revision_of = ?", quizID).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return r.FindQuizByID(ids[0])
+}
+
+// PublishQuiz saves the quiz's own columns together with its new version.
+func (r *quizRepository) PublishQuiz(quiz *models.Quiz, version *models.QuizVersion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(quiz).Select("title", quizColumns...).Updates(quiz).Error; err != nil {
+			return err
+		}
+		return tx.Create(version).Error
+	})
+}
+
+// PublishRevision saves quiz with the revision's content copied in, and its
+// questions replaced by quiz.Questions when the revision changed them, then
+// deletes the revision. The quiz's new version is saved along with it.
+func (r *quizRepository) PublishRevision(quiz, revision *models.Quiz, version *models.QuizVersion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(quiz).Select("title", quizColumns...).Updates(quiz).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(quiz).Association("Tags").Replace(quiz.Tags); err != nil {
+			return err
+		}
+		if revision.QuestionsChanged {
+			if err := deleteQuestions(tx, quiz.ID); err != nil {
+				return err
+			}
+			for i := range quiz.Questions {
+				quiz.Questions[i].QuizID = quiz.ID
+			}
+			if len(quiz.Questions) > 0 {
+				if err := tx.Create(&quiz.Questions).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return deleteQuiz(tx, revision)
+	})
+}
+
+// CorrectAnswerKey saves the question's answer and the quiz's version
+// recording it.
+func (r *quizRepository) CorrectAnswerKey(question *models.Question, version *models.QuizVersion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(question).Update("answer_id", question.AnswerID).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&models.Quiz{}).Where("id = ?", version.QuizID).
+			Updates(map[string]interface{}{"version": version.Version, "updated_at": version.CreatedAt}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(version).Error
+	})
+}
+
+// FindVersions returns the quiz's versions, newest first, without their
+// content.
+func (r *quizRepository) FindVersions(quizID uuid.UUID) ([]models.QuizVersion, error) {
+	versions := []models.QuizVersion{}
+	err := r.db.Omit("content").Where("quiz_id = ?", quizID).Order("version DESC").Find(&versions).Error
+	return versions, err
+}
+
+func (r *quizRepository) FindVersion(quizID uuid.UUID, version int) (*models.QuizVersion, error) {
+	v := &models.QuizVersion{}
+	if err := r.db.Where("quiz_id = ? AND version = ?", quizID, version).First(v).Error; err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (r *quizRepository) FindQuizByID(id uuid.UUID) (*models.Quiz, error) {
+	quiz := &models.Quiz{}
+	err := r.db.
+		Preload("Category").
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("name") }).
+		Preload("Questions", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Questions.Tags", func(db *gorm.DB) *gorm.DB { return db.Order("name") }).
+		Preload("Questions.Options", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Questions.Rubric", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Questions.Attachments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Where("id = ?", id).First(quiz).Error
+	if err != nil {
+		return nil, err
+	}
+	return quiz, nil
+}
+
+// FindAllQuizzes returns the quizzes matching the filter, newest first,
+// leaving out draft revisions.
+func (r *quizRepository) FindAllQuizzes(filter models.QuizFilter) ([]models.Quiz, error) {
+	quizzes := []models.Quiz{}
+	query := r.db.
+		Preload("Category").
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("name") }).
+		Where("revision_of IS NULL").
+		Order("created_at DESC")
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ViewerID != 0 {
+		condition, args := visibleQuizzes(filter.ViewerID)
+		query = query.Where(condition, args...)
+	}
+	if filter.CategoryIDs != nil {
+		query = query.Where("category_id IN ?", filter.CategoryIDs)
+	}
+	if len(filter.Tags) > 0 {
+		query = query.Where("id IN (?)", r.taggedIDs("quiz_tags", "quiz_id", filter.Tags, filter.MatchAllTags))
+	}
+	return quizzes, query.Find(&quizzes).Error
+}
+
+// FindCatalog returns a page of the published quizzes matching the filter,
+// by title, and how many match in total.
+func (r *quizRepository) FindCatalog(filter models.CatalogFilter) ([]models.Quiz, int64, error) {
+	query := r.db.Model(&models.Quiz{}).Where("status = ?", constant.QuizPublished)
+	if filter.CategoryIDs != nil {
+		query = query.Where("category_id IN ?", filter.CategoryIDs)
+	}
+	if len(filter.Tags) > 0 {
+		query = query.Where("id IN (?)", r.taggedIDs("quiz_tags", "quiz_id", filter.Tags, filter.MatchAllTags))
+	}
+	if filter.Difficulty != "" {
+		query = query.Where("difficulty = ?", filter.Difficulty)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	quizzes := []models.Quiz{}
+	err := query.
+		Preload("Category").
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("name") }).
+		Order("title, id").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&quizzes).Error
+	return quizzes, total, err
+}
+
+// CountQuestions returns how many questions each of the quizzes has;
+// quizzes without questions are left out.
+func (r *quizRepository) CountQuestions(quizIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := map[uuid.UUID]int{}
+	if len(quizIDs) == 0 {
+		return counts, nil
+	}
+
+	rows := []struct {
+		QuizID uuid.UUID
+		Count  int
+	}{}
+	err := r.db.Model(&models.Question{}).
+		Select("quiz_id, COUNT(*) AS count").
+		Where("quiz_id IN ?", quizIDs).
+		Group("quiz_id").
+		Scan(&rows).Error
+	for _, row := range rows {
+		counts[row.QuizID] = row.Count
+	}
+	return counts, err
+}
+
+// FindQuestions returns a page of the questions matching the filter, by
+// quiz and position, and how many match in total.
+func (r *quizRepository) FindQuestions(filter models.QuestionFilter) ([]models.Question, int64, error) {
+	query := r.db.Model(&models.Question{})
+	if filter.QuizID != nil {
+		query = query.Where("quiz_id = ?", *filter.QuizID)
+	}
+	if filter.ViewerID != 0 {
+		condition, args := visibleQuizzes(filter.ViewerID)
+		query = query.Where("quiz_id IN (?)", r.db.Model(&models.Quiz{}).Select("id").Where(condition, args...))
+	}
+	if len(filter.Tags) > 0 {
+		query = query.Where("id IN (?)", r.taggedIDs("question_tags", "question_id", filter.Tags, filter.MatchAllTags))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	questions := []models.Question{}
+	err := query.
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Rubric", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("name") }).
+		Preload("Attachments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Order("quiz_id, position").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&questions).Error
+	return questions, total, err
+}
+
+// ReplaceQuizTags sets quiz.Tags as the quiz's tags.
+func (r *quizRepository) ReplaceQuizTags(quiz *models.Quiz) error {
+	return r.db.Model(quiz).Association("Tags").Replace(quiz.Tags)
+}
+
+// taggedIDs selects from a tag join table the IDs carrying any of the
+// named tags, or all of them with matchAll. The names must be distinct.
+func (r *quizRepository) taggedIDs(joinTable, idColumn string, names []string, matchAll bool) *gorm.DB {
+	query := r.db.Table(joinTable).
+		Select(joinTable+"."+idColumn).
+		Joins("JOIN tags ON tags.id = "+joinTable+".tag_id").
+		Where("tags.name IN ?", names)
+	if matchAll {
+		query = query.Group(joinTable+"."+idColumn).Having("COUNT(DISTINCT tags.id) = ?", len(names))
+	}
+	return query
+}
+
+func (r *quizRepository) CountParticipantsByQuizID(id uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Participant{}).Where("quiz_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// deleteQuiz deletes the quiz with its questions, tags, versions, shares
+// and the classroom links and assignments that use it.
+func deleteQuiz(tx *gorm.DB, quiz *models.Quiz) error {
+	if err := deleteQuestions(tx, quiz.ID); err != nil {
+		return err
+	}
+	if err := tx.Where("quiz_id = ?", quiz.ID).Delete(&models.ClassroomQuiz{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("quiz_id = ?", quiz.ID).Delete(&models.Assignment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM quiz_tags WHERE quiz_id = ?", quiz.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("quiz_id = ?", quiz.ID).Delete(&models.QuizVersion{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("quiz_id = ?", quiz.ID).Delete(&models.QuizShare{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(quiz).Error
+}
+
+func deleteQuestions(tx *gorm.DB, quizID uuid.UUID) error {
+	questionIDs := tx.Model(&models.Question{}).Select("id").Where("quiz_id = ?", quizID)
+	if err := tx.Where("question_id IN (?)", questionIDs).Delete(&models.Option{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("question_id IN (?)", questionIDs).Delete(&models.RubricCriterion{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM question_tags WHERE question_id IN (?)", questionIDs).Error; err != nil {
+		return err
+	}
+	// Stored files stay: copies and versions of the question may use them.
+	if err := tx.Where("question_id IN (?)", questionIDs).Delete(&models.Attachment{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("quiz_id = ?", quizID).Delete(&models.Question{}).Error
+}
+
+// FindCalibrationAnswers returns the multiple choice answers of finished
+// attempts at the quiz that served every question, i.e. neither practice
+// nor adaptive ones.
+func (r *quizRepository) FindCalibrationAnswers(quizID uuid.UUID) ([]models.CalibrationAnswer, error) {
+	answers := []models.CalibrationAnswer{}
+	err := r.db.Model(&models.Answer{}).
+		Select("answers.participant_id, answers.question_id, answers.correct").
+		Joins("JOIN participants ON participants.id = answers.participant_id").
+		Joins("JOIN questions ON questions.id = answers.question_id").
+		Where("participants.quiz_id = ? AND participants.finished = ?", quizID, true).
+		Where("participants.practice = ? AND participants.adaptive = ?", false, false).
+		Where("questions.type = ?", constant.QuestionMultipleChoice).
+		Scan(&answers).Error
+	return answers, err
+}
+
+// SaveCalibration stores the quiz's success rate and the questions' IRT
+// parameters and success rates.
+func (r *quizRepository) SaveCalibration(quiz *models.Quiz, questions []models.Question) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(quiz).
+			Select("success_rate", "suggested_difficulty").
+			Updates(quiz).Error
+		if err != nil {
+			return err
+		}
+		for i := range questions {
+			err := tx.Model(&questions[i]).
+				Select("irt_discrimination", "irt_difficulty", "irt_responses", "calibrated_at", "success_rate", "suggested_difficulty").
+				Updates(&questions[i]).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindDifficultySuggestions returns the quizzes, then the questions, whose
+// suggested difficulty differs from their own.
+func (r *quizRepository) FindDifficultySuggestions() ([]models.DifficultySuggestion, error) {
+	quizzes := []models.DifficultySuggestion{}
+	err := r.db.Model(&models.Quiz{}).
+		Select("id AS quiz_id, title AS quiz_title, difficulty, suggested_difficulty, success_rate").
+		Where("suggested_difficulty <> '' AND suggested_difficulty <> difficulty").
+		Order("title").
+		Scan(&quizzes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	questions := []models.DifficultySuggestion{}
+	err = r.db.Model(&models.Question{}).
+		Select("quizzes.id AS quiz_id, quizzes.title AS quiz_title, questions.id AS question_id, questions.position, questions.text, " +
+			"questions.difficulty, questions.suggested_difficulty, questions.success_rate").
+		Joins("JOIN quizzes ON quizzes.id = questions.quiz_id").
+		Where("questions.suggested_difficulty <> '' AND questions.suggested_difficulty <> questions.difficulty").
+		Order("quizzes.title, questions.position").
+		Scan(&questions).Error
+	return append(quizzes, questions...), err
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReviewCardRepository stores the users' spaced repetition cards.
+type ReviewCardRepository interface {
+	FindMissedAnswers(userID uint) ([]models.MissedAnswer, error)
+	FindCard(userID uint, questionID uuid.UUID) (*models.ReviewCard, error)
+	FindCardByID(id uint) (*models.ReviewCard, error)
+	FindDueCards(userID uint, now time.Time, limit int) ([]models.ReviewCard, error)
+	SaveCard(card *models.ReviewCard) error
+}
+
+type reviewCardRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewCardRepository(db *gorm.DB) ReviewCardRepository {
+	return &reviewCardRepository{db: db}
+}
+
+// FindMissedAnswers returns the user's wrong multiple choice answers in
+// finished attempts that are newer than the question's card, oldest first.
+// Answers to questions a later version replaced are included too; their
+// type is only known from the attempt's version.
+func (r *reviewCardRepository) FindMissedAnswers(userID uint) ([]models.MissedAnswer, error) {
+	missed := []models.MissedAnswer{}
+	err := r.db.Model(&models.Answer{}).
+		Select("answers.participant_id, answers.question_id, participants.quiz_id, answers.answered_at").
+		Joins("JOIN participants ON participants.id = answers.participant_id").
+		Joins("LEFT JOIN questions ON questions.id = answers.question_id").
+		Joins("LEFT JOIN review_cards ON review_cards.user_id = participants.user_id AND review_cards.question_id = answers.question_id").
+		Where("participants.user_id = ? AND participants.finished = ?", userID, true).
+		Where("answers.correct = ? AND answers.needs_grading = ?", false, false).
+		Where("questions.id IS NULL OR questions.type = ?", constant.QuestionMultipleChoice).
+		Where("review_cards.id IS NULL OR answers.answered_at > review_cards.last_missed_at").
+		Order("answers.answered_at").
+		Scan(&missed).Error
+	return missed, err
+}
+
+// FindCard returns nil, nil when the user has no card for the question.
+func (r *reviewCardRepository) FindCard(userID uint, questionID uuid.UUID) (*models.ReviewCard, error) {
+	card := &models.ReviewCard{}
+	err := r.db.Where("user_id = ? AND question_id = ?", userID, questionID).First(card).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+func (r *reviewCardRepository) FindCardByID(id uint) (*models.ReviewCard, error) {
+	card := &models.ReviewCard{}
+	return card, r.db.First(card, id).Error
+}
+
+// FindDueCards returns up to limit of the user's cards due at now, the most
+// overdue first.
+func (r *reviewCardRepository) FindDueCards(userID uint, now time.Time, limit int) ([]models.ReviewCard, error) {
+	cards := []models.ReviewCard{}
+	err := r.db.Where("user_id = ? AND due_at <= ?", userID, now).
+		Order("due_at, id").
+		Limit(limit).
+		Find(&cards).Error
+	return cards, err
+}
+
+func (r *reviewCardRepository) SaveCard(card *models.ReviewCard) error {
+	return r.db.Save(card).Error
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
 	"gorm.io/gorm"
 )
@@ -12,6 +14,12 @@ type RoleRepository interface {
 	FindRoleByID(id uint) (*models.Role, error)
 	FindRoleByName(name string) (*models.Role, error)
 	FindAllRoles() ([]models.Role, error)
+	FindDeletedRoles() ([]models.Role, error)
+	FindDeletedRoleByID(id uint) (*models.Role, error)
+	Restore(role *models.Role) error
+	Purge(role *models.Role) error
+	FindDeletedRolesBefore(before time.Time) ([]models.Role, error)
+	CountUsersByRoleID(id uint) (int64, error)
 }
 
 type roleRepository struct {
@@ -24,7 +32,7 @@ func NewRoleRepository(db *gorm.DB) RoleRepository {
 
 func (r *roleRepository) Create(role *models.Role) (*models.Role, error) {
 	err := r.DB.Create(role).Error
-	
+
 	if err != nil {
 		return nil, err
 	}
@@ -75,3 +83,52 @@ func (r *roleRepository) FindRoleByName(name string) (*models.Role, error) {
 	}
 	return role, nil
 }
+
+func (r *roleRepository) FindDeletedRoles() ([]models.Role, error) {
+	var roles []models.Role
+
+	err := r.DB.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (r *roleRepository) FindDeletedRoleByID(id uint) (*models.Role, error) {
+	role := &models.Role{}
+	err := r.DB.Unscoped().Where("deleted_at IS NOT NULL").First(role, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (r *roleRepository) Restore(role *models.Role) error {
+	return r.DB.Unscoped().Model(role).Updates(map[string]interface{}{
+		"name":       role.Name,
+		"deleted_at": nil,
+	}).Error
+}
+
+func (r *roleRepository) Purge(role *models.Role) error {
+	return r.DB.Unscoped().Delete(role).Error
+}
+
+func (r *roleRepository) FindDeletedRolesBefore(before time.Time) ([]models.Role, error) {
+	var roles []models.Role
+
+	err := r.DB.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// CountUsersByRoleID counts users holding the role, including trashed ones.
+func (r *roleRepository) CountUsersByRoleID(id uint) (int64, error) {
+	var count int64
+	err := r.DB.Unscoped().Model(&models.User{}).Where("role_id = ?", id).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"strings"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SearchRepository keeps quizzes and their questions in the database's
+// native full-text index and searches them. Terms are lowercase words of
+// letters and digits; a document matches when it has a word starting with
+// every term.
+type SearchRepository interface {
+	// Migrate creates the full-text index and, when it is empty, fills it
+	// from the existing quizzes.
+	Migrate() error
+	IndexQuiz(quiz *models.Quiz) error
+	RemoveQuiz(quizID uuid.UUID) error
+	// Search leaves out the quizzes viewerID may not see, unless it is 0.
+	Search(terms []string, limit int, viewerID uint) ([]models.SearchHit, error)
+}
+
+// NewSearchRepository returns the implementation for the database's
+// provider: Postgres tsvector, MySQL FULLTEXT or SQLite FTS5.
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	docs := searchDocuments{db: db}
+	switch db.Dialector.Name() {
+	case "postgres":
+		return &postgresSearch{docs}
+	case "mysql":
+		return &mysqlSearch{docs}
+	default:
+		return &sqliteSearch{searchDocuments: docs, fallback: !sqliteHasFTS5(db)}
+	}
+}
+
+// searchDocuments maintains the search_documents table the provider
+// specific indexes are built on.
+type searchDocuments struct {
+	db *gorm.DB
+}
+
+// IndexQuiz replaces the quiz's documents: one for the quiz and one per
+// question, with the option texts as its body.
+func (r searchDocuments) IndexQuiz(quiz *models.Quiz) error {
+	docs := []models.SearchDocument{{
+		Entity:   constant.SearchQuiz,
+		EntityID: quiz.ID,
+		QuizID:   quiz.ID,
+		Title:    quiz.Title,
+		Body:     quiz.Description,
+	}}
+	for _, q := range quiz.Questions {
+		options := []string{}
+		for _, o := range q.Options {
+			options = append(options, o.Text)
+		}
+		docs = append(docs, models.SearchDocument{
+			Entity:   constant.SearchQuestion,
+			EntityID: q.ID,
+			QuizID:   quiz.ID,
+			Title:    q.Text,
+			Body:     strings.Join(options, "\n"),
+		})
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("quiz_id = ?", quiz.ID).Delete(&models.SearchDocument{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&docs).Error
+	})
+}
+
+func (r searchDocuments) RemoveQuiz(quizID uuid.UUID) error {
+	return r.db.Where("quiz_id = ?", quizID).Delete(&models.SearchDocument{}).Error
+}
+
+// fill indexes every quiz when there are no documents yet, e.g. right after
+// search was added to an existing database.
+func (r searchDocuments) fill() error {
+	var count int64
+	if err := r.db.Model(&models.SearchDocument{}).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+
+	quizzes := []models.Quiz{}
+	err := r.db.
+		Preload("Questions").
+		Preload("Questions.Options", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Find(&quizzes).Error
+	if err != nil {
+		return err
+	}
+	for i := range quizzes {
+		if err := r.IndexQuiz(&quizzes[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hitColumns selects a search hit from search_documents d joined with its
+// quiz; score is appended by each provider.
+const hitColumns = "d.entity AS type, d.entity_id AS id, d.quiz_id, quizzes.title AS quiz_title, d.title AS text, d.body"
+
+// searchVisibility is the clause appended to a search's WHERE that leaves
+// out the quizzes viewerID may not see, with its arguments.
+func searchVisibility(viewerID uint) (string, []interface{}) {
+	if viewerID == 0 {
+		return "", nil
+	}
+	condition, args := visibleQuizzes(viewerID)
+	return " AND " + condition, args
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"strings"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+)
+
+const mysqlSearchIndex = "idx_search_documents_text"
+
+// mysqlSearch uses an InnoDB FULLTEXT index in boolean mode. Words shorter
+// than innodb_ft_min_token_size (3 by default) and stopwords aren't
+// indexed, so terms like those never match.
+type mysqlSearch struct {
+	searchDocuments
+}
+
+func (r *mysqlSearch) Migrate() error {
+	if !r.db.Migrator().HasIndex(&models.SearchDocument{}, mysqlSearchIndex) {
+		err := r.db.Exec("CREATE FULLTEXT INDEX " + mysqlSearchIndex + " ON search_documents (title, body)").Error
+		if err != nil {
+			return err
+		}
+	}
+	return r.fill()
+}
+
+func (r *mysqlSearch) Search(terms []string, limit int, viewerID uint) ([]models.SearchHit, error) {
+	required := []string{}
+	for _, t := range terms {
+		required = append(required, "+"+t+"*")
+	}
+	query := strings.Join(required, " ")
+
+	visible, args := searchVisibility(viewerID)
+	hits := []models.SearchHit{}
+	err := r.db.Raw("SELECT "+hitColumns+", MATCH (d.title, d.body) AGAINST (? IN BOOLEAN MODE) AS score "+
+		"FROM search_documents d JOIN quizzes ON quizzes.id = d.quiz_id "+
+		"WHERE MATCH (d.title, d.body) AGAINST (? IN BOOLEAN MODE)"+visible+" ORDER BY score DESC, d.id LIMIT ?",
+		append(append([]interface{}{query, query}, args...), limit)...).
+		Scan(&hits).Error
+	return hits, err
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"strings"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+)
+
+// postgresSearch ranks documents with ts_rank over a generated tsvector
+// column, titles weighted above bodies. The simple configuration is used
+// because quizzes are written in more than one language.
+type postgresSearch struct {
+	searchDocuments
+}
+
+func (r *postgresSearch) Migrate() error {
+	err := r.db.Exec("ALTER TABLE search_documents ADD COLUMN IF NOT EXISTS document tsvector GENERATED ALWAYS AS (" +
+		"setweight(to_tsvector('simple', coalesce(title, '')), 'A') || " +
+		"setweight(to_tsvector('simple', coalesce(body, '')), 'B')) STORED").Error
+	if err != nil {
+		return err
+	}
+	err = r.db.Exec("CREATE INDEX IF NOT EXISTS idx_search_documents_document ON search_documents USING GIN (document)").Error
+	if err != nil {
+		return err
+	}
+	return r.fill()
+}
+
+func (r *postgresSearch) Search(terms []string, limit int, viewerID uint) ([]models.SearchHit, error) {
+	prefixes := []string{}
+	for _, t := range terms {
+		prefixes = append(prefixes, t+":*")
+	}
+
+	visible, args := searchVisibility(viewerID)
+	hits := []models.SearchHit{}
+	err := r.db.Raw("SELECT "+hitColumns+", ts_rank(d.document, q) AS score "+
+		"FROM search_documents d JOIN quizzes ON quizzes.id = d.quiz_id, to_tsquery('simple', ?) q "+
+		"WHERE d.document @@ q"+visible+" ORDER BY score DESC, d.id LIMIT ?",
+		append(append([]interface{}{strings.Join(prefixes, " & ")}, args...), limit)...).
+		Scan(&hits).Error
+	return hits, err
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"log"
+	"strings"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// sqliteSearch ranks documents with bm25 over an external content FTS5
+// table kept in sync by triggers, titles weighted above bodies. The
+// go-sqlite3 driver only includes FTS5 when built with -tags sqlite_fts5;
+// without it, search falls back to LIKE matching.
+type sqliteSearch struct {
+	searchDocuments
+	fallback bool
+}
+
+var sqliteSearchSchema = []string{
+	"CREATE VIRTUAL TABLE IF NOT EXISTS search_fts USING fts5(title, body, content='search_documents', content_rowid='id')",
+	"CREATE TRIGGER IF NOT EXISTS search_documents_ai AFTER INSERT ON search_documents BEGIN " +
+		"INSERT INTO search_fts (rowid, title, body) VALUES (new.id, new.title, new.body); END",
+	"CREATE TRIGGER IF NOT EXISTS search_documents_ad AFTER DELETE ON search_documents BEGIN " +
+		"INSERT INTO search_fts (search_fts, rowid, title, body) VALUES ('delete', old.id, old.title, old.body); END",
+	"CREATE TRIGGER IF NOT EXISTS search_documents_au AFTER UPDATE ON search_documents BEGIN " +
+		"INSERT INTO search_fts (search_fts, rowid, title, body) VALUES ('delete', old.id, old.title, old.body); " +
+		"INSERT INTO search_fts (rowid, title, body) VALUES (new.id, new.title, new.body); END",
+}
+
+// sqliteHasFTS5 reports whether the linked SQLite was compiled with FTS5.
+func sqliteHasFTS5(db *gorm.DB) bool {
+	var enabled bool
+	if err := db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled).Error; err != nil {
+		return false
+	}
+	return enabled
+}
+
+func (r *sqliteSearch) Migrate() error {
+	if r.fallback {
+		log.Printf("Search: SQLite was built without FTS5, falling back to LIKE search; build with -tags sqlite_fts5")
+		return r.fill()
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range sqliteSearchSchema {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+
+		// Documents indexed by a build without FTS5 are missing from a new
+		// index, and fill leaves them alone, so the index is rebuilt from
+		// them. Counting search_fts itself would read the content table.
+		var indexed int64
+		if err := tx.Table("search_fts_docsize").Count(&indexed).Error; err != nil {
+			return err
+		}
+		if indexed == 0 {
+			return tx.Exec("INSERT INTO search_fts (search_fts) VALUES ('rebuild')").Error
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return r.fill()
+}
+
+func (r *sqliteSearch) Search(terms []string, limit int, viewerID uint) ([]models.SearchHit, error) {
+	if r.fallback {
+		return r.searchLike(terms, limit, viewerID)
+	}
+
+	prefixes := []string{}
+	for _, t := range terms {
+		prefixes = append(prefixes, `"`+t+`"*`)
+	}
+
+	// bm25 is lower for better matches, so it is negated into a score.
+	visible, args := searchVisibility(viewerID)
+	hits := []models.SearchHit{}
+	err := r.db.Raw("SELECT "+hitColumns+", -bm25(search_fts, 10.0, 1.0) AS score "+
+		"FROM search_fts JOIN search_documents d ON d.id = search_fts.rowid JOIN quizzes ON quizzes.id = d.quiz_id "+
+		"WHERE search_fts MATCH ?"+visible+" ORDER BY score DESC, d.id LIMIT ?",
+		append(append([]interface{}{strings.Join(prefixes, " AND ")}, args...), limit)...).
+		Scan(&hits).Error
+	return hits, err
+}
+
+// searchLike matches every term anywhere in the title or body, scoring ten
+// points per term found in the title and one per term in the body.
+func (r *sqliteSearch) searchLike(terms []string, limit int, viewerID uint) ([]models.SearchHit, error) {
+	query := r.db.Table("search_documents d").Joins("JOIN quizzes ON quizzes.id = d.quiz_id")
+	if viewerID != 0 {
+		condition, args := visibleQuizzes(viewerID)
+		query = query.Where(condition, args...)
+	}
+	score := []string{}
+	args := []interface{}{}
+	for _, t := range terms {
+		pattern := "%" + t + "%"
+		query = query.Where("(LOWER(d.title) LIKE ? OR LOWER(d.body) LIKE ?)", pattern, pattern)
+		score = append(score, "(CASE WHEN LOWER(d.title) LIKE ? THEN 10 ELSE 0 END) + (CASE WHEN LOWER(d.body) LIKE ? THEN 1 ELSE 0 END)")
+		args = append(args, pattern, pattern)
+	}
+	query = query.Select(hitColumns+", "+strings.Join(score, " + ")+" AS score", args...)
+
+	hits := []models.SearchHit{}
+	err := query.Order("score DESC, d.id").Limit(limit).Scan(&hits).Error
+	return hits, err
+}
@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ShareRepository interface {
+	FindAccess(quizID uuid.UUID, userID uint) (*models.QuizAccess, error)
+	FindCollaborators(quizID uuid.UUID) ([]models.Collaborator, error)
+	FindShare(quizID uuid.UUID, userID uint) (*models.QuizShare, error)
+	SaveShare(share *models.QuizShare) error
+	DeleteShare(share *models.QuizShare) error
+	TransferQuiz(quizID uuid.UUID, from *uint, to uint) error
+}
+
+type shareRepository struct {
+	db *gorm.DB
+}
+
+func NewShareRepository(db *gorm.DB) ShareRepository {
+	return &shareRepository{db: db}
+}
+
+// FindAccess returns the quiz's owner and status and the user's role on
+// it. A draft revision is shared along with the quiz it belongs to.
+func (r *shareRepository) FindAccess(quizID uuid.UUID, userID uint) (*models.QuizAccess, error) {
+	quiz := &models.Quiz{}
+	err := r.db.Select("id", "owner_id", "status", "revision_of").Where("id = ?", quizID).First(quiz).Error
+	if err != nil {
+		return nil, err
+	}
+	access := &models.QuizAccess{QuizID: quiz.ID, OwnerID: quiz.OwnerID, Status: quiz.Status, RevisionOf: quiz.RevisionOf}
+	if quiz.OwnerID != nil && *quiz.OwnerID == userID {
+		access.Role = constant.QuizRoleOwner
+		return access, nil
+	}
+
+	live := quiz.ID
+	if quiz.RevisionOf != nil {
+		live = *quiz.RevisionOf
+	}
+	share, err := r.FindShare(live, userID)
+	if err == nil {
+		access.Role = share.Role
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return access, nil
+}
+
+// FindCollaborators returns the quiz's owner, then the teachers it is
+// shared with in the order they were added.
+func (r *shareRepository) FindCollaborators(quizID uuid.UUID) ([]models.Collaborator, error) {
+	collaborators := []models.Collaborator{}
+	err := r.db.Table("quizzes").
+		Select("users.id AS user_id, users.username, users.email, ? AS role", constant.QuizRoleOwner).
+		Joins("JOIN users ON users.id = quizzes.owner_id").
+		Where("quizzes.id = ?", quizID).
+		Scan(&collaborators).Error
+	if err != nil {
+		return nil, err
+	}
+
+	shared := []models.Collaborator{}
+	err = r.db.Table("quiz_shares").
+		Select("users.id AS user_id, users.username, users.email, quiz_shares.role, quiz_shares.created_at AS shared_at").
+		Joins("JOIN users ON users.id = quiz_shares.user_id").
+		Where("quiz_shares.quiz_id = ?", quizID).
+		Order("quiz_shares.created_at, quiz_shares.id").
+		Scan(&shared).Error
+	return append(collaborators, shared...), err
+}
+
+func (r *shareRepository) FindShare(quizID uuid.UUID, userID uint) (*models.QuizShare, error) {
+	share := &models.QuizShare{}
+	return share, r.db.Where("quiz_id = ? AND user_id = ?", quizID, userID).First(share).Error
+}
+
+func (r *shareRepository) SaveShare(share *models.QuizShare) error {
+	return r.db.Save(share).Error
+}
+
+func (r *shareRepository) DeleteShare(share *models.QuizShare) error {
+	return r.db.Delete(share).Error
+}
+
+// TransferQuiz makes to the owner of the quiz and its draft revision. Their
+// share is dropped, and the previous owner, if any, stays on as an editor.
+func (r *shareRepository) TransferQuiz(quizID uuid.UUID, from *uint, to uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := setOwner(tx, quizID, to); err != nil {
+			return err
+		}
+		if from == nil || *from == to {
+			return nil
+		}
+		return tx.Create(&models.QuizShare{QuizID: quizID, UserID: *from, Role: constant.QuizRoleEditor}).Error
+	})
+}
+
+// setOwner makes the user the owner of the quiz and its draft revision,
+// dropping their share of it.
+func setOwner(tx *gorm.DB, quizID uuid.UUID, userID uint) error {
+	err := tx.Model(&models.Quiz{}).Where("id = ? OR revision_of = ?", quizID, quizID).Update("owner_id", userID).Error
+	if err != nil {
+		return err
+	}
+	return tx.Where("quiz_id = ? AND user_id = ?", quizID, userID).Delete(&models.QuizShare{}).Error
+}
+
+// transferQuizzes hands the quizzes owned by a user who is being deleted
+// to the editor they were first shared with or, without one, to the first
+// admin, and drops the user's shares. Only when there is no admin either
+// is a quiz left without an owner, for admins to take over later.
+func transferQuizzes(tx *gorm.DB, userID uint) error {
+	var quizIDs []uuid.UUID
+	err := tx.Model(&models.Quiz{}).Where("owner_id = ? AND revision_of IS NULL", userID).Pluck("id", &quizIDs).Error
+	if err != nil {
+		return err
+	}
+
+	var admins []uint
+	err = tx.Model(&models.User{}).Where("role_id = ? AND id <> ?", constant.RoleAdminID, userID).Order("id").Limit(1).Pluck("id", &admins).Error
+	if err != nil {
+		return err
+	}
+
+	for _, quizID := range quizIDs {
+		var editors []uint
+		err := tx.Model(&models.QuizShare{}).
+			Where("quiz_id = ? AND role = ?", quizID, constant.QuizRoleEditor).
+			Order("created_at, id").Limit(1).Pluck("user_id", &editors).Error
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(editors) > 0:
+			err = setOwner(tx, quizID, editors[0])
+		case len(admins) > 0:
+			err = setOwner(tx, quizID, admins[0])
+		default:
+			err = tx.Model(&models.Quiz{}).Where("id = ? OR revision_of = ?", quizID, quizID).Update("owner_id", nil).Error
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Where("user_id = ?", userID).Delete(&models.QuizShare{}).Error
+}
+
+// visibleQuizzes is the condition on the quizzes table that keeps the
+// quizzes a teacher may see: their own and those shared with them, and
+// those past the draft stage, which every teacher may review, assign or
+// copy.
+func visibleQuizzes(userID uint) (string, []interface{}) {
+	condition := "(quizzes.owner_id = ? OR quizzes.status IN ? OR " +
+		"COALESCE(quizzes.revision_of, quizzes.id) IN (SELECT quiz_id FROM quiz_shares WHERE user_id = ?))"
+	public := []string{constant.QuizInReview, constant.QuizPublished, constant.QuizArchived}
+	return condition, []interface{}{userID, public, userID}
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"strings"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type TagRepository interface {
+	FindOrCreateTags(names []string) ([]models.Tag, error)
+	FindTagByID(id uint) (*models.Tag, error)
+	SearchTags(prefix string, limit int) ([]models.TagUsage, error)
+	UpdateTag(tag *models.Tag) error
+	MergeTag(source, target *models.Tag) error
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// FindOrCreateTags returns the tags with the given normalized names,
+// creating those that don't exist yet.
+func (r *tagRepository) FindOrCreateTags(names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	for _, name := range names {
+		tag := models.Tag{}
+		if err := r.db.Where(models.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) FindTagByID(id uint) (*models.Tag, error) {
+	tag := &models.Tag{}
+	return tag, r.db.Where("id = ?", id).First(tag).Error
+}
+
+// SearchTags returns the tags starting with prefix, most used first.
+func (r *tagRepository) SearchTags(prefix string, limit int) ([]models.TagUsage, error) {
+	escaped := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(prefix)
+
+	quizzes := "(SELECT COUNT(*) FROM quiz_tags WHERE quiz_tags.tag_id = tags.id)"
+	questions := "(SELECT COUNT(*) FROM question_tags WHERE question_tags.tag_id = tags.id)"
+
+	// Postgres can't refer to the column aliases inside an ORDER BY
+	// expression, so the counts are spelled out again.
+	tags := []models.TagUsage{}
+	err := r.db.Model(&models.Tag{}).
+		Select("tags.id, tags.name, "+quizzes+" AS quizzes, "+questions+" AS questions").
+		Where("tags.name LIKE ? ESCAPE '!'", escaped+"%").
+		Order(quizzes + " + " + questions + " DESC, tags.name").
+		Limit(limit).
+		Scan(&tags).Error
+	return tags, err
+}
+
+func (r *tagRepository) UpdateTag(tag *models.Tag) error {
+	return r.db.Model(tag).Select("name", "updated_at").Updates(tag).Error
+}
+
+// MergeTag moves the source tag's quizzes and questions to the target,
+// skipping those that already have it, and deletes the source.
+func (r *tagRepository) MergeTag(source, target *models.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("INSERT INTO quiz_tags (quiz_id, tag_id) SELECT quiz_id, ? FROM quiz_tags WHERE tag_id = ? "+
+			"AND quiz_id NOT IN (SELECT quiz_id FROM quiz_tags WHERE tag_id = ?)", target.ID, source.ID, target.ID).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec("INSERT INTO question_tags (question_id, tag_id) SELECT question_id, ? FROM question_tags WHERE tag_id = ? "+
+			"AND question_id NOT IN (SELECT question_id FROM question_tags WHERE tag_id = ?)", target.ID, source.ID, target.ID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM quiz_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM question_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(source).Error
+	})
+}
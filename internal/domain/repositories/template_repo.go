@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type TemplateRepository interface {
+	CreateTemplate(template *models.QuizTemplate) error
+	UpdateTemplate(template *models.QuizTemplate) error
+	DeleteTemplate(template *models.QuizTemplate) error
+	FindTemplateByID(id uint) (*models.QuizTemplate, error)
+	FindTemplates(categoryIDs []uint) ([]models.QuizTemplate, error)
+}
+
+type templateRepository struct {
+	db *gorm.DB
+}
+
+func NewTemplateRepository(db *gorm.DB) TemplateRepository {
+	return &templateRepository{db: db}
+}
+
+func (r *templateRepository) CreateTemplate(template *models.QuizTemplate) error {
+	return r.db.Create(template).Error
+}
+
+func (r *templateRepository) UpdateTemplate(template *models.QuizTemplate) error {
+	return r.db.Save(template).Error
+}
+
+func (r *templateRepository) DeleteTemplate(template *models.QuizTemplate) error {
+	return r.db.Delete(template).Error
+}
+
+func (r *templateRepository) FindTemplateByID(id uint) (*models.QuizTemplate, error) {
+	template := &models.QuizTemplate{}
+	return template, r.db.Where("id = ?", id).First(template).Error
+}
+
+// FindTemplates returns the templates by name, without their content, only
+// those in categoryIDs unless it is nil.
+func (r *templateRepository) FindTemplates(categoryIDs []uint) ([]models.QuizTemplate, error) {
+	templates := []models.QuizTemplate{}
+	query := r.db.Omit("content").Order("name, id")
+	if categoryIDs != nil {
+		query = query.Where("category_id IN ?", categoryIDs)
+	}
+	return templates, query.Find(&templates).Error
+}
@@ -2,8 +2,10 @@ package repositories
 
 import (
 	"errors"
+	"time"
 
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
 	"gorm.io/gorm"
 )
 
@@ -16,6 +18,12 @@ type UserRepository interface {
 	FindUserByEmail(email string) (*models.User, error)
 	FindAllUsers() ([]models.User, error)
 	FindUserByRoleID(id uint) ([]models.User, error)
+	FindDeletedUsers() ([]models.User, error)
+	FindDeletedUserByID(id uint) (*models.User, error)
+	RestoreUser(user *models.User) error
+	PurgeUser(user *models.User) error
+	FindDeletedUsersBefore(before time.Time) ([]models.User, error)
+	CountActiveUsersByUsernameOrEmail(username, email string) (int64, error)
 }
 
 type userRepository struct {
@@ -42,17 +50,20 @@ func (ur *userRepository) UpdateUser(user *models.User) (*models.User, error) {
 	return user, nil
 }
 
+// DeleteUser soft-deletes the user and hands the quizzes they own on to
+// another teacher, so none is left with an owner who can't sign in.
 func (ur *userRepository) DeleteUser(user *models.User) error {
-	err := ur.DB.Delete(user).Error
-	if err != nil {
-		return err
-	}
-	return nil
+	return ur.DB.Transaction(func(tx *gorm.DB) error {
+		if err := transferQuizzes(tx, user.ID); err != nil {
+			return err
+		}
+		return tx.Delete(user).Error
+	})
 }
 
 func (ur *userRepository) FindUserByID(id uint) (*models.User, error) {
 	var user models.User
-	
+
 	err := ur.DB.Preload("Role").Where("id = ?", id).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -66,7 +77,7 @@ func (ur *userRepository) FindUserByID(id uint) (*models.User, error) {
 
 func (ur *userRepository) FindUserByUsername(username string) (*models.User, error) {
 	user := &models.User{}
-	err := ur.DB.Where("username = ?", username).First(user).Error
+	err := ur.DB.Preload("Role").Where("username = ?", username).First(user).Error
 	if err != nil {
 		return nil, err
 	}
@@ -99,3 +110,98 @@ func (ur *userRepository) FindUserByRoleID(id uint) ([]models.User, error) {
 	}
 	return users, nil
 }
+
+func (ur *userRepository) FindDeletedUsers() ([]models.User, error) {
+	users := []models.User{}
+	err := ur.DB.Unscoped().Preload("Role").Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (ur *userRepository) FindDeletedUserByID(id uint) (*models.User, error) {
+	user := &models.User{}
+	err := ur.DB.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(user).Error
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (ur *userRepository) RestoreUser(user *models.User) error {
+	return ur.DB.Unscoped().Model(user).Updates(map[string]interface{}{
+		"username":   user.Username,
+		"email":      user.Email,
+		"deleted_at": nil,
+	}).Error
+}
+
+// PurgeUser permanently deletes the user along with their profile, any
+// pending email change, their classroom enrolments and the assignments
+// targeted at them. Quiz attempts are kept for statistics but detached from
+// the account and marked anonymized, the quizzes the user owns are handed on
+// to another teacher, and their classrooms and assignments to an admin.
+func (ur *userRepository) PurgeUser(user *models.User) error {
+	return ur.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Participant{}).Where("user_id = ?", user.ID).Updates(map[string]interface{}{
+			"user_id":    0,
+			"anonymized": true,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.Profile{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.EmailChange{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.ReviewCard{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.ClassroomMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.AssignmentUser{}).Error; err != nil {
+			return err
+		}
+		if err := transferQuizzes(tx, user.ID); err != nil {
+			return err
+		}
+		if err := transferTeaching(tx, user.ID); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(user).Error
+	})
+}
+
+// transferTeaching hands the classrooms and assignments the user created,
+// trashed ones included, on to the first admin. With no admin left they keep
+// pointing at the user.
+func transferTeaching(tx *gorm.DB, userID uint) error {
+	var admins []uint
+	err := tx.Model(&models.User{}).Where("role_id = ? AND id <> ?", constant.RoleAdminID, userID).Order("id").Limit(1).Pluck("id", &admins).Error
+	if err != nil || len(admins) == 0 {
+		return err
+	}
+	if err := tx.Unscoped().Model(&models.Classroom{}).Where("teacher_id = ?", userID).Update("teacher_id", admins[0]).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Model(&models.Assignment{}).Where("created_by = ?", userID).Update("created_by", admins[0]).Error
+}
+
+func (ur *userRepository) FindDeletedUsersBefore(before time.Time) ([]models.User, error) {
+	users := []models.User{}
+	err := ur.DB.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (ur *userRepository) CountActiveUsersByUsernameOrEmail(username, email string) (int64, error) {
+	var count int64
+	err := ur.DB.Model(&models.User{}).Where("username = ? OR email = ?", username, email).Count(&count).Error
+	return count, err
+}
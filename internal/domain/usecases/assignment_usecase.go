@@ -0,0 +1,366 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"gorm.io/gorm"
+)
+
+// ErrAssignmentNotFound is also returned for assignments the actor may not
+// see, so their existence isn't revealed.
+var ErrAssignmentNotFound = apperror.NotFound("assignment not found")
+
+type AssignmentUsecase interface {
+	CreateAssignment(actor models.Actor, req *models.AssignmentRequest) (*models.Assignment, error)
+	UpdateAssignment(actor models.Actor, id uint, req *models.AssignmentRequest) (*models.Assignment, error)
+	DeleteAssignment(actor models.Actor, id uint) (*models.Assignment, error)
+	GetAssignment(actor models.Actor, id uint) (*models.Assignment, error)
+	GetAssignments(actor models.Actor) ([]models.Assignment, error)
+	GetGrades(actor models.Actor, id uint) ([]models.StudentGrade, error)
+	GetDueAssignments(userID uint) ([]models.DueAssignment, error)
+}
+
+type assignmentUsecase struct {
+	assignmentRepo repositories.AssignmentRepository
+	attemptRepo    repositories.AttemptRepository
+	classroomRepo  repositories.ClassroomRepository
+	quizRepo       repositories.QuizRepository
+	userRepo       repositories.UserRepository
+}
+
+func NewAssignmentUsecase(
+	assignmentRepo repositories.AssignmentRepository,
+	attemptRepo repositories.AttemptRepository,
+	classroomRepo repositories.ClassroomRepository,
+	quizRepo repositories.QuizRepository,
+	userRepo repositories.UserRepository,
+) AssignmentUsecase {
+	return &assignmentUsecase{
+		assignmentRepo: assignmentRepo,
+		attemptRepo:    attemptRepo,
+		classroomRepo:  classroomRepo,
+		quizRepo:       quizRepo,
+		userRepo:       userRepo,
+	}
+}
+
+func (u *assignmentUsecase) CreateAssignment(actor models.Actor, req *models.AssignmentRequest) (*models.Assignment, error) {
+	assignment := &models.Assignment{CreatedBy: actor.ID}
+	if err := u.apply(actor, assignment, req); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.assignmentRepo.CreateAssignment(assignment); err != nil {
+		return nil, dbError(err, "assignment")
+	}
+	return u.GetAssignment(actor, assignment.ID)
+}
+
+func (u *assignmentUsecase) UpdateAssignment(actor models.Actor, id uint, req *models.AssignmentRequest) (*models.Assignment, error) {
+	assignment, err := u.GetAssignment(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.apply(actor, assignment, req); err != nil {
+		return nil, err
+	}
+	assignment.UpdatedAt = time.Now()
+
+	if _, err := u.assignmentRepo.UpdateAssignment(assignment, true); err != nil {
+		return nil, dbError(err, "assignment")
+	}
+	return u.GetAssignment(actor, id)
+}
+
+func (u *assignmentUsecase) DeleteAssignment(actor models.Actor, id uint) (*models.Assignment, error) {
+	assignment, err := u.GetAssignment(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	return assignment, dbError(u.assignmentRepo.DeleteAssignment(assignment), "assignment")
+}
+
+// GetAssignment returns the assignment if the actor created it or is an admin.
+func (u *assignmentUsecase) GetAssignment(actor models.Actor, id uint) (*models.Assignment, error) {
+	assignment, err := u.assignmentRepo.FindAssignmentByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAssignmentNotFound
+	}
+	if err != nil {
+		return nil, dbError(err, "assignment")
+	}
+
+	if !actor.IsAdmin() && assignment.CreatedBy != actor.ID {
+		return nil, ErrAssignmentNotFound
+	}
+
+	fillUserIDs(assignment)
+	return assignment, nil
+}
+
+func (u *assignmentUsecase) GetAssignments(actor models.Actor) ([]models.Assignment, error) {
+	creator := actor.ID
+	if actor.IsAdmin() {
+		creator = 0
+	}
+
+	assignments, err := u.assignmentRepo.FindAssignmentsByCreator(creator)
+	if err != nil {
+		return nil, dbError(err, "assignment")
+	}
+	for i := range assignments {
+		fillUserIDs(&assignments[i])
+	}
+	return assignments, nil
+}
+
+// GetGrades lists every targeted student with their attempt count and the
+// grade their finished attempts earn under the scoring policy.
+func (u *assignmentUsecase) GetGrades(actor models.Actor, id uint) ([]models.StudentGrade, error) {
+	assignment, err := u.GetAssignment(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := u.assignmentRepo.FindAssignees(assignment)
+	if err != nil {
+		return nil, dbError(err, "assignment")
+	}
+	attempts, err := u.attemptRepo.FindAssignmentAttempts(assignment.ID, 0)
+	if err != nil {
+		return nil, dbError(err, "attempt")
+	}
+
+	byUser := map[uint][]models.Participant{}
+	for _, a := range attempts {
+		byUser[a.UserID] = append(byUser[a.UserID], a)
+	}
+
+	grades := []models.StudentGrade{}
+	for _, s := range students {
+		grades = append(grades, models.StudentGrade{
+			UserID:   s.ID,
+			Username: s.Username,
+			Attempts: len(byUser[s.ID]),
+			Grade:    AssignmentGrade(assignment.ScoringPolicy, byUser[s.ID]),
+		})
+	}
+	return grades, nil
+}
+
+// GetDueAssignments lists the student's assignments that are upcoming or
+// still accept attempts and have attempts left, soonest deadline first. The
+// grade is left out while the quiz does not release scores.
+func (u *assignmentUsecase) GetDueAssignments(userID uint) ([]models.DueAssignment, error) {
+	assignments, err := u.assignmentRepo.FindAssignmentsForStudent(userID)
+	if err != nil {
+		return nil, dbError(err, "assignment")
+	}
+
+	now := time.Now()
+	due := []models.DueAssignment{}
+	for _, a := range assignments {
+		status := assignmentStatus(&a, now)
+		if status == "" {
+			continue
+		}
+
+		attempts, err := u.attemptRepo.FindAssignmentAttempts(a.ID, userID)
+		if err != nil {
+			return nil, dbError(err, "attempt")
+		}
+		if a.MaxAttempts > 0 && len(attempts) >= a.MaxAttempts && !hasUnfinished(attempts) {
+			continue
+		}
+
+		quiz, err := u.quizRepo.FindQuizByID(a.QuizID)
+		if err != nil {
+			return nil, dbError(err, "quiz")
+		}
+		if quiz.Status != constant.QuizPublished {
+			continue
+		}
+		var grade *int
+		if policyReleased(quiz.ReleaseScore, a.Deadline(), now) {
+			grade = AssignmentGrade(a.ScoringPolicy, attempts)
+		}
+
+		due = append(due, models.DueAssignment{
+			ID:            a.ID,
+			QuizID:        a.QuizID,
+			Title:         a.Title,
+			Status:        status,
+			OpensAt:       a.OpensAt,
+			ClosesAt:      a.ClosesAt,
+			LateUntil:     a.LateUntil,
+			LatePenalty:   a.LatePenalty,
+			MaxAttempts:   a.MaxAttempts,
+			AttemptsUsed:  len(attempts),
+			ScoringPolicy: a.ScoringPolicy,
+			Grade:         grade,
+		})
+	}
+	return due, nil
+}
+
+// apply validates req and copies it onto assignment.
+func (u *assignmentUsecase) apply(actor models.Actor, assignment *models.Assignment, req *models.AssignmentRequest) error {
+	quiz, err := u.quizRepo.FindQuizByID(req.QuizID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.Validation("quiz does not exist", apperror.FieldError{Field: "quiz_id", Rule: "exists", Message: "does not match an existing quiz"})
+	}
+	if err != nil {
+		return dbError(err, "quiz")
+	}
+	if quiz.Status != constant.QuizPublished {
+		return ErrQuizUnpublished
+	}
+
+	if err := validateSchedule(req); err != nil {
+		return err
+	}
+
+	assignment.Users = nil
+	switch {
+	case req.ClassroomID != nil && len(req.UserIDs) > 0, req.ClassroomID == nil && len(req.UserIDs) == 0:
+		return apperror.Validation("assign to either a classroom or a list of students", apperror.FieldError{
+			Field:   "classroom_id",
+			Rule:    "xor",
+			Message: "exactly one of classroom_id and user_ids is required",
+		})
+	case req.ClassroomID != nil:
+		classroom, err := u.classroomRepo.FindClassroomByID(*req.ClassroomID)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !actor.IsAdmin() && classroom.TeacherID != actor.ID) {
+			return ErrClassroomNotFound
+		}
+		if err != nil {
+			return dbError(err, "classroom")
+		}
+	default:
+		seen := map[uint]bool{}
+		for _, id := range req.UserIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			user, err := u.userRepo.FindUserByID(id)
+			if err != nil {
+				return dbError(err, "user")
+			}
+			if user == nil || user.RoleID != constant.RoleStudentID {
+				return apperror.Validation("user_ids must reference students", apperror.FieldError{
+					Field:   "user_ids",
+					Rule:    "student",
+					Message: fmt.Sprintf("user %d is not a student", id),
+				})
+			}
+			assignment.Users = append(assignment.Users, models.AssignmentUser{UserID: id})
+		}
+	}
+
+	assignment.QuizID = req.QuizID
+	assignment.ClassroomID = req.ClassroomID
+	assignment.Title = req.Title
+	if assignment.Title == "" {
+		assignment.Title = quiz.Title
+	}
+	assignment.OpensAt = req.OpensAt
+	assignment.ClosesAt = req.ClosesAt
+	assignment.LateUntil = req.LateUntil
+	assignment.LatePenalty = req.LatePenalty
+	assignment.MaxAttempts = req.MaxAttempts
+	assignment.ScoringPolicy = req.ScoringPolicy
+	if assignment.ScoringPolicy == "" {
+		assignment.ScoringPolicy = constant.ScoringBest
+	}
+	return nil
+}
+
+func validateSchedule(req *models.AssignmentRequest) error {
+	var fields []apperror.FieldError
+	if req.OpensAt != nil && req.ClosesAt != nil && !req.ClosesAt.After(*req.OpensAt) {
+		fields = append(fields, apperror.FieldError{Field: "closes_at", Rule: "after", Message: "must be after opens_at"})
+	}
+	if req.LateUntil != nil {
+		if req.ClosesAt == nil {
+			fields = append(fields, apperror.FieldError{Field: "late_until", Rule: "requires", Message: "requires closes_at"})
+		} else if !req.LateUntil.After(*req.ClosesAt) {
+			fields = append(fields, apperror.FieldError{Field: "late_until", Rule: "after", Message: "must be after closes_at"})
+		}
+	}
+	if len(fields) > 0 {
+		return apperror.Validation("invalid assignment schedule", fields...)
+	}
+	return nil
+}
+
+// assignmentStatus returns the due-list status at now, or "" once no more
+// attempts are accepted.
+func assignmentStatus(a *models.Assignment, now time.Time) string {
+	switch {
+	case a.OpensAt != nil && now.Before(*a.OpensAt):
+		return constant.AssignmentUpcoming
+	case a.ClosesAt == nil || !now.After(*a.ClosesAt):
+		return constant.AssignmentOpen
+	case a.LateUntil != nil && !now.After(*a.LateUntil):
+		return constant.AssignmentLate
+	default:
+		return ""
+	}
+}
+
+// AssignmentGrade combines the finished attempts' scores by policy; it is
+// nil while no attempt is finished and fully graded. Attempts must be in the
+// order taken.
+func AssignmentGrade(policy string, attempts []models.Participant) *int {
+	var scores []int
+	for _, a := range attempts {
+		if a.Finished && !a.GradingPending {
+			scores = append(scores, a.Score)
+		}
+	}
+	if len(scores) == 0 {
+		return nil
+	}
+
+	grade := scores[len(scores)-1]
+	switch policy {
+	case constant.ScoringBest:
+		for _, s := range scores {
+			if s > grade {
+				grade = s
+			}
+		}
+	case constant.ScoringAverage:
+		sum := 0
+		for _, s := range scores {
+			sum += s
+		}
+		grade = (sum + len(scores)/2) / len(scores)
+	}
+	return &grade
+}
+
+func hasUnfinished(attempts []models.Participant) bool {
+	for _, a := range attempts {
+		if !a.Finished {
+			return true
+		}
+	}
+	return false
+}
+
+func fillUserIDs(assignment *models.Assignment) {
+	assignment.UserIDs = nil
+	for _, au := range assignment.Users {
+		assignment.UserIDs = append(assignment.UserIDs, au.UserID)
+	}
+}
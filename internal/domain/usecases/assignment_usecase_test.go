@@ -0,0 +1,89 @@
+package usecases
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+)
+
+func TestAssignmentStatus(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		when := now.Add(d)
+		return &when
+	}
+
+	tests := []struct {
+		name       string
+		assignment models.Assignment
+		want       string
+	}{
+		{"no dates", models.Assignment{}, constant.AssignmentOpen},
+		{"not yet open", models.Assignment{OpensAt: at(time.Hour)}, constant.AssignmentUpcoming},
+		{"opens now", models.Assignment{OpensAt: at(0)}, constant.AssignmentOpen},
+		{"before close", models.Assignment{OpensAt: at(-time.Hour), ClosesAt: at(time.Hour)}, constant.AssignmentOpen},
+		{"closes now", models.Assignment{ClosesAt: at(0)}, constant.AssignmentOpen},
+		{"closed without late window", models.Assignment{ClosesAt: at(-time.Second)}, ""},
+		{"in late window", models.Assignment{ClosesAt: at(-time.Hour), LateUntil: at(time.Hour)}, constant.AssignmentLate},
+		{"late window ends now", models.Assignment{ClosesAt: at(-time.Hour), LateUntil: at(0)}, constant.AssignmentLate},
+		{"after late window", models.Assignment{ClosesAt: at(-2 * time.Hour), LateUntil: at(-time.Hour)}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := assignmentStatus(&tt.assignment, now); got != tt.want {
+				t.Errorf("assignmentStatus = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignmentGrade(t *testing.T) {
+	done := func(score int) models.Participant {
+		return models.Participant{Finished: true, Score: score}
+	}
+	pending := models.Participant{Finished: true, Score: 100, GradingPending: true}
+	unfinished := models.Participant{Score: 100}
+
+	tests := []struct {
+		name     string
+		policy   string
+		attempts []models.Participant
+		want     *int
+	}{
+		{"no attempts", constant.ScoringBest, nil, nil},
+		{"only unfinished", constant.ScoringBest, []models.Participant{unfinished}, nil},
+		{"only pending grading", constant.ScoringLast, []models.Participant{pending}, nil},
+		{"best", constant.ScoringBest, []models.Participant{done(40), done(90), done(70)}, intPtr(90)},
+		{"last", constant.ScoringLast, []models.Participant{done(40), done(90), done(70)}, intPtr(70)},
+		{"average", constant.ScoringAverage, []models.Participant{done(40), done(90), done(70)}, intPtr(67)},
+		{"average rounds half up", constant.ScoringAverage, []models.Participant{done(50), done(51)}, intPtr(51)},
+		{"last skips unfinished and pending", constant.ScoringLast, []models.Participant{done(40), pending, unfinished}, intPtr(40)},
+		{"best skips pending", constant.ScoringBest, []models.Participant{done(40), pending}, intPtr(40)},
+		{"average skips unfinished", constant.ScoringAverage, []models.Participant{done(20), unfinished, done(60)}, intPtr(40)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AssignmentGrade(tt.policy, tt.attempts)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || *got != *tt.want:
+				t.Errorf("AssignmentGrade = %v, want %v", fmtGrade(got), fmtGrade(tt.want))
+			}
+		})
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func fmtGrade(grade *int) interface{} {
+	if grade == nil {
+		return nil
+	}
+	return *grade
+}
@@ -0,0 +1,248 @@
+package usecases
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/media"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/storage"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAttachmentNotFound = apperror.NotFound("attachment not found")
+
+	// ErrQuizLive is returned when changing the attachments of a published
+	// quiz directly rather than through its draft revision.
+	ErrQuizLive = apperror.Conflict("published quizzes are changed through their draft revision")
+)
+
+type AttachmentUsecase interface {
+	UploadAttachment(quizID, questionID uuid.UUID, upload *models.AttachmentUpload) (*models.Attachment, error)
+	DeleteAttachment(quizID, id uuid.UUID) (*models.Attachment, error)
+}
+
+type attachmentUsecase struct {
+	attachmentRepo repositories.AttachmentRepository
+	quizRepo       repositories.QuizRepository
+	store          storage.Storage
+	maxSize        int64
+}
+
+// NewAttachmentUsecase returns the attachment usecase, accepting files of
+// up to maxSize bytes.
+func NewAttachmentUsecase(attachmentRepo repositories.AttachmentRepository, quizRepo repositories.QuizRepository, store storage.Storage, maxSize int64) AttachmentUsecase {
+	return &attachmentUsecase{
+		attachmentRepo: attachmentRepo,
+		quizRepo:       quizRepo,
+		store:          store,
+		maxSize:        maxSize,
+	}
+}
+
+// UploadAttachment stores an image or audio file for a question, or for
+// one of its options. The type is sniffed from the content; images also
+// get a thumbnail. Attachments change like the question itself: on
+// drafts and revisions, and only while the quiz has no attempts.
+func (u *attachmentUsecase) UploadAttachment(quizID, questionID uuid.UUID, upload *models.AttachmentUpload) (*models.Attachment, error) {
+	quiz, err := u.editableQuiz(quizID)
+	if err != nil {
+		return nil, err
+	}
+	question := findQuestion(quiz, questionID)
+	if question == nil {
+		return nil, apperror.NotFound("question not found")
+	}
+	if upload.OptionID != nil && !hasOption(question, *upload.OptionID) {
+		return nil, apperror.Validation("option does not belong to the question", apperror.FieldError{Field: "option_id", Rule: "exists", Message: "is not an option of this question"})
+	}
+	if upload.Size > u.maxSize {
+		return nil, fileError("max", fmt.Sprintf("must be at most %d MB", u.maxSize>>20))
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(upload.File, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, apperror.Internal(err)
+	}
+	fileType, ok := media.Detect(head[:n])
+	if !ok {
+		return nil, fileError("mimetype", "must be one of "+strings.Join(media.Accepted(), ", "))
+	}
+
+	attachment := &models.Attachment{
+		ID:          uuid.New(),
+		QuestionID:  question.ID,
+		OptionID:    upload.OptionID,
+		Kind:        fileType.Kind,
+		ContentType: fileType.MIME,
+		Filename:    upload.Filename,
+		Size:        upload.Size,
+	}
+	attachment.StorageKey = "attachments/" + attachment.ID.String() + "/original" + fileType.Ext
+
+	var thumbnail []byte
+	if fileType.Kind == media.KindImage {
+		if _, err := upload.File.Seek(0, io.SeekStart); err != nil {
+			return nil, apperror.Internal(err)
+		}
+		thumbnail, attachment.Width, attachment.Height, err = media.Thumbnail(upload.File, media.ThumbnailSize)
+		if errors.Is(err, media.ErrTooLarge) {
+			return nil, fileError("dimensions", fmt.Sprintf("must have at most %d pixels", media.MaxImagePixels))
+		}
+		if err != nil {
+			return nil, fileError("image", "is not a readable image")
+		}
+		attachment.ThumbnailKey = "attachments/" + attachment.ID.String() + "/thumbnail.png"
+	}
+
+	if _, err := upload.File.Seek(0, io.SeekStart); err != nil {
+		return nil, apperror.Internal(err)
+	}
+	if err := u.store.Put(attachment.StorageKey, upload.File, upload.Size, attachment.ContentType); err != nil {
+		return nil, apperror.Internal(err)
+	}
+	if thumbnail != nil {
+		if err := u.store.Put(attachment.ThumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/png"); err != nil {
+			u.removeFiles(attachment)
+			return nil, apperror.Internal(err)
+		}
+	}
+	if err := u.attachmentRepo.CreateAttachment(attachment); err != nil {
+		u.removeFiles(attachment)
+		return nil, dbError(err, "attachment")
+	}
+
+	if err := u.touch(quiz); err != nil {
+		return nil, err
+	}
+	signAttachment(u.store, attachment)
+	return attachment, nil
+}
+
+// DeleteAttachment removes an attachment from the quiz. Its files are
+// deleted unless copies or versions of the question still use them.
+func (u *attachmentUsecase) DeleteAttachment(quizID, id uuid.UUID) (*models.Attachment, error) {
+	quiz, err := u.editableQuiz(quizID)
+	if err != nil {
+		return nil, err
+	}
+	attachment, err := u.attachmentRepo.FindAttachmentByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && findQuestion(quiz, attachment.QuestionID) == nil) {
+		return nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, dbError(err, "attachment")
+	}
+
+	if err := u.attachmentRepo.DeleteAttachment(attachment); err != nil {
+		return nil, dbError(err, "attachment")
+	}
+	u.removeFiles(attachment)
+
+	if err := u.touch(quiz); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// editableQuiz loads a quiz whose questions may change.
+func (u *attachmentUsecase) editableQuiz(id uuid.UUID) (*models.Quiz, error) {
+	quiz, err := u.quizRepo.FindQuizByID(id)
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	switch quiz.Status {
+	case constant.QuizPublished:
+		return nil, ErrQuizLive
+	case constant.QuizArchived:
+		return nil, ErrQuizArchived
+	}
+
+	// A revision's changes only reach attempts through a new version.
+	if quiz.RevisionOf != nil {
+		return quiz, nil
+	}
+	attempts, err := u.quizRepo.CountParticipantsByQuizID(quiz.ID)
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	if attempts > 0 {
+		return nil, ErrQuizHasAttempts
+	}
+	return quiz, nil
+}
+
+// touch records that the quiz's questions changed, taking a quiz in review
+// back to draft like any other edit.
+func (u *attachmentUsecase) touch(quiz *models.Quiz) error {
+	if quiz.Status == constant.QuizInReview {
+		quiz.Status = constant.QuizDraft
+		quiz.SubmittedBy = nil
+	}
+	quiz.QuestionsChanged = quiz.RevisionOf != nil
+	quiz.UpdatedAt = time.Now()
+	_, err := u.quizRepo.UpdateQuiz(quiz)
+	return dbError(err, "quiz")
+}
+
+// removeFiles deletes the attachment's stored files that nothing else
+// uses. Failures are only logged; the files are then merely left over.
+func (u *attachmentUsecase) removeFiles(attachment *models.Attachment) {
+	for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		refs, err := u.attachmentRepo.CountKeyReferences(key)
+		if err == nil && refs == 0 {
+			err = u.store.Delete(key)
+		}
+		if err != nil {
+			log.Printf("Storage: failed to delete %s: %v", key, err)
+		}
+	}
+}
+
+func fileError(rule, message string) error {
+	return apperror.Validation("invalid file", apperror.FieldError{Field: "file", Rule: rule, Message: message})
+}
+
+// signAttachments fills in signed URLs for the questions' attachments.
+func signAttachments(store storage.Storage, questions []models.Question) {
+	for i := range questions {
+		for j := range questions[i].Attachments {
+			signAttachment(store, &questions[i].Attachments[j])
+		}
+	}
+}
+
+// signAttachment fills in the attachment's signed URLs. Signing only fails
+// for malformed keys, which then get no URL.
+func signAttachment(store storage.Storage, attachment *models.Attachment) {
+	attachment.URL, _ = store.SignedURL(attachment.StorageKey)
+	if attachment.ThumbnailKey != "" {
+		attachment.ThumbnailURL, _ = store.SignedURL(attachment.ThumbnailKey)
+	}
+}
+
+// studentAttachments returns signed copies of the attachments without
+// their storage keys and filenames.
+func studentAttachments(store storage.Storage, attachments []models.Attachment) []models.Attachment {
+	copies := []models.Attachment{}
+	for _, a := range attachments {
+		signAttachment(store, &a)
+		a.StorageKey, a.ThumbnailKey, a.Filename = "", "", ""
+		copies = append(copies, a)
+	}
+	return copies
+}
@@ -0,0 +1,879 @@
+package usecases
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/irt"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/storage"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAttemptNotFound is also returned for other users' attempts.
+	ErrAttemptNotFound = apperror.NotFound("attempt not found")
+
+	ErrAttemptFinished    = apperror.Conflict("attempt is already finished")
+	ErrAttemptNotFinished = apperror.Conflict("attempt is not finished yet")
+	ErrAttemptExpired     = apperror.Conflict("time for this attempt is over")
+
+	ErrQuestionAnswered = apperror.Conflict("question is already answered in this practice attempt")
+	ErrNotCurrentItem   = apperror.Conflict("only the current question of an adaptive attempt can be answered")
+	ErrAdaptiveStopped  = apperror.Conflict("adaptive test is complete; finish the attempt")
+)
+
+type AttemptUsecase interface {
+	StartAssignmentAttempt(userID, assignmentID uint) (attempt *models.AttemptView, resumed bool, err error)
+	GetAttempt(userID uint, attemptID uuid.UUID) (*models.AttemptView, error)
+	GetMyAttempts(userID uint, filter models.AttemptFilter) ([]models.AttemptSummary, int64, error)
+	StartPractice(userID uint, quizID uuid.UUID) (attempt *models.AttemptView, resumed bool, err error)
+	RetryMissed(userID uint, attemptID uuid.UUID) (*models.AttemptView, error)
+	AnswerQuestion(userID uint, attemptID uuid.UUID, req *models.AnswerRequest) (*models.AnswerFeedback, error)
+	FinishAttempt(userID uint, attemptID uuid.UUID) (*models.AttemptView, error)
+	ReviewAttempt(userID uint, attemptID uuid.UUID) (*models.AttemptReview, error)
+}
+
+type attemptUsecase struct {
+	attemptRepo    repositories.AttemptRepository
+	assignmentRepo repositories.AssignmentRepository
+	quizRepo       repositories.QuizRepository
+	store          storage.Storage
+}
+
+func NewAttemptUsecase(attemptRepo repositories.AttemptRepository, assignmentRepo repositories.AssignmentRepository, quizRepo repositories.QuizRepository, store storage.Storage) AttemptUsecase {
+	return &attemptUsecase{
+		attemptRepo:    attemptRepo,
+		assignmentRepo: assignmentRepo,
+		quizRepo:       quizRepo,
+		store:          store,
+	}
+}
+
+// StartAssignmentAttempt starts an attempt at an assignment, enforcing its
+// window and attempt limit. An attempt already in progress is resumed
+// instead, with resumed set.
+func (u *attemptUsecase) StartAssignmentAttempt(userID, assignmentID uint) (*models.AttemptView, bool, error) {
+	assignment, err := u.assignmentRepo.FindAssignmentByID(assignmentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, ErrAssignmentNotFound
+	}
+	if err != nil {
+		return nil, false, dbError(err, "assignment")
+	}
+
+	assigned, err := u.assignmentRepo.IsAssignedTo(assignment, userID)
+	if err != nil {
+		return nil, false, dbError(err, "assignment")
+	}
+	if !assigned {
+		return nil, false, ErrAssignmentNotFound
+	}
+
+	unfinished, err := u.attemptRepo.FindUnfinishedAttempt(assignment.ID, userID)
+	if err != nil {
+		return nil, false, dbError(err, "attempt")
+	}
+	if unfinished != nil {
+		view, err := u.view(unfinished)
+		return view, true, err
+	}
+
+	now := time.Now()
+	switch assignmentStatus(assignment, now) {
+	case "":
+		return nil, false, apperror.Conflict("assignment is closed")
+	case constant.AssignmentUpcoming:
+		return nil, false, apperror.Conflict("assignment is not open yet")
+	}
+
+	if assignment.MaxAttempts > 0 {
+		attempts, err := u.attemptRepo.FindAssignmentAttempts(assignment.ID, userID)
+		if err != nil {
+			return nil, false, dbError(err, "attempt")
+		}
+		if len(attempts) >= assignment.MaxAttempts {
+			return nil, false, apperror.Conflict("no attempts left for this assignment")
+		}
+	}
+
+	quiz, err := u.quizRepo.FindQuizByID(assignment.QuizID)
+	if err != nil {
+		return nil, false, dbError(err, "quiz")
+	}
+	if quiz.Status != constant.QuizPublished {
+		return nil, false, apperror.Conflict("quiz is no longer available")
+	}
+
+	attempt := &models.Participant{
+		QuizID:       quiz.ID,
+		QuizVersion:  quiz.Version,
+		UserID:       userID,
+		AssignmentID: &assignment.ID,
+		ExpiresAt:    attemptExpiry(now, quiz.Duration, assignment.Deadline()),
+	}
+	if quiz.Adaptive {
+		first := nextAdaptiveQuestion(quiz, nil, 0)
+		if first == nil {
+			return nil, false, apperror.Conflict("quiz has no questions for adaptive testing")
+		}
+		attempt.Adaptive = true
+		attempt.CurrentQuestionID = &first.ID
+	}
+	if _, err := u.attemptRepo.CreateAttempt(attempt); err != nil {
+		return nil, false, dbError(err, "attempt")
+	}
+
+	view, err := u.view(attempt)
+	return view, false, err
+}
+
+func (u *attemptUsecase) GetAttempt(userID uint, attemptID uuid.UUID) (*models.AttemptView, error) {
+	attempt, err := u.ownAttempt(userID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	return u.view(attempt)
+}
+
+// GetMyAttempts lists a page of the student's attempts, newest first, with
+// the scores their quizzes have released.
+func (u *attemptUsecase) GetMyAttempts(userID uint, filter models.AttemptFilter) ([]models.AttemptSummary, int64, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = constant.DefaultPageSize
+	}
+	if filter.PageSize > constant.MaxPageSize {
+		filter.PageSize = constant.MaxPageSize
+	}
+
+	attempts, total, err := u.attemptRepo.FindUserAttempts(userID, filter)
+	if err != nil {
+		return nil, 0, dbError(err, "attempt")
+	}
+
+	now := time.Now()
+	quizzes := map[uuid.UUID]*models.Quiz{}
+	summaries := []models.AttemptSummary{}
+	for i := range attempts {
+		attempt := &attempts[i]
+		quiz, ok := quizzes[attempt.QuizID]
+		if !ok {
+			quiz, err = u.quizRepo.FindQuizByID(attempt.QuizID)
+			if err != nil {
+				return nil, 0, dbError(err, "quiz")
+			}
+			quizzes[attempt.QuizID] = quiz
+		}
+		release, err := u.release(attempt, quiz, now)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		summary := models.AttemptSummary{
+			ID:             attempt.ID,
+			QuizID:         attempt.QuizID,
+			QuizTitle:      quiz.Title,
+			AssignmentID:   attempt.AssignmentID,
+			Practice:       attempt.Practice,
+			Adaptive:       attempt.Adaptive,
+			Finished:       attempt.Finished,
+			GradingPending: attempt.GradingPending,
+			Late:           attempt.Late,
+			ExpiresAt:      attempt.ExpiresAt,
+			FinishedAt:     attempt.FinishedAt,
+			CreatedAt:      attempt.CreatedAt,
+		}
+		if release.Score {
+			summary.Score = &attempt.Score
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, total, nil
+}
+
+// StartPractice starts a practice attempt at the multiple choice questions
+// of a published quiz. A practice attempt at the quiz already in progress
+// is resumed instead.
+func (u *attemptUsecase) StartPractice(userID uint, quizID uuid.UUID) (*models.AttemptView, bool, error) {
+	quiz, err := u.quizRepo.FindQuizByID(quizID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, ErrQuizNotFound
+	}
+	if err != nil {
+		return nil, false, dbError(err, "quiz")
+	}
+	if quiz.Status != constant.QuizPublished {
+		return nil, false, ErrQuizNotFound
+	}
+
+	unfinished, err := u.attemptRepo.FindUnfinishedPractice(quizID, userID)
+	if err != nil {
+		return nil, false, dbError(err, "attempt")
+	}
+	if unfinished != nil {
+		view, err := u.view(unfinished)
+		return view, true, err
+	}
+
+	questionIDs := []uuid.UUID{}
+	for _, q := range quiz.Questions {
+		if q.Type != constant.QuestionEssay {
+			questionIDs = append(questionIDs, q.ID)
+		}
+	}
+	if len(questionIDs) == 0 {
+		return nil, false, apperror.Conflict("quiz has no questions to practice")
+	}
+
+	view, err := u.startPractice(userID, quiz, questionIDs)
+	return view, false, err
+}
+
+// RetryMissed starts a practice attempt at the questions the student did
+// not answer correctly in a finished practice attempt.
+func (u *attemptUsecase) RetryMissed(userID uint, attemptID uuid.UUID) (*models.AttemptView, error) {
+	previous, err := u.ownAttempt(userID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	if !previous.Practice {
+		return nil, apperror.Conflict("only practice attempts can be retried")
+	}
+	if !previous.Finished {
+		return nil, ErrAttemptNotFinished
+	}
+
+	quiz, err := u.attemptQuiz(previous)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := u.attemptRepo.FindAnswers(previous.ID)
+	if err != nil {
+		return nil, dbError(err, "answer")
+	}
+	correct := map[uuid.UUID]bool{}
+	for _, a := range answers {
+		correct[a.QuestionID] = a.Correct
+	}
+
+	missed := []uuid.UUID{}
+	for _, q := range quiz.Questions {
+		if !correct[q.ID] {
+			missed = append(missed, q.ID)
+		}
+	}
+	if len(missed) == 0 {
+		return nil, apperror.Conflict("no missed questions to retry")
+	}
+
+	return u.startPractice(userID, quiz, missed)
+}
+
+func (u *attemptUsecase) startPractice(userID uint, quiz *models.Quiz, questionIDs []uuid.UUID) (*models.AttemptView, error) {
+	attempt := &models.Participant{
+		QuizID:      quiz.ID,
+		QuizVersion: quiz.Version,
+		UserID:      userID,
+		Practice:    true,
+		QuestionIDs: questionIDs,
+	}
+	if _, err := u.attemptRepo.CreateAttempt(attempt); err != nil {
+		return nil, dbError(err, "attempt")
+	}
+	return u.view(attempt)
+}
+
+// AnswerQuestion records the chosen option or essay text, replacing an
+// earlier answer to the same question, as long as the attempt is running.
+// In a practice attempt each question is answered once and the feedback is
+// returned right away; graded attempts return no feedback.
+func (u *attemptUsecase) AnswerQuestion(userID uint, attemptID uuid.UUID, req *models.AnswerRequest) (*models.AnswerFeedback, error) {
+	attempt, err := u.ownAttempt(userID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	if attempt.Finished {
+		return nil, ErrAttemptFinished
+	}
+	if attempt.ExpiresAt != nil && time.Now().After(*attempt.ExpiresAt) {
+		return nil, ErrAttemptExpired
+	}
+
+	quiz, err := u.attemptQuiz(attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	question := findQuestion(quiz, req.QuestionID)
+	if question == nil {
+		return nil, apperror.Validation("question is not part of this quiz", apperror.FieldError{Field: "question_id", Rule: "exists", Message: "is not a question of this quiz"})
+	}
+
+	if attempt.Adaptive {
+		if attempt.CurrentQuestionID == nil {
+			return nil, ErrAdaptiveStopped
+		}
+		if *attempt.CurrentQuestionID != question.ID {
+			return nil, ErrNotCurrentItem
+		}
+	}
+	if attempt.Practice {
+		answers, err := u.attemptRepo.FindAnswers(attempt.ID)
+		if err != nil {
+			return nil, dbError(err, "answer")
+		}
+		for _, a := range answers {
+			if a.QuestionID == question.ID {
+				return nil, ErrQuestionAnswered
+			}
+		}
+	}
+
+	answer := &models.Answer{
+		ParticipantID: attempt.ID,
+		QuestionID:    question.ID,
+		AnsweredAt:    time.Now(),
+	}
+	if question.Type == constant.QuestionEssay {
+		if strings.TrimSpace(req.Text) == "" {
+			return nil, apperror.Validation("essay answer is empty", apperror.FieldError{Field: "text", Rule: "required", Message: "is required for essay questions"})
+		}
+		answer.Text = req.Text
+		answer.NeedsGrading = true
+		answer.MaxPoints = rubricPoints(question)
+	} else {
+		if !hasOption(question, req.OptionID) {
+			return nil, apperror.Validation("option does not belong to the question", apperror.FieldError{Field: "option_id", Rule: "exists", Message: "is not an option of this question"})
+		}
+		answer.OptionID = req.OptionID
+		answer.Correct = question.AnswerID == req.OptionID
+	}
+
+	if err := u.attemptRepo.SaveAnswer(answer); err != nil {
+		return nil, dbError(err, "answer")
+	}
+	if attempt.Adaptive {
+		return nil, u.advanceAdaptive(attempt)
+	}
+	if !attempt.Practice {
+		return nil, nil
+	}
+
+	feedback := &models.AnswerFeedback{
+		QuestionID:      question.ID,
+		Correct:         answer.Correct,
+		CorrectOptionID: question.AnswerID,
+		Explanation:     question.Explanation,
+		ExplanationHTML: question.ExplanationHTML,
+	}
+	for _, o := range question.Options {
+		if o.ID == answer.OptionID {
+			feedback.OptionExplanation, feedback.OptionExplanationHTML = o.Explanation, o.ExplanationHTML
+		}
+	}
+	return feedback, nil
+}
+
+// FinishAttempt scores the attempt. Submitting after the assignment closed
+// marks it late and takes the late penalty off the score; the submission
+// time is capped at the attempt's expiry. Attempts with essay answers stay
+// grading pending until a teacher has scored them all. Adaptive attempts
+// are scored by the final ability estimate. The score is only returned when
+// the quiz releases it.
+func (u *attemptUsecase) FinishAttempt(userID uint, attemptID uuid.UUID) (*models.AttemptView, error) {
+	attempt, err := u.ownAttempt(userID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	if attempt.Finished {
+		return nil, ErrAttemptFinished
+	}
+
+	quiz, err := u.attemptQuiz(attempt)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := u.attemptRepo.FindAnswers(attempt.ID)
+	if err != nil {
+		return nil, dbError(err, "answer")
+	}
+
+	submittedAt := time.Now()
+	if attempt.ExpiresAt != nil && submittedAt.After(*attempt.ExpiresAt) {
+		submittedAt = *attempt.ExpiresAt
+	}
+
+	if attempt.Adaptive {
+		theta, se := estimateAbility(quiz, answers)
+		attempt.Ability, attempt.AbilitySE = &theta, &se
+		attempt.RawScore = irt.ScorePercent(theta)
+		attempt.CurrentQuestionID = nil
+	} else {
+		attempt.RawScore, attempt.GradingPending = scoreAnswers(quiz, answers)
+	}
+	attempt.Score = attempt.RawScore
+	if attempt.AssignmentID != nil {
+		assignment, err := u.assignmentRepo.FindAssignmentByID(*attempt.AssignmentID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dbError(err, "assignment")
+		}
+		if err == nil && assignment.ClosesAt != nil && submittedAt.After(*assignment.ClosesAt) {
+			attempt.Late = true
+			attempt.Penalty = assignment.LatePenalty
+			attempt.Score = applyPenalty(attempt.RawScore, attempt.Penalty)
+		}
+	}
+
+	attempt.Finished = true
+	attempt.FinishedAt = &submittedAt
+	if _, err := u.attemptRepo.UpdateAttempt(attempt); err != nil {
+		return nil, dbError(err, "attempt")
+	}
+	return u.viewOf(attempt, quiz)
+}
+
+// ReviewAttempt shows a finished attempt with as much of its results as the
+// quiz's release policies allow at this point.
+func (u *attemptUsecase) ReviewAttempt(userID uint, attemptID uuid.UUID) (*models.AttemptReview, error) {
+	attempt, err := u.ownAttempt(userID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	if !attempt.Finished {
+		return nil, ErrAttemptNotFinished
+	}
+
+	quiz, err := u.attemptQuiz(attempt)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := u.attemptRepo.FindAnswers(attempt.ID)
+	if err != nil {
+		return nil, dbError(err, "answer")
+	}
+	release, err := u.release(attempt, quiz, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	review := &models.AttemptReview{
+		AttemptID:      attempt.ID,
+		QuizID:         quiz.ID,
+		QuizTitle:      quiz.Title,
+		AssignmentID:   attempt.AssignmentID,
+		FinishedAt:     attempt.FinishedAt,
+		Late:           attempt.Late,
+		Penalty:        attempt.Penalty,
+		GradingPending: attempt.GradingPending,
+		Release:        release,
+		Questions:      []models.ReviewQuestion{},
+	}
+	if release.Score {
+		review.Score = &attempt.Score
+		review.RawScore = &attempt.RawScore
+		review.Ability = attempt.Ability
+		review.AbilitySE = attempt.AbilitySE
+	}
+
+	given := map[uuid.UUID]models.Answer{}
+	for _, a := range answers {
+		given[a.QuestionID] = a
+	}
+	for _, q := range quiz.Questions {
+		question := models.ReviewQuestion{
+			ID:          q.ID,
+			Position:    q.Position,
+			Type:        q.Type,
+			Text:        q.Text,
+			TextHTML:    q.TextHTML,
+			Options:     []models.AttemptOption{},
+			Attachments: studentAttachments(u.store, q.Attachments),
+		}
+		for _, o := range q.Options {
+			option := attemptOption(o)
+			if release.Answers {
+				option.Explanation, option.ExplanationHTML = o.Explanation, o.ExplanationHTML
+			}
+			question.Options = append(question.Options, option)
+		}
+
+		a, answered := given[q.ID]
+		if answered {
+			if q.Type == constant.QuestionEssay {
+				question.AnswerText = a.Text
+			} else {
+				optionID := a.OptionID
+				question.SelectedOptionID = &optionID
+			}
+		}
+
+		if release.Mistakes && !(answered && a.NeedsGrading) {
+			correct := answered && a.Correct
+			question.Correct = &correct
+			if q.Type == constant.QuestionEssay {
+				points, maxPoints := a.Points, rubricPoints(&q)
+				question.Points = &points
+				question.MaxPoints = &maxPoints
+				question.Comment = a.Comment
+			}
+		}
+		if release.Answers {
+			question.Explanation, question.ExplanationHTML = q.Explanation, q.ExplanationHTML
+			if q.Type != constant.QuestionEssay {
+				answerID := q.AnswerID
+				question.CorrectOptionID = &answerID
+			}
+		}
+		review.Questions = append(review.Questions, question)
+	}
+	return review, nil
+}
+
+// attemptOption is the option as students see it, without explanations.
+func attemptOption(o models.Option) models.AttemptOption {
+	return models.AttemptOption{ID: o.ID, Position: o.Position, Text: o.Text, TextHTML: o.TextHTML}
+}
+
+func (u *attemptUsecase) release(attempt *models.Participant, quiz *models.Quiz, now time.Time) (models.ResultRelease, error) {
+	return resultRelease(u.assignmentRepo, attempt, quiz, now)
+}
+
+// resultRelease applies the quiz's release policies to a finished attempt.
+// The close date is the deadline of the attempt's assignment; without one,
+// after_close results are released as soon as the attempt is finished. A
+// score waiting for essay grading is never released. Practice results are
+// released in full, the answers having been shown along the way.
+func resultRelease(assignmentRepo repositories.AssignmentRepository, attempt *models.Participant, quiz *models.Quiz, now time.Time) (models.ResultRelease, error) {
+	release := models.ResultRelease{}
+	if !attempt.Finished {
+		return release, nil
+	}
+	if attempt.Practice {
+		return models.ResultRelease{Score: true, Mistakes: true, Answers: true}, nil
+	}
+
+	var closesAt *time.Time
+	if attempt.AssignmentID != nil {
+		assignment, err := assignmentRepo.FindAssignmentByID(*attempt.AssignmentID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return release, dbError(err, "assignment")
+		}
+		if err == nil {
+			closesAt = assignment.Deadline()
+		}
+	}
+	release.Score = policyReleased(quiz.ReleaseScore, closesAt, now) && !attempt.GradingPending
+	release.Mistakes = policyReleased(quiz.ReleaseMistakes, closesAt, now)
+	release.Answers = policyReleased(quiz.ReleaseAnswers, closesAt, now)
+
+	if closesAt != nil && !now.After(*closesAt) {
+		for _, policy := range []string{quiz.ReleaseScore, quiz.ReleaseMistakes, quiz.ReleaseAnswers} {
+			if policy == constant.ReleaseAfterClose {
+				release.ResultsAt = closesAt
+			}
+		}
+	}
+	return release, nil
+}
+
+func (u *attemptUsecase) ownAttempt(userID uint, attemptID uuid.UUID) (*models.Participant, error) {
+	attempt, err := u.attemptRepo.FindAttemptByID(attemptID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAttemptNotFound
+	}
+	if err != nil {
+		return nil, dbError(err, "attempt")
+	}
+	if attempt.UserID != userID {
+		return nil, ErrAttemptNotFound
+	}
+	return attempt, nil
+}
+
+// attemptQuiz loads the attempt's quiz with the questions of the version
+// the attempt is pinned to, narrowed down to the ones a practice attempt
+// covers, or that an adaptive attempt has served so far.
+func (u *attemptUsecase) attemptQuiz(attempt *models.Participant) (*models.Quiz, error) {
+	quiz, err := pinnedQuiz(u.quizRepo, attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	switch {
+	case attempt.Practice:
+		ids = attempt.QuestionIDs
+	case attempt.Adaptive:
+		answers, err := u.attemptRepo.FindAnswers(attempt.ID)
+		if err != nil {
+			return nil, dbError(err, "answer")
+		}
+		for _, a := range answers {
+			ids = append(ids, a.QuestionID)
+		}
+		if attempt.CurrentQuestionID != nil {
+			ids = append(ids, *attempt.CurrentQuestionID)
+		}
+	default:
+		return quiz, nil
+	}
+
+	questions := []models.Question{}
+	for _, id := range ids {
+		if q := findQuestion(quiz, id); q != nil {
+			questions = append(questions, *q)
+		}
+	}
+	quiz.Questions = questions
+	return quiz, nil
+}
+
+// pinnedQuiz loads the attempt's quiz with its questions as they were in
+// the attempt's version, when a later one has replaced it since.
+func pinnedQuiz(quizRepo repositories.QuizRepository, attempt *models.Participant) (*models.Quiz, error) {
+	quiz, err := quizRepo.FindQuizByID(attempt.QuizID)
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	return pinQuestions(quizRepo, quiz, attempt)
+}
+
+// pinQuestions returns the live quiz as is when the attempt is at its
+// version, or else a copy with the questions of the attempt's version.
+func pinQuestions(quizRepo repositories.QuizRepository, quiz *models.Quiz, attempt *models.Participant) (*models.Quiz, error) {
+	if attempt.QuizVersion == 0 || attempt.QuizVersion == quiz.Version {
+		return quiz, nil
+	}
+
+	version, err := quizRepo.FindVersion(quiz.ID, attempt.QuizVersion)
+	if err != nil {
+		return nil, dbError(err, "quiz version")
+	}
+	pinned, err := version.Quiz()
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	copied := *quiz
+	copied.Questions = pinned.Questions
+	return &copied, nil
+}
+
+func (u *attemptUsecase) view(attempt *models.Participant) (*models.AttemptView, error) {
+	quiz, err := u.attemptQuiz(attempt)
+	if err != nil {
+		return nil, err
+	}
+	return u.viewOf(attempt, quiz)
+}
+
+// viewOf builds the student's view of an attempt with their current
+// selections and without the correct answers.
+func (u *attemptUsecase) viewOf(attempt *models.Participant, quiz *models.Quiz) (*models.AttemptView, error) {
+	answers, err := u.attemptRepo.FindAnswers(attempt.ID)
+	if err != nil {
+		return nil, dbError(err, "answer")
+	}
+	release, err := u.release(attempt, quiz, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	given := map[uuid.UUID]models.Answer{}
+	for _, a := range answers {
+		given[a.QuestionID] = a
+	}
+
+	view := &models.AttemptView{
+		Participant: *attempt,
+		QuizTitle:   quiz.Title,
+		Questions:   []models.AttemptQuestion{},
+	}
+	if release.Score {
+		view.Score = &attempt.Score
+		view.RawScore = &attempt.RawScore
+		view.Ability = attempt.Ability
+		view.AbilitySE = attempt.AbilitySE
+	}
+	for _, q := range quiz.Questions {
+		question := models.AttemptQuestion{
+			ID:          q.ID,
+			Position:    q.Position,
+			Type:        q.Type,
+			Text:        q.Text,
+			TextHTML:    q.TextHTML,
+			Options:     []models.AttemptOption{},
+			Attachments: studentAttachments(u.store, q.Attachments),
+		}
+		if a, ok := given[q.ID]; ok {
+			if q.Type == constant.QuestionEssay {
+				question.AnswerText = a.Text
+			} else {
+				optionID := a.OptionID
+				question.SelectedOptionID = &optionID
+			}
+		}
+		for _, o := range q.Options {
+			question.Options = append(question.Options, attemptOption(o))
+		}
+		view.Questions = append(view.Questions, question)
+	}
+	return view, nil
+}
+
+// advanceAdaptive updates the ability estimate of an adaptive attempt after
+// an answer and serves the most informative question left, or stops the
+// test once the estimate is precise enough or the item limit is reached.
+func (u *attemptUsecase) advanceAdaptive(attempt *models.Participant) error {
+	quiz, err := pinnedQuiz(u.quizRepo, attempt)
+	if err != nil {
+		return err
+	}
+	answers, err := u.attemptRepo.FindAnswers(attempt.ID)
+	if err != nil {
+		return dbError(err, "answer")
+	}
+
+	theta, se := estimateAbility(quiz, answers)
+	attempt.Ability, attempt.AbilitySE = &theta, &se
+	attempt.CurrentQuestionID = nil
+
+	maxSE := quiz.AdaptiveMaxSE
+	if maxSE == 0 {
+		maxSE = constant.AdaptiveDefaultMaxSE
+	}
+	done := se <= maxSE || (quiz.AdaptiveMaxItems > 0 && len(answers) >= quiz.AdaptiveMaxItems)
+	if !done {
+		answered := map[uuid.UUID]bool{}
+		for _, a := range answers {
+			answered[a.QuestionID] = true
+		}
+		if next := nextAdaptiveQuestion(quiz, answered, theta); next != nil {
+			attempt.CurrentQuestionID = &next.ID
+		}
+	}
+
+	_, err = u.attemptRepo.UpdateAttempt(attempt)
+	return dbError(err, "attempt")
+}
+
+// nextAdaptiveQuestion picks the unanswered multiple choice question with
+// the most information at ability theta, or nil when none is left.
+func nextAdaptiveQuestion(quiz *models.Quiz, answered map[uuid.UUID]bool, theta float64) *models.Question {
+	var best *models.Question
+	bestInfo := -1.0
+	for i := range quiz.Questions {
+		q := &quiz.Questions[i]
+		if q.Type == constant.QuestionEssay || answered[q.ID] {
+			continue
+		}
+		if info := irt.Information(irtItem(q), theta); info > bestInfo {
+			best, bestInfo = q, info
+		}
+	}
+	return best
+}
+
+// estimateAbility estimates the participant's ability from their answers to
+// the quiz's multiple choice questions.
+func estimateAbility(quiz *models.Quiz, answers []models.Answer) (theta, se float64) {
+	responses := []irt.Response{}
+	for _, a := range answers {
+		q := findQuestion(quiz, a.QuestionID)
+		if q == nil || q.Type == constant.QuestionEssay {
+			continue
+		}
+		responses = append(responses, irt.Response{Item: irtItem(q), Correct: a.Correct})
+	}
+	return irt.EstimateAbility(responses)
+}
+
+func irtItem(q *models.Question) irt.Item {
+	return irt.Item{Discrimination: q.IRTDiscrimination, Difficulty: q.IRTDifficulty}
+}
+
+// policyReleased reports whether a release policy lets students see results
+// at now, given the close date if there is one.
+func policyReleased(policy string, closesAt *time.Time, now time.Time) bool {
+	switch policy {
+	case constant.ReleaseImmediately:
+		return true
+	case constant.ReleaseAfterClose:
+		return closesAt == nil || now.After(*closesAt)
+	default:
+		return false
+	}
+}
+
+// attemptExpiry is the earlier of the quiz time limit and the deadline, or
+// nil when neither applies.
+func attemptExpiry(start time.Time, duration time.Duration, deadline *time.Time) *time.Time {
+	var expiry *time.Time
+	if duration > 0 {
+		end := start.Add(duration)
+		expiry = &end
+	}
+	if deadline != nil && (expiry == nil || deadline.Before(*expiry)) {
+		end := *deadline
+		expiry = &end
+	}
+	return expiry
+}
+
+// scoreAnswers returns the percentage of the quiz answered correctly, with
+// essays counting for the share of rubric points awarded. pending is set
+// while any essay answer still waits for grading.
+func scoreAnswers(quiz *models.Quiz, answers []models.Answer) (score int, pending bool) {
+	if len(quiz.Questions) == 0 {
+		return 0, false
+	}
+	earned := 0.0
+	for _, a := range answers {
+		switch {
+		case a.NeedsGrading:
+			pending = true
+		case a.MaxPoints > 0:
+			earned += float64(a.Points) / float64(a.MaxPoints)
+		case a.Correct:
+			earned++
+		}
+	}
+	return int(math.Round(earned * 100 / float64(len(quiz.Questions)))), pending
+}
+
+func applyPenalty(score, penalty int) int {
+	return score * (100 - penalty) / 100
+}
+
+func rubricPoints(question *models.Question) int {
+	points := 0
+	for _, c := range question.Rubric {
+		points += c.Points
+	}
+	return points
+}
+
+func findQuestion(quiz *models.Quiz, id uuid.UUID) *models.Question {
+	for i := range quiz.Questions {
+		if quiz.Questions[i].ID == id {
+			return &quiz.Questions[i]
+		}
+	}
+	return nil
+}
+
+func hasOption(question *models.Question, id uuid.UUID) bool {
+	for _, o := range question.Options {
+		if o.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package usecases
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+)
+
+func TestPolicyReleased(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	before, after := now.Add(-time.Minute), now.Add(time.Minute)
+
+	tests := []struct {
+		name     string
+		policy   string
+		closesAt *time.Time
+		want     bool
+	}{
+		{"immediately", constant.ReleaseImmediately, &after, true},
+		{"after close without a close date", constant.ReleaseAfterClose, nil, true},
+		{"after close, still open", constant.ReleaseAfterClose, &after, false},
+		{"after close, closing now", constant.ReleaseAfterClose, &now, false},
+		{"after close, closed", constant.ReleaseAfterClose, &before, true},
+		{"never", constant.ReleaseNever, &before, false},
+		{"unknown policy", "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policyReleased(tt.policy, tt.closesAt, now); got != tt.want {
+				t.Errorf("policyReleased = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttemptExpiry(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	soon, later := start.Add(10*time.Minute), start.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		duration time.Duration
+		deadline *time.Time
+		want     *time.Time
+	}{
+		{"no limit", 0, nil, nil},
+		{"time limit only", 30 * time.Minute, nil, timePtr(start.Add(30 * time.Minute))},
+		{"deadline only", 0, &later, &later},
+		{"time limit first", 30 * time.Minute, &later, timePtr(start.Add(30 * time.Minute))},
+		{"deadline first", 30 * time.Minute, &soon, &soon},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := attemptExpiry(start, tt.duration, tt.deadline)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || !got.Equal(*tt.want):
+				t.Errorf("attemptExpiry = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttemptExpiryCopiesDeadline(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	deadline := start.Add(time.Hour)
+	got := attemptExpiry(start, 0, &deadline)
+	deadline = deadline.Add(time.Hour)
+	if !got.Equal(start.Add(time.Hour)) {
+		t.Errorf("expiry moved with the assignment's deadline to %v", got)
+	}
+}
+
+func TestScoreAnswers(t *testing.T) {
+	quiz := func(questions int) *models.Quiz {
+		return &models.Quiz{Questions: make([]models.Question, questions)}
+	}
+	right := models.Answer{Correct: true}
+	wrong := models.Answer{}
+	essay := func(points, max int) models.Answer {
+		return models.Answer{Points: points, MaxPoints: max}
+	}
+	ungraded := models.Answer{NeedsGrading: true, MaxPoints: 10}
+
+	tests := []struct {
+		name    string
+		quiz    *models.Quiz
+		answers []models.Answer
+		score   int
+		pending bool
+	}{
+		{"no questions", quiz(0), nil, 0, false},
+		{"nothing answered", quiz(4), nil, 0, false},
+		{"all right", quiz(2), []models.Answer{right, right}, 100, false},
+		{"unanswered count as wrong", quiz(4), []models.Answer{right, wrong}, 25, false},
+		{"rounds", quiz(3), []models.Answer{right, right, wrong}, 67, false},
+		{"essay counts its share of points", quiz(2), []models.Answer{right, essay(5, 10)}, 75, false},
+		{"essay scored zero", quiz(2), []models.Answer{right, essay(0, 10)}, 50, false},
+		{"ungraded essay is pending", quiz(2), []models.Answer{right, ungraded}, 50, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, pending := scoreAnswers(tt.quiz, tt.answers)
+			if score != tt.score || pending != tt.pending {
+				t.Errorf("scoreAnswers = (%d, %v), want (%d, %v)", score, pending, tt.score, tt.pending)
+			}
+		})
+	}
+}
+
+func TestApplyPenalty(t *testing.T) {
+	tests := []struct {
+		score, penalty, want int
+	}{
+		{80, 0, 80},
+		{80, 25, 60},
+		{80, 100, 0},
+		{99, 10, 89},
+		{0, 50, 0},
+	}
+	for _, tt := range tests {
+		if got := applyPenalty(tt.score, tt.penalty); got != tt.want {
+			t.Errorf("applyPenalty(%d, %d) = %d, want %d", tt.score, tt.penalty, got, tt.want)
+		}
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
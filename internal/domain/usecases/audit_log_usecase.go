@@ -0,0 +1,139 @@
+package usecases
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+)
+
+// Fields never written to the audit trail, either because they are secret or
+// because they change on every update and only add noise to the diff.
+var ignoredAuditFields = map[string]bool{
+	"password":   true,
+	"Role":       true,
+	"created_at": true,
+	"updated_at": true,
+	"deleted_at": true,
+}
+
+type AuditLogUsecase interface {
+	Record(entry models.AuditEntry) error
+	GetAuditLogs(filter models.AuditLogFilter) ([]models.AuditLog, int64, error)
+}
+
+type auditLogUsecase struct {
+	auditRepo repositories.AuditLogRepository
+}
+
+func NewAuditLogUsecase(repo repositories.AuditLogRepository) AuditLogUsecase {
+	return &auditLogUsecase{auditRepo: repo}
+}
+
+func (u *auditLogUsecase) Record(entry models.AuditEntry) error {
+	before, err := auditSnapshot(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := auditSnapshot(entry.After)
+	if err != nil {
+		return err
+	}
+
+	log := &models.AuditLog{
+		ActorID:   entry.ActorID,
+		ActorRole: entry.ActorRole,
+		Action:    entry.Action,
+		Entity:    entry.Entity,
+		EntityID:  entry.EntityID,
+		IP:        entry.IP,
+		RequestID: entry.RequestID,
+		CreatedAt: time.Now(),
+	}
+
+	if log.Before, err = encodeAuditJSON(before); err != nil {
+		return err
+	}
+	if log.After, err = encodeAuditJSON(after); err != nil {
+		return err
+	}
+	if log.Changes, err = encodeAuditJSON(auditDiff(before, after)); err != nil {
+		return err
+	}
+
+	return u.auditRepo.CreateAuditLog(log)
+}
+
+func (u *auditLogUsecase) GetAuditLogs(filter models.AuditLogFilter) ([]models.AuditLog, int64, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = constant.DefaultPageSize
+	}
+	if filter.PageSize > constant.MaxPageSize {
+		filter.PageSize = constant.MaxPageSize
+	}
+
+	logs, total, err := u.auditRepo.FindAuditLogs(filter)
+	return logs, total, dbError(err, "audit log")
+}
+
+// auditSnapshot flattens an entity into its JSON fields, minus ignored ones.
+func auditSnapshot(v interface{}) (map[string]interface{}, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, err
+	}
+
+	for field := range snapshot {
+		if ignoredAuditFields[field] {
+			delete(snapshot, field)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// auditDiff returns {field: {"from": old, "to": new}} for every field whose
+// value differs between the two snapshots.
+func auditDiff(before, after map[string]interface{}) map[string]map[string]interface{} {
+	diff := map[string]map[string]interface{}{}
+
+	for field, old := range before {
+		if updated, ok := after[field]; !ok || !reflect.DeepEqual(old, updated) {
+			diff[field] = map[string]interface{}{"from": old, "to": after[field]}
+		}
+	}
+	for field, updated := range after {
+		if _, ok := before[field]; !ok {
+			diff[field] = map[string]interface{}{"from": nil, "to": updated}
+		}
+	}
+
+	return diff
+}
+
+func encodeAuditJSON(v interface{}) (models.JSONText, error) {
+	if reflect.ValueOf(v).Len() == 0 {
+		return "", nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return models.JSONText(raw), nil
+}
@@ -0,0 +1,124 @@
+package usecases
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/storage"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CatalogUsecase shows students the published quizzes.
+type CatalogUsecase interface {
+	GetCatalog(filter models.CatalogFilter) ([]models.CatalogQuiz, int64, error)
+	GetCatalogQuiz(id uuid.UUID) (*models.CatalogQuizDetail, error)
+}
+
+type catalogUsecase struct {
+	quizRepo     repositories.QuizRepository
+	categoryRepo repositories.CategoryRepository
+	store        storage.Storage
+}
+
+func NewCatalogUsecase(quizRepo repositories.QuizRepository, categoryRepo repositories.CategoryRepository, store storage.Storage) CatalogUsecase {
+	return &catalogUsecase{
+		quizRepo:     quizRepo,
+		categoryRepo: categoryRepo,
+		store:        store,
+	}
+}
+
+// GetCatalog lists a page of the published quizzes by title, limited to a
+// category subtree, tags and a difficulty like the teachers' listing.
+func (u *catalogUsecase) GetCatalog(filter models.CatalogFilter) ([]models.CatalogQuiz, int64, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = constant.DefaultPageSize
+	}
+	if filter.PageSize > constant.MaxPageSize {
+		filter.PageSize = constant.MaxPageSize
+	}
+	if err := resolveQuizFilter(u.categoryRepo, &filter.QuizFilter); err != nil {
+		return nil, 0, err
+	}
+
+	quizzes, total, err := u.quizRepo.FindCatalog(filter)
+	if err != nil {
+		return nil, 0, dbError(err, "quiz")
+	}
+	ids := []uuid.UUID{}
+	for _, q := range quizzes {
+		ids = append(ids, q.ID)
+	}
+	counts, err := u.quizRepo.CountQuestions(ids)
+	if err != nil {
+		return nil, 0, dbError(err, "question")
+	}
+
+	catalog := []models.CatalogQuiz{}
+	for i := range quizzes {
+		catalog = append(catalog, catalogQuiz(&quizzes[i], counts[quizzes[i].ID]))
+	}
+	return catalog, total, nil
+}
+
+// GetCatalogQuiz returns a published quiz without its answers.
+func (u *catalogUsecase) GetCatalogQuiz(id uuid.UUID) (*models.CatalogQuizDetail, error) {
+	quiz, err := u.quizRepo.FindQuizByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrQuizNotFound
+	}
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	if quiz.Status != constant.QuizPublished {
+		return nil, ErrQuizNotFound
+	}
+
+	detail := &models.CatalogQuizDetail{
+		CatalogQuiz: catalogQuiz(quiz, len(quiz.Questions)),
+		Questions:   []models.CatalogQuestion{},
+	}
+	for _, q := range quiz.Questions {
+		question := models.CatalogQuestion{
+			ID:          q.ID,
+			Position:    q.Position,
+			Type:        q.Type,
+			Text:        q.Text,
+			TextHTML:    q.TextHTML,
+			Options:     []models.AttemptOption{},
+			Attachments: studentAttachments(u.store, q.Attachments),
+		}
+		for _, o := range q.Options {
+			question.Options = append(question.Options, attemptOption(o))
+		}
+		detail.Questions = append(detail.Questions, question)
+	}
+	return detail, nil
+}
+
+func catalogQuiz(quiz *models.Quiz, questions int) models.CatalogQuiz {
+	tags := quiz.Tags
+	if tags == nil {
+		tags = []models.Tag{}
+	}
+	return models.CatalogQuiz{
+		ID:              quiz.ID,
+		Title:           quiz.Title,
+		Description:     quiz.Description,
+		CategoryID:      quiz.CategoryID,
+		Category:        quiz.Category.Name,
+		CategorySlug:    quiz.Category.Slug,
+		Difficulty:      quiz.Difficulty,
+		DurationMinutes: int(quiz.Duration / time.Minute),
+		QuestionCount:   questions,
+		Adaptive:        quiz.Adaptive,
+		Tags:            tags,
+	}
+}
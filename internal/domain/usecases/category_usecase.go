@@ -1,10 +1,23 @@
 package usecases
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCategoryHasChildren is returned when deleting a category that still
+	// has subcategories.
+	ErrCategoryHasChildren = apperror.Conflict("category has subcategories, move or delete them first")
+
+	ErrCategorySlugTaken = apperror.Conflict("category slug already exists")
 )
 
 type CategoryUsecase interface {
@@ -12,8 +25,14 @@ type CategoryUsecase interface {
 	UpdateCategory(category *models.Category) (*models.Category, error)
 	DeleteCategory(category *models.Category) error
 	GetCategoryByID(id uint) (*models.Category, error)
-	GetCategoryByName(name string) (*models.Category, error)
+	GetCategoryBySlug(slug string) (*models.Category, error)
 	GetAllCategories() ([]models.CategoryList, error)
+	MoveCategory(id uint, parentID *uint) (*models.Category, error)
+	MergeCategory(id, targetID uint) (*models.Category, error)
+	GetTrashedCategories() ([]models.Category, error)
+	RestoreCategory(id uint, name string) (*models.Category, error)
+	PurgeCategory(id uint) (*models.Category, error)
+	PurgeExpiredCategories(before time.Time) ([]models.Category, error)
 }
 
 type categoryUsecase struct {
@@ -24,42 +43,361 @@ func NewCategoryUsecase(repo repositories.CategoryRepository) CategoryUsecase {
 	return &categoryUsecase{categoryRepo: repo}
 }
 
+// CreateCategory creates a category under ParentID, or at the top level.
+// Without a slug, one is derived from the name.
 func (u *categoryUsecase) CreateCategory(category *models.Category) (*models.Category, error) {
+	if category.Name == "" {
+		return nil, apperror.Validation("category name is required", apperror.FieldError{Field: "name", Rule: "required", Message: "is required"})
+	}
+	if err := u.checkParent(0, category.ParentID); err != nil {
+		return nil, err
+	}
+	if err := u.assignSlug(category, category.Slug); err != nil {
+		return nil, err
+	}
+
 	category.CreatedAt = time.Now()
 	category.UpdatedAt = time.Now()
-	return u.categoryRepo.CreateCategory(category)
+	category, err := u.categoryRepo.CreateCategory(category)
+	return category, dbError(err, "category")
 }
 
-func (u *categoryUsecase) UpdateCategory(category *models.Category) (*models.Category, error) {
+// UpdateCategory renames the category and changes its slug when one is
+// given. Its place in the tree only changes through MoveCategory.
+func (u *categoryUsecase) UpdateCategory(input *models.Category) (*models.Category, error) {
+	if input.Name == "" {
+		return nil, apperror.Validation("category name is required", apperror.FieldError{Field: "name", Rule: "required", Message: "is required"})
+	}
+
+	category, err := u.GetCategoryByID(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	category.Name = input.Name
+	if input.Slug != "" && input.Slug != category.Slug {
+		if err := u.assignSlug(category, input.Slug); err != nil {
+			return nil, err
+		}
+	}
+
 	category.UpdatedAt = time.Now()
-	return u.categoryRepo.UpdateCategory(category)
+	category, err = u.categoryRepo.UpdateCategory(category)
+	return category, dbError(err, "category")
 }
 
+// DeleteCategory trashes a category without subcategories.
 func (u *categoryUsecase) DeleteCategory(category *models.Category) error {
-	return u.categoryRepo.DeleteCategory(category)
+	children, err := u.categoryRepo.CountChildren(category.ID)
+	if err != nil {
+		return dbError(err, "category")
+	}
+	if children > 0 {
+		return ErrCategoryHasChildren
+	}
+
+	return dbError(u.categoryRepo.DeleteCategory(category), "category")
+}
+
+// MoveCategory moves a category, with its subcategories, under another
+// parent or to the top level when parentID is nil.
+func (u *categoryUsecase) MoveCategory(id uint, parentID *uint) (*models.Category, error) {
+	category, err := u.GetCategoryByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkParent(id, parentID); err != nil {
+		return nil, err
+	}
+
+	category.ParentID = parentID
+	category.UpdatedAt = time.Now()
+	category, err = u.categoryRepo.UpdateCategory(category)
+	return category, dbError(err, "category")
+}
+
+// MergeCategory moves the category's quizzes, templates and subcategories
+// to the target category, trashes it and returns the target.
+func (u *categoryUsecase) MergeCategory(id, targetID uint) (*models.Category, error) {
+	source, err := u.GetCategoryByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := u.categoryRepo.GetCategoryByID(targetID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.Validation("target category does not exist", apperror.FieldError{
+			Field:   "target_id",
+			Rule:    "exists",
+			Message: "does not match an existing category",
+		})
+	}
+	if err != nil {
+		return nil, dbError(err, "category")
+	}
+
+	subtree, err := categorySubtree(u.categoryRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	if containsID(subtree, targetID) {
+		return nil, apperror.Validation("cannot merge a category into itself or its subcategories", apperror.FieldError{
+			Field:   "target_id",
+			Rule:    "tree",
+			Message: "must not be the category or one of its subcategories",
+		})
+	}
+
+	if err := u.categoryRepo.MergeCategory(source, target); err != nil {
+		return nil, dbError(err, "category")
+	}
+	return target, nil
 }
 
 func (u *categoryUsecase) GetCategoryByID(id uint) (*models.Category, error) {
-	return u.categoryRepo.GetCategoryByID(id)
+	category, err := u.categoryRepo.GetCategoryByID(id)
+	if err != nil {
+		return nil, dbError(err, "category")
+	}
+	return category, nil
 }
 
-func (u *categoryUsecase) GetCategoryByName(name string) (*models.Category, error) {
-	return u.categoryRepo.GetCategoryByName(name)
+func (u *categoryUsecase) GetCategoryBySlug(slug string) (*models.Category, error) {
+	category, err := u.categoryRepo.GetCategoryBySlug(slug)
+	if err != nil {
+		return nil, dbError(err, "category")
+	}
+	return category, nil
 }
 
 func (u *categoryUsecase) GetAllCategories() ([]models.CategoryList, error) {
 	category, err := u.categoryRepo.GetAllCategories()
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, "category")
 	}
 
 	categories := []models.CategoryList{}
 	for _, c := range category {
 		categories = append(categories, models.CategoryList{
-			ID:   c.ID,
-			Name: c.Name,
+			ID:       c.ID,
+			ParentID: c.ParentID,
+			Name:     c.Name,
+			Slug:     c.Slug,
 		})
 	}
 
 	return categories, nil
 }
+
+func (u *categoryUsecase) GetTrashedCategories() ([]models.Category, error) {
+	categories, err := u.categoryRepo.GetDeletedCategories()
+	return categories, dbError(err, "category")
+}
+
+// RestoreCategory brings a trashed category back, optionally under a new
+// name when an active category already uses the old one. It returns to the
+// top level when its parent is gone.
+func (u *categoryUsecase) RestoreCategory(id uint, name string) (*models.Category, error) {
+	category, err := u.categoryRepo.GetDeletedCategoryByID(id)
+	if err != nil {
+		return nil, dbError(err, "trashed category")
+	}
+
+	if name != "" {
+		category.Name = name
+	}
+
+	_, err = u.categoryRepo.GetCategoryByName(category.Name)
+	if err == nil {
+		return nil, ErrRestoreConflict
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dbError(err, "category")
+	}
+
+	if category.ParentID != nil {
+		_, err := u.categoryRepo.GetCategoryByID(*category.ParentID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			category.ParentID = nil
+		} else if err != nil {
+			return nil, dbError(err, "category")
+		}
+	}
+
+	if err := u.categoryRepo.RestoreCategory(category); err != nil {
+		return nil, dbError(err, "category")
+	}
+
+	category.DeletedAt = gorm.DeletedAt{}
+	return category, nil
+}
+
+func (u *categoryUsecase) PurgeCategory(id uint) (*models.Category, error) {
+	category, err := u.categoryRepo.GetDeletedCategoryByID(id)
+	if err != nil {
+		return nil, dbError(err, "trashed category")
+	}
+
+	if err := u.purge(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// PurgeExpiredCategories permanently deletes categories trashed before the
+// given time, skipping any that quizzes still belong to.
+func (u *categoryUsecase) PurgeExpiredCategories(before time.Time) ([]models.Category, error) {
+	categories, err := u.categoryRepo.GetDeletedCategoriesBefore(before)
+	if err != nil {
+		return nil, dbError(err, "category")
+	}
+
+	purged := []models.Category{}
+	for i := range categories {
+		if err := u.purge(&categories[i]); err != nil {
+			if err == ErrStillReferenced {
+				continue
+			}
+			return purged, err
+		}
+		purged = append(purged, categories[i])
+	}
+	return purged, nil
+}
+
+func (u *categoryUsecase) purge(category *models.Category) error {
+	quizzes, err := u.categoryRepo.CountQuizzesByCategoryID(category.ID)
+	if err != nil {
+		return dbError(err, "category")
+	}
+	templates, err := u.categoryRepo.CountTemplatesByCategoryID(category.ID)
+	if err != nil {
+		return dbError(err, "category")
+	}
+	if quizzes > 0 || templates > 0 {
+		return ErrStillReferenced
+	}
+
+	return dbError(u.categoryRepo.PurgeCategory(category), "category")
+}
+
+// checkParent validates the parent of category id (0 for a new one): it must
+// exist and not be the category itself or one of its subcategories.
+func (u *categoryUsecase) checkParent(id uint, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+
+	_, err := u.categoryRepo.GetCategoryByID(*parentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.Validation("parent category does not exist", apperror.FieldError{
+			Field:   "parent_id",
+			Rule:    "exists",
+			Message: "does not match an existing category",
+		})
+	}
+	if err != nil {
+		return dbError(err, "category")
+	}
+	if id == 0 {
+		return nil
+	}
+
+	subtree, err := categorySubtree(u.categoryRepo, id)
+	if err != nil {
+		return err
+	}
+	if containsID(subtree, *parentID) {
+		return apperror.Validation("cannot move a category under itself or its subcategories", apperror.FieldError{
+			Field:   "parent_id",
+			Rule:    "tree",
+			Message: "must not be the category or one of its subcategories",
+		})
+	}
+	return nil
+}
+
+// assignSlug gives the category the requested slug, or one derived from its
+// name when none is requested. A requested slug that is taken is refused,
+// a derived one gets a numeric suffix instead.
+func (u *categoryUsecase) assignSlug(category *models.Category, requested string) error {
+	if requested != "" {
+		if utils.Slugify(requested) != requested {
+			return apperror.Validation("invalid category slug", apperror.FieldError{
+				Field:   "slug",
+				Rule:    "slug",
+				Message: "must be lowercase letters and digits separated by single hyphens",
+			})
+		}
+		taken, err := u.categoryRepo.SlugTaken(requested, category.ID)
+		if err != nil {
+			return dbError(err, "category")
+		}
+		if taken {
+			return ErrCategorySlugTaken
+		}
+		category.Slug = requested
+		return nil
+	}
+
+	base := utils.Slugify(category.Name)
+	if base == "" {
+		base = "category"
+	}
+	slug, err := uniqueSlug(base, func(s string) (bool, error) { return u.categoryRepo.SlugTaken(s, category.ID) })
+	if err != nil {
+		return dbError(err, "category")
+	}
+	category.Slug = slug
+	return nil
+}
+
+// uniqueSlug returns base, or base with the lowest numeric suffix from 2 up
+// that is not taken.
+func uniqueSlug(base string, taken func(string) (bool, error)) (string, error) {
+	slug := base
+	for n := 2; ; n++ {
+		used, err := taken(slug)
+		if err != nil || !used {
+			return slug, err
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// categorySubtree returns the ID of the category followed by those of all
+// its active descendants.
+func categorySubtree(repo repositories.CategoryRepository, id uint) ([]uint, error) {
+	categories, err := repo.GetAllCategories()
+	if err != nil {
+		return nil, dbError(err, "category")
+	}
+
+	children := map[uint][]uint{}
+	for _, c := range categories {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+
+	ids := []uint{id}
+	seen := map[uint]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids, nil
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,405 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrClassroomNotFound is also returned for classrooms owned by another
+// teacher, so their existence isn't revealed.
+var ErrClassroomNotFound = apperror.NotFound("classroom not found")
+
+// Reasons reported for students skipped during enrollment.
+const (
+	SkipUnknownUser     = "no such user"
+	SkipNotStudent      = "user is not a student"
+	SkipAlreadyEnrolled = "already enrolled"
+)
+
+type ClassroomUsecase interface {
+	CreateClassroom(actor models.Actor, req *models.ClassroomRequest) (*models.Classroom, error)
+	UpdateClassroom(actor models.Actor, id uint, req *models.ClassroomRequest) (*models.Classroom, error)
+	DeleteClassroom(actor models.Actor, id uint) (*models.Classroom, error)
+	GetClassroom(actor models.Actor, id uint) (*models.Classroom, error)
+	GetClassrooms(actor models.Actor) ([]models.ClassroomList, error)
+	RegenerateJoinCode(actor models.Actor, id uint) (*models.Classroom, error)
+	GetRoster(actor models.Actor, id uint) ([]models.RosterEntry, error)
+	EnrollStudents(actor models.Actor, id uint, students []string) (*models.EnrollmentResult, error)
+	ImportRoster(actor models.Actor, id uint, r io.Reader) (*models.EnrollmentResult, error)
+	RemoveStudent(actor models.Actor, id, userID uint) error
+	AssignQuiz(actor models.Actor, id uint, quizID uuid.UUID) error
+	UnassignQuiz(actor models.Actor, id uint, quizID uuid.UUID) error
+	GetClassroomQuizzes(actor models.Actor, id uint) ([]models.QuizList, error)
+	GetResults(actor models.Actor, id uint) ([]models.ClassroomResult, error)
+	JoinClassroom(userID uint, code string) (*models.Classroom, error)
+	GetStudentClassrooms(userID uint) ([]models.ClassroomList, error)
+	LeaveClassroom(userID, id uint) error
+}
+
+type classroomUsecase struct {
+	classroomRepo repositories.ClassroomRepository
+	userRepo      repositories.UserRepository
+	quizRepo      repositories.QuizRepository
+}
+
+func NewClassroomUsecase(classroomRepo repositories.ClassroomRepository, userRepo repositories.UserRepository, quizRepo repositories.QuizRepository) ClassroomUsecase {
+	return &classroomUsecase{
+		classroomRepo: classroomRepo,
+		userRepo:      userRepo,
+		quizRepo:      quizRepo,
+	}
+}
+
+func (u *classroomUsecase) CreateClassroom(actor models.Actor, req *models.ClassroomRequest) (*models.Classroom, error) {
+	classroom := &models.Classroom{
+		Name:        req.Name,
+		Description: req.Description,
+		TeacherID:   actor.ID,
+	}
+
+	if err := u.saveWithNewJoinCode(classroom, u.classroomRepo.CreateClassroom); err != nil {
+		return nil, err
+	}
+	return classroom, nil
+}
+
+func (u *classroomUsecase) UpdateClassroom(actor models.Actor, id uint, req *models.ClassroomRequest) (*models.Classroom, error) {
+	classroom, err := u.GetClassroom(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	classroom.Name = req.Name
+	classroom.Description = req.Description
+	classroom.UpdatedAt = time.Now()
+
+	classroom, err = u.classroomRepo.UpdateClassroom(classroom)
+	return classroom, dbError(err, "classroom")
+}
+
+func (u *classroomUsecase) DeleteClassroom(actor models.Actor, id uint) (*models.Classroom, error) {
+	classroom, err := u.GetClassroom(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	return classroom, dbError(u.classroomRepo.DeleteClassroom(classroom), "classroom")
+}
+
+// GetClassroom returns the classroom if the actor owns it or is an admin.
+func (u *classroomUsecase) GetClassroom(actor models.Actor, id uint) (*models.Classroom, error) {
+	classroom, err := u.classroomRepo.FindClassroomByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrClassroomNotFound
+	}
+	if err != nil {
+		return nil, dbError(err, "classroom")
+	}
+
+	if !actor.IsAdmin() && classroom.TeacherID != actor.ID {
+		return nil, ErrClassroomNotFound
+	}
+	return classroom, nil
+}
+
+func (u *classroomUsecase) GetClassrooms(actor models.Actor) ([]models.ClassroomList, error) {
+	teacherID := actor.ID
+	if actor.IsAdmin() {
+		teacherID = 0
+	}
+	classrooms, err := u.classroomRepo.FindClassrooms(teacherID)
+	return classrooms, dbError(err, "classroom")
+}
+
+// RegenerateJoinCode invalidates the current join code.
+func (u *classroomUsecase) RegenerateJoinCode(actor models.Actor, id uint) (*models.Classroom, error) {
+	classroom, err := u.GetClassroom(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	classroom.UpdatedAt = time.Now()
+	if err := u.saveWithNewJoinCode(classroom, u.classroomRepo.UpdateClassroom); err != nil {
+		return nil, err
+	}
+	return classroom, nil
+}
+
+func (u *classroomUsecase) GetRoster(actor models.Actor, id uint) ([]models.RosterEntry, error) {
+	if _, err := u.GetClassroom(actor, id); err != nil {
+		return nil, err
+	}
+	roster, err := u.classroomRepo.FindRoster(id)
+	return roster, dbError(err, "classroom")
+}
+
+// EnrollStudents enrolls each student given by username or email. Unknown
+// users, non-students and existing members are skipped and reported.
+func (u *classroomUsecase) EnrollStudents(actor models.Actor, id uint, students []string) (*models.EnrollmentResult, error) {
+	if _, err := u.GetClassroom(actor, id); err != nil {
+		return nil, err
+	}
+
+	rows := make([]enrollRow, 0, len(students))
+	for _, s := range students {
+		rows = append(rows, enrollRow{value: strings.TrimSpace(s)})
+	}
+	return u.enroll(id, rows)
+}
+
+// ImportRoster enrolls the students listed in a CSV file. The first column
+// holds a username or email; an optional header row naming it "username" or
+// "email" is skipped, as are blank lines.
+func (u *classroomUsecase) ImportRoster(actor models.Actor, id uint, r io.Reader) (*models.EnrollmentResult, error) {
+	if _, err := u.GetClassroom(actor, id); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows := []enrollRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, apperror.Validation("invalid CSV: "+err.Error(), apperror.FieldError{
+				Field:   "file",
+				Rule:    "csv",
+				Message: err.Error(),
+			})
+		}
+
+		line, _ := reader.FieldPos(0)
+		value := strings.TrimSpace(record[0])
+		if value == "" {
+			continue
+		}
+		if line == 1 && (strings.EqualFold(value, "username") || strings.EqualFold(value, "email")) {
+			continue
+		}
+		rows = append(rows, enrollRow{line: line, value: value})
+	}
+
+	if len(rows) == 0 {
+		return nil, apperror.Validation("CSV contains no students", apperror.FieldError{
+			Field:   "file",
+			Rule:    "required",
+			Message: "must list at least one username or email",
+		})
+	}
+	return u.enroll(id, rows)
+}
+
+func (u *classroomUsecase) RemoveStudent(actor models.Actor, id, userID uint) error {
+	if _, err := u.GetClassroom(actor, id); err != nil {
+		return err
+	}
+
+	removed, err := u.classroomRepo.RemoveMember(id, userID)
+	if err != nil {
+		return dbError(err, "classroom")
+	}
+	if removed == 0 {
+		return apperror.NotFound("student is not enrolled in this classroom")
+	}
+	return nil
+}
+
+func (u *classroomUsecase) AssignQuiz(actor models.Actor, id uint, quizID uuid.UUID) error {
+	if _, err := u.GetClassroom(actor, id); err != nil {
+		return err
+	}
+
+	quiz, err := u.quizRepo.FindQuizByID(quizID)
+	if err != nil {
+		return dbError(err, "quiz")
+	}
+	if quiz.Status != constant.QuizPublished {
+		return ErrQuizUnpublished
+	}
+
+	err = u.classroomRepo.AssignQuiz(&models.ClassroomQuiz{ClassroomID: id, QuizID: quizID})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return apperror.Conflict("quiz is already assigned to this classroom")
+	}
+	return dbError(err, "classroom")
+}
+
+func (u *classroomUsecase) UnassignQuiz(actor models.Actor, id uint, quizID uuid.UUID) error {
+	if _, err := u.GetClassroom(actor, id); err != nil {
+		return err
+	}
+
+	removed, err := u.classroomRepo.UnassignQuiz(id, quizID)
+	if err != nil {
+		return dbError(err, "classroom")
+	}
+	if removed == 0 {
+		return apperror.NotFound("quiz is not assigned to this classroom")
+	}
+	return nil
+}
+
+func (u *classroomUsecase) GetClassroomQuizzes(actor models.Actor, id uint) ([]models.QuizList, error) {
+	if _, err := u.GetClassroom(actor, id); err != nil {
+		return nil, err
+	}
+	quizzes, err := u.classroomRepo.FindClassroomQuizzes(id)
+	return quizzes, dbError(err, "classroom")
+}
+
+// GetResults lists attempts by the classroom's students at its quizzes.
+func (u *classroomUsecase) GetResults(actor models.Actor, id uint) ([]models.ClassroomResult, error) {
+	if _, err := u.GetClassroom(actor, id); err != nil {
+		return nil, err
+	}
+	results, err := u.classroomRepo.FindResults(id)
+	return results, dbError(err, "classroom")
+}
+
+func (u *classroomUsecase) JoinClassroom(userID uint, code string) (*models.Classroom, error) {
+	classroom, err := u.classroomRepo.FindClassroomByJoinCode(strings.ToUpper(strings.TrimSpace(code)))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.Validation("join code is invalid", apperror.FieldError{
+			Field:   "code",
+			Rule:    "valid",
+			Message: "does not match any classroom",
+		})
+	}
+	if err != nil {
+		return nil, dbError(err, "classroom")
+	}
+
+	err = u.classroomRepo.AddMember(&models.ClassroomMember{ClassroomID: classroom.ID, UserID: userID})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, apperror.Conflict(SkipAlreadyEnrolled)
+	}
+	if err != nil {
+		return nil, dbError(err, "classroom")
+	}
+	return classroom, nil
+}
+
+func (u *classroomUsecase) GetStudentClassrooms(userID uint) ([]models.ClassroomList, error) {
+	classrooms, err := u.classroomRepo.FindClassroomsByStudentID(userID)
+	return classrooms, dbError(err, "classroom")
+}
+
+func (u *classroomUsecase) LeaveClassroom(userID, id uint) error {
+	removed, err := u.classroomRepo.RemoveMember(id, userID)
+	if err != nil {
+		return dbError(err, "classroom")
+	}
+	if removed == 0 {
+		return ErrClassroomNotFound
+	}
+	return nil
+}
+
+type enrollRow struct {
+	line  int
+	value string
+}
+
+func (u *classroomUsecase) enroll(classroomID uint, rows []enrollRow) (*models.EnrollmentResult, error) {
+	result := &models.EnrollmentResult{
+		Enrolled: []models.RosterEntry{},
+		Skipped:  []models.EnrollmentSkip{},
+	}
+
+	for _, row := range rows {
+		skip := func(reason string) {
+			result.Skipped = append(result.Skipped, models.EnrollmentSkip{Line: row.line, Value: row.value, Reason: reason})
+		}
+
+		var user *models.User
+		var err error
+		if strings.Contains(row.value, "@") {
+			user, err = u.userRepo.FindUserByEmail(row.value)
+		} else {
+			user, err = u.userRepo.FindUserByUsername(row.value)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			skip(SkipUnknownUser)
+			continue
+		}
+		if err != nil {
+			return nil, dbError(err, "user")
+		}
+		if user.RoleID != constant.RoleStudentID {
+			skip(SkipNotStudent)
+			continue
+		}
+
+		member := &models.ClassroomMember{ClassroomID: classroomID, UserID: user.ID}
+		err = u.classroomRepo.AddMember(member)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			skip(SkipAlreadyEnrolled)
+			continue
+		}
+		if err != nil {
+			return nil, dbError(err, "classroom")
+		}
+
+		result.Enrolled = append(result.Enrolled, models.RosterEntry{
+			UserID:     user.ID,
+			Username:   user.Username,
+			Email:      user.Email,
+			EnrolledAt: member.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// joinCodeAlphabet leaves out characters that are easily confused when read
+// aloud or copied from a board (0/O, 1/I/L).
+const joinCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+const joinCodeLength = 8
+
+// saveWithNewJoinCode assigns a random join code and saves the classroom,
+// retrying a few times if the code happens to be taken.
+func (u *classroomUsecase) saveWithNewJoinCode(classroom *models.Classroom, save func(*models.Classroom) (*models.Classroom, error)) error {
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := newJoinCode()
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		classroom.JoinCode = code
+
+		_, err = save(classroom)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			continue
+		}
+		return dbError(err, "classroom")
+	}
+	return apperror.Internal(errors.New("could not generate a unique join code"))
+}
+
+func newJoinCode() (string, error) {
+	max := big.NewInt(int64(len(joinCodeAlphabet)))
+	code := make([]byte, joinCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = joinCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,36 @@
+package usecases
+
+import (
+	"errors"
+
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRestoreConflict is returned when restoring a trashed record would
+	// clash with the unique name of an active one.
+	ErrRestoreConflict = apperror.Conflict("an active record with the same name already exists, restore it under a new name")
+
+	// ErrStillReferenced is returned when purging a record other rows still point to.
+	ErrStillReferenced = apperror.Conflict("record is still referenced and cannot be purged")
+)
+
+// dbError translates a repository error into a typed error: missing rows
+// become not found, unique violations become conflicts and anything else is
+// internal so the raw database message is never exposed.
+func dbError(err error, entity string) error {
+	var appErr *apperror.Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &appErr):
+		return err
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperror.NotFound(entity + " not found")
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return apperror.Conflict(entity + " already exists")
+	default:
+		return apperror.Internal(err)
+	}
+}
@@ -0,0 +1,347 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/irt"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/mailer"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/richtext"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrAnswerNotFound is also returned for answers to other teachers'
+// assignments.
+var ErrAnswerNotFound = apperror.NotFound("answer not found")
+
+type GradingUsecase interface {
+	GetQueue(actor models.Actor) ([]models.GradingItem, error)
+	GradeAnswer(actor models.Actor, answerID uuid.UUID, req *models.GradeAnswerRequest) (*models.Answer, error)
+	RegradeQuiz(quizID uuid.UUID) (*models.RegradeResult, error)
+}
+
+type gradingUsecase struct {
+	gradingRepo    repositories.GradingRepository
+	attemptRepo    repositories.AttemptRepository
+	assignmentRepo repositories.AssignmentRepository
+	quizRepo       repositories.QuizRepository
+	userRepo       repositories.UserRepository
+	mailer         mailer.Mailer
+}
+
+func NewGradingUsecase(gradingRepo repositories.GradingRepository, attemptRepo repositories.AttemptRepository, assignmentRepo repositories.AssignmentRepository, quizRepo repositories.QuizRepository, userRepo repositories.UserRepository, m mailer.Mailer) GradingUsecase {
+	return &gradingUsecase{
+		gradingRepo:    gradingRepo,
+		attemptRepo:    attemptRepo,
+		assignmentRepo: assignmentRepo,
+		quizRepo:       quizRepo,
+		userRepo:       userRepo,
+		mailer:         m,
+	}
+}
+
+// GetQueue lists the essay answers waiting for the actor to grade; admins
+// see every assignment's answers.
+func (u *gradingUsecase) GetQueue(actor models.Actor) ([]models.GradingItem, error) {
+	creator := actor.ID
+	if actor.IsAdmin() {
+		creator = 0
+	}
+	items, err := u.gradingRepo.FindGradingQueue(creator)
+	if err != nil {
+		return nil, dbError(err, "answer")
+	}
+	for i := range items {
+		items[i].QuestionTextHTML = richtext.Render(items[i].QuestionText)
+	}
+	return items, nil
+}
+
+// GradeAnswer scores an essay answer against its rubric. Once the last
+// essay of the attempt is graded, the attempt's score is released and the
+// student is notified. Graded answers can be graded again, which rescores
+// the attempt without a new notification.
+func (u *gradingUsecase) GradeAnswer(actor models.Actor, answerID uuid.UUID, req *models.GradeAnswerRequest) (*models.Answer, error) {
+	answer, err := u.gradingRepo.FindAnswerByID(answerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAnswerNotFound
+	}
+	if err != nil {
+		return nil, dbError(err, "answer")
+	}
+
+	attempt, err := u.attemptRepo.FindAttemptByID(answer.ParticipantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAnswerNotFound
+	}
+	if err != nil {
+		return nil, dbError(err, "attempt")
+	}
+	if attempt.AssignmentID == nil {
+		return nil, ErrAnswerNotFound
+	}
+
+	assignment, err := u.assignmentRepo.FindAssignmentByID(*attempt.AssignmentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAnswerNotFound
+	}
+	if err != nil {
+		return nil, dbError(err, "assignment")
+	}
+	if !actor.IsAdmin() && assignment.CreatedBy != actor.ID {
+		return nil, ErrAnswerNotFound
+	}
+	if !attempt.Finished {
+		return nil, ErrAttemptNotFinished
+	}
+
+	quiz, err := pinnedQuiz(u.quizRepo, attempt)
+	if err != nil {
+		return nil, err
+	}
+	question := findQuestion(quiz, answer.QuestionID)
+	if question == nil || question.Type != constant.QuestionEssay {
+		return nil, apperror.Conflict("only essay answers are graded manually")
+	}
+
+	scores, points, err := rubricScores(question, answer.ID, req.Scores)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	answer.Points = points
+	answer.MaxPoints = rubricPoints(question)
+	answer.Correct = points == answer.MaxPoints
+	answer.Comment = req.Comment
+	answer.NeedsGrading = false
+	answer.GradedBy = &actor.ID
+	answer.GradedAt = &now
+	if err := u.gradingRepo.SaveGrade(answer, scores); err != nil {
+		return nil, dbError(err, "answer")
+	}
+
+	if err := u.rescore(attempt, quiz); err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+// RegradeQuiz moves the quiz's attempts pinned to an earlier version onto
+// its latest one: multiple choice answers are marked against the current
+// answer key, and finished attempts are scored again, keeping their late
+// penalty. Practice attempts only have their answers remarked, and essay
+// grades are kept as they are. Attempts whose version has other questions
+// than the latest, because a revision replaced them, stay on their version.
+func (u *gradingUsecase) RegradeQuiz(quizID uuid.UUID) (*models.RegradeResult, error) {
+	quiz, err := u.quizRepo.FindQuizByID(quizID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrQuizNotFound
+	}
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+
+	result := &models.RegradeResult{QuizID: quiz.ID, Version: quiz.Version}
+	attempts, err := u.attemptRepo.FindOutdatedAttempts(quiz.ID, quiz.Version)
+	if err != nil {
+		return nil, dbError(err, "attempt")
+	}
+	regradable := map[int]bool{}
+	for i := range attempts {
+		attempt := &attempts[i]
+		same, ok := regradable[attempt.QuizVersion]
+		if !ok {
+			pinned, err := pinQuestions(u.quizRepo, quiz, attempt)
+			if err != nil {
+				return nil, err
+			}
+			same = sameQuestions(pinned, quiz)
+			regradable[attempt.QuizVersion] = same
+		}
+		if !same {
+			result.Kept++
+			continue
+		}
+
+		answers, err := u.attemptRepo.FindAnswers(attempt.ID)
+		if err != nil {
+			return nil, dbError(err, "answer")
+		}
+
+		changed := []models.Answer{}
+		for j := range answers {
+			a := &answers[j]
+			q := findQuestion(quiz, a.QuestionID)
+			if q == nil || q.Type == constant.QuestionEssay {
+				continue
+			}
+			if correct := q.AnswerID == a.OptionID; correct != a.Correct {
+				a.Correct = correct
+				changed = append(changed, *a)
+			}
+		}
+
+		score := attempt.Score
+		if attempt.Finished && !attempt.Practice && len(changed) > 0 {
+			if attempt.Adaptive {
+				theta, se := estimateAbility(quiz, answers)
+				attempt.Ability, attempt.AbilitySE = &theta, &se
+				attempt.RawScore = irt.ScorePercent(theta)
+			} else {
+				attempt.RawScore, attempt.GradingPending = scoreAnswers(quiz, answers)
+			}
+			attempt.Score = attempt.RawScore
+			if attempt.Late {
+				attempt.Score = applyPenalty(attempt.RawScore, attempt.Penalty)
+			}
+		}
+
+		attempt.QuizVersion = quiz.Version
+		if err := u.attemptRepo.SaveRegrade(attempt, changed); err != nil {
+			return nil, dbError(err, "attempt")
+		}
+		result.Attempts++
+		result.AnswersChanged += len(changed)
+		if attempt.Score != score {
+			result.ScoresChanged++
+		}
+	}
+	return result, nil
+}
+
+// sameQuestions reports whether both quizzes have the same questions, as
+// told by their IDs, which only change when a revision replaces them.
+func sameQuestions(a, b *models.Quiz) bool {
+	if len(a.Questions) != len(b.Questions) {
+		return false
+	}
+	ids := make(map[uuid.UUID]bool, len(a.Questions))
+	for _, q := range a.Questions {
+		ids[q.ID] = true
+	}
+	for _, q := range b.Questions {
+		if !ids[q.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// rescore recomputes the attempt's score once none of its answers wait for
+// grading, and tells the student when that releases the result.
+func (u *gradingUsecase) rescore(attempt *models.Participant, quiz *models.Quiz) error {
+	ungraded, err := u.gradingRepo.CountUngradedAnswers(attempt.ID)
+	if err != nil {
+		return dbError(err, "answer")
+	}
+	if ungraded > 0 {
+		return nil
+	}
+
+	answers, err := u.attemptRepo.FindAnswers(attempt.ID)
+	if err != nil {
+		return dbError(err, "answer")
+	}
+
+	released := attempt.GradingPending
+	attempt.RawScore, attempt.GradingPending = scoreAnswers(quiz, answers)
+	attempt.Score = attempt.RawScore
+	if attempt.Late {
+		attempt.Score = applyPenalty(attempt.RawScore, attempt.Penalty)
+	}
+	if _, err := u.attemptRepo.UpdateAttempt(attempt); err != nil {
+		return dbError(err, "attempt")
+	}
+
+	if released {
+		u.notifyGraded(attempt, quiz)
+	}
+	return nil
+}
+
+// notifyGraded tells the student their attempt is graded, with the score
+// only once the quiz's release policy shows it. The grade is already saved,
+// so a failure is only logged.
+func (u *gradingUsecase) notifyGraded(attempt *models.Participant, quiz *models.Quiz) {
+	if attempt.UserID == 0 {
+		return
+	}
+	user, err := u.userRepo.FindUserByID(attempt.UserID)
+	if err != nil || user == nil {
+		log.Printf("Grading: failed to find user %d to notify: %v", attempt.UserID, err)
+		return
+	}
+
+	release, err := resultRelease(u.assignmentRepo, attempt, quiz, time.Now())
+	if err != nil {
+		log.Printf("Grading: failed to check the result release of attempt %s: %v", attempt.ID, err)
+		return
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nYour attempt at %q has been graded.", user.Username, quiz.Title)
+	if release.Score {
+		body += fmt.Sprintf(" Your score is %d%%.", attempt.Score)
+	} else if release.ResultsAt != nil {
+		body += fmt.Sprintf(" Your score will be available from %s.", release.ResultsAt.Format(time.RFC1123))
+	}
+	body += "\n"
+	if err := u.mailer.Send(user.Email, "Your quiz has been graded", body); err != nil {
+		log.Printf("Grading: failed to notify user %d: %v", user.ID, err)
+	}
+}
+
+// rubricScores checks that every criterion of the question is scored once
+// and within its points, and returns the scores with their total.
+func rubricScores(question *models.Question, answerID uuid.UUID, given []models.CriterionScore) ([]models.RubricScore, int, error) {
+	byID := map[uuid.UUID]models.CriterionScore{}
+	for i, s := range given {
+		if _, dup := byID[s.CriterionID]; dup {
+			return nil, 0, apperror.Validation("criterion scored twice", apperror.FieldError{
+				Field:   fmt.Sprintf("scores[%d].criterion_id", i),
+				Rule:    "unique",
+				Message: "is scored more than once",
+			})
+		}
+		byID[s.CriterionID] = s
+	}
+
+	scores := []models.RubricScore{}
+	total := 0
+	for _, c := range question.Rubric {
+		s, ok := byID[c.ID]
+		if !ok {
+			return nil, 0, apperror.Validation("criterion not scored", apperror.FieldError{
+				Field:   "scores",
+				Rule:    "required",
+				Message: fmt.Sprintf("must score criterion %s", c.ID),
+			})
+		}
+		if s.Points > c.Points {
+			return nil, 0, apperror.Validation("points exceed the criterion", apperror.FieldError{
+				Field:   "scores",
+				Rule:    "max",
+				Message: fmt.Sprintf("criterion %s is worth at most %d points", c.ID, c.Points),
+			})
+		}
+		delete(byID, c.ID)
+		scores = append(scores, models.RubricScore{AnswerID: answerID, CriterionID: c.ID, Points: s.Points})
+		total += s.Points
+	}
+
+	if len(byID) > 0 {
+		return nil, 0, apperror.Validation("unknown criterion", apperror.FieldError{
+			Field:   "scores",
+			Rule:    "exists",
+			Message: "contains a criterion that is not part of the question's rubric",
+		})
+	}
+	return scores, total, nil
+}
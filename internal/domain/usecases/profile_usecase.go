@@ -0,0 +1,254 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/mailer"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/utils"
+	"golang.org/x/text/language"
+	"gorm.io/gorm"
+)
+
+// ErrWrongPassword is returned when a sensitive self-service action is not
+// confirmed with the current password.
+var ErrWrongPassword = apperror.Validation("password does not match", apperror.FieldError{
+	Field:   "password",
+	Rule:    "match",
+	Message: "does not match the current password",
+})
+
+type ProfileUsecase interface {
+	GetProfile(userID uint) (*models.Profile, error)
+	UpdateProfile(userID uint, req *models.UpdateProfileRequest) (*models.Profile, error)
+	RequestEmailChange(userID uint, password, newEmail string) (*models.EmailChange, error)
+	VerifyEmailChange(token string) (*models.EmailChange, error)
+	DeleteAccount(userID uint, password string) (*models.User, error)
+}
+
+type profileUsecase struct {
+	profileRepo repositories.ProfileRepository
+	userRepo    repositories.UserRepository
+	mailer      mailer.Mailer
+	publicURL   string
+	verifyTTL   time.Duration
+}
+
+// NewProfileUsecase builds the self-service profile usecase. Verification
+// links are built from publicURL and expire after verifyTTL.
+func NewProfileUsecase(profileRepo repositories.ProfileRepository, userRepo repositories.UserRepository, m mailer.Mailer, publicURL string, verifyTTL time.Duration) ProfileUsecase {
+	return &profileUsecase{
+		profileRepo: profileRepo,
+		userRepo:    userRepo,
+		mailer:      m,
+		publicURL:   publicURL,
+		verifyTTL:   verifyTTL,
+	}
+}
+
+// GetProfile returns the user's profile, or an empty one if none was saved yet.
+func (u *profileUsecase) GetProfile(userID uint) (*models.Profile, error) {
+	profile, err := u.profileRepo.FindProfileByUserID(userID)
+	if err != nil {
+		return nil, dbError(err, "profile")
+	}
+	if profile == nil {
+		profile = &models.Profile{UserID: userID}
+	}
+	return profile, nil
+}
+
+// UpdateProfile applies the fields present in req; an empty string clears one.
+func (u *profileUsecase) UpdateProfile(userID uint, req *models.UpdateProfileRequest) (*models.Profile, error) {
+	if err := validateProfile(req); err != nil {
+		return nil, err
+	}
+
+	profile, err := u.GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DisplayName != nil {
+		profile.DisplayName = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.AvatarURL != nil {
+		profile.AvatarURL = *req.AvatarURL
+	}
+	if req.Bio != nil {
+		profile.Bio = *req.Bio
+	}
+	if req.Locale != nil {
+		profile.Locale = *req.Locale
+	}
+	if req.Timezone != nil {
+		profile.Timezone = *req.Timezone
+	}
+
+	profile, err = u.profileRepo.SaveProfile(profile)
+	return profile, dbError(err, "profile")
+}
+
+// RequestEmailChange mails a verification link to newEmail. The address on
+// the account only changes once the link is followed.
+func (u *profileUsecase) RequestEmailChange(userID uint, password, newEmail string) (*models.EmailChange, error) {
+	user, err := u.confirmPassword(userID, password)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(user.Email, newEmail) {
+		return nil, apperror.Validation("new email is the current email", apperror.FieldError{
+			Field:   "new_email",
+			Rule:    "changed",
+			Message: "must differ from the current email",
+		})
+	}
+	if err := u.checkEmailAvailable(newEmail, userID); err != nil {
+		return nil, err
+	}
+
+	token, err := newVerificationToken()
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	change := &models.EmailChange{
+		UserID:    userID,
+		NewEmail:  newEmail,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(u.verifyTTL),
+	}
+	if err := u.profileRepo.CreateEmailChange(change); err != nil {
+		return nil, dbError(err, "email change")
+	}
+
+	link := u.publicURL + "/auth/profile/email/verify?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hi %s,\n\nConfirm your new email address by following this link:\n%s\n\nThe link expires at %s. If you did not ask for this change, ignore this message.\n",
+		user.Username, link, change.ExpiresAt.Format(time.RFC1123))
+	if err := u.mailer.Send(newEmail, "Confirm your new email address", body); err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	return change, nil
+}
+
+// VerifyEmailChange applies the pending change matching token.
+func (u *profileUsecase) VerifyEmailChange(token string) (*models.EmailChange, error) {
+	change, err := u.profileRepo.FindEmailChangeByTokenHash(hashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.Validation("verification token is invalid or expired", apperror.FieldError{
+				Field:   "token",
+				Rule:    "valid",
+				Message: "is invalid or expired",
+			})
+		}
+		return nil, dbError(err, "email change")
+	}
+
+	// Someone may have registered the address since the link was sent.
+	if err := u.checkEmailAvailable(change.NewEmail, change.UserID); err != nil {
+		return nil, err
+	}
+
+	if err := u.profileRepo.ApplyEmailChange(change); err != nil {
+		return nil, dbError(err, "user")
+	}
+	return change, nil
+}
+
+// DeleteAccount permanently removes the caller's account. Past quiz attempts
+// are kept anonymized rather than deleted.
+func (u *profileUsecase) DeleteAccount(userID uint, password string) (*models.User, error) {
+	user, err := u.confirmPassword(userID, password)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.RoleID == constant.RoleAdminID {
+		return nil, apperror.Forbidden("administrators cannot delete their own account")
+	}
+
+	if err := u.userRepo.PurgeUser(user); err != nil {
+		return nil, dbError(err, "user")
+	}
+	return user, nil
+}
+
+func (u *profileUsecase) confirmPassword(userID uint, password string) (*models.User, error) {
+	user, err := u.userRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, dbError(err, "user")
+	}
+	if user == nil {
+		return nil, apperror.NotFound("user not found")
+	}
+	if !utils.CheckPasswordHash(password, user.Password) {
+		return nil, ErrWrongPassword
+	}
+	return user, nil
+}
+
+func (u *profileUsecase) checkEmailAvailable(email string, exceptID uint) error {
+	existing, err := u.userRepo.FindUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return dbError(err, "user")
+	}
+	if existing.ID != exceptID {
+		return apperror.Conflict("email already exists")
+	}
+	return nil
+}
+
+func validateProfile(req *models.UpdateProfileRequest) error {
+	var fields []apperror.FieldError
+
+	if req.AvatarURL != nil && *req.AvatarURL != "" {
+		u, err := url.Parse(*req.AvatarURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fields = append(fields, apperror.FieldError{Field: "avatar_url", Rule: "url", Message: "must be an absolute http(s) URL"})
+		}
+	}
+	if req.Locale != nil && *req.Locale != "" {
+		if _, err := language.Parse(*req.Locale); err != nil {
+			fields = append(fields, apperror.FieldError{Field: "locale", Rule: "bcp47", Message: "must be a BCP 47 language tag such as en or id-ID"})
+		}
+	}
+	if req.Timezone != nil && *req.Timezone != "" {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil {
+			fields = append(fields, apperror.FieldError{Field: "timezone", Rule: "timezone", Message: "must be an IANA time zone such as Asia/Jakarta"})
+		}
+	}
+
+	if len(fields) > 0 {
+		return apperror.Validation("invalid profile", fields...)
+	}
+	return nil
+}
+
+func newVerificationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
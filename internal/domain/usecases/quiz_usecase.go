@@ -0,0 +1,1246 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/irt"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/storage"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrQuizNotFound is also returned to students for quizzes that are
+	// neither published nor assigned to them.
+	ErrQuizNotFound = apperror.NotFound("quiz not found")
+
+	// ErrQuizHasAttempts is returned when a change would invalidate answers
+	// already given to a quiz.
+	ErrQuizHasAttempts = apperror.Conflict("quiz already has attempts")
+
+	// ErrQuizUnpublished is returned when assigning a quiz that students
+	// can't take.
+	ErrQuizUnpublished = apperror.Validation("quiz is not published", apperror.FieldError{Field: "quiz_id", Rule: "published", Message: "must be a published quiz"})
+
+	ErrQuizArchived       = apperror.Conflict("archived quizzes can't be edited; publish the quiz again first")
+	ErrQuizNotDraft       = apperror.Conflict("only draft quizzes can be submitted for review")
+	ErrQuizNotInReview    = apperror.Conflict("quiz is not in review")
+	ErrQuizNotPublished   = apperror.Conflict("only published quizzes can be archived")
+	ErrQuizPublished      = apperror.Conflict("quiz is already published")
+	ErrQuizReviewRequired = apperror.Conflict("quiz needs a reviewer's approval before it is published")
+	ErrOwnQuizReview      = apperror.Forbidden("quizzes can't be reviewed by the teacher who submitted them")
+	ErrNoQuizRevision     = apperror.NotFound("quiz has no draft revision")
+
+	ErrQuizVersionNotFound = apperror.NotFound("quiz version not found")
+	ErrQuizNeverPublished  = apperror.Conflict("quiz has not been published yet; edit it instead")
+)
+
+type QuizUsecase interface {
+	CreateQuiz(actor models.Actor, req *models.QuizRequest) (*models.Quiz, error)
+	UpdateQuiz(id uuid.UUID, req *models.QuizRequest) (*models.Quiz, error)
+	DeleteQuiz(id uuid.UUID) (*models.Quiz, error)
+	GetQuizByID(id uuid.UUID) (*models.Quiz, error)
+	GetAllQuizzes(actor models.Actor, filter models.QuizFilter) ([]models.QuizList, error)
+	CalibrateQuiz(id uuid.UUID) (*models.Quiz, error)
+	GetDifficultySuggestions() ([]models.DifficultySuggestion, error)
+	GetQuestions(actor models.Actor, filter models.QuestionFilter) ([]models.Question, int64, error)
+	GetRevision(id uuid.UUID) (*models.Quiz, error)
+	SubmitQuiz(actor models.Actor, id uuid.UUID) (*models.Quiz, error)
+	ApproveQuiz(actor models.Actor, id uuid.UUID, comment string) (*models.Quiz, error)
+	RejectQuiz(actor models.Actor, id uuid.UUID, comment string) (*models.Quiz, error)
+	PublishQuiz(id uuid.UUID) (*models.Quiz, error)
+	ArchiveQuiz(id uuid.UUID) (*models.Quiz, error)
+	GetVersions(id uuid.UUID) ([]models.QuizVersion, error)
+	GetVersion(id uuid.UUID, version int) (*models.QuizVersion, *models.Quiz, error)
+	DiffVersions(id uuid.UUID, from, to int) (*models.QuizDiff, error)
+	CorrectAnswerKey(id, questionID, optionID uuid.UUID) (*models.Quiz, error)
+	DuplicateQuiz(actor models.Actor, id uuid.UUID, req *models.CopyQuizRequest) (*models.Quiz, error)
+}
+
+type quizUsecase struct {
+	quizRepo       repositories.QuizRepository
+	categoryRepo   repositories.CategoryRepository
+	tagRepo        repositories.TagRepository
+	searchRepo     repositories.SearchRepository
+	store          storage.Storage
+	reviewRequired bool
+}
+
+// NewQuizUsecase returns the quiz usecase; with reviewRequired, quizzes are
+// only published through a reviewer's approval. Attachment URLs are signed
+// by store.
+func NewQuizUsecase(quizRepo repositories.QuizRepository, categoryRepo repositories.CategoryRepository, tagRepo repositories.TagRepository, searchRepo repositories.SearchRepository, store storage.Storage, reviewRequired bool) QuizUsecase {
+	return &quizUsecase{
+		quizRepo:       quizRepo,
+		categoryRepo:   categoryRepo,
+		tagRepo:        tagRepo,
+		searchRepo:     searchRepo,
+		store:          store,
+		reviewRequired: reviewRequired,
+	}
+}
+
+// CreateQuiz creates a draft quiz owned by the actor.
+func (u *quizUsecase) CreateQuiz(actor models.Actor, req *models.QuizRequest) (*models.Quiz, error) {
+	if err := checkCategory(u.categoryRepo, req.CategoryID); err != nil {
+		return nil, err
+	}
+
+	questions, err := buildQuestions(req.Questions, req.Difficulty)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := buildTags(req.Tags, "tags")
+	if err != nil {
+		return nil, err
+	}
+
+	quiz := &models.Quiz{
+		Title:       req.Title,
+		Description: req.Description,
+		CategoryID:  req.CategoryID,
+		Difficulty:  req.Difficulty,
+		Duration:    time.Duration(req.DurationMinutes) * time.Minute,
+		Status:      constant.QuizDraft,
+		OwnerID:     &actor.ID,
+		Tags:        tags,
+		Questions:   questions,
+	}
+	applyReleasePolicies(quiz, req)
+	applyAdaptive(quiz, req)
+	if err := resolveTags(u.tagRepo, quiz); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.quizRepo.CreateQuiz(quiz); err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	return u.reindex(quiz.ID)
+}
+
+// UpdateQuiz changes the quiz details and, when req.Questions is given,
+// replaces its questions, keeping the attachments of the questions and
+// options at the same positions. A published quiz stays as it is: the
+// change goes to its draft revision, which is created as needed and
+// returned instead, so its questions can change after it has been
+// attempted. Those of a quiz that was never published can't. Editing a
+// quiz in review takes it back to draft.
+func (u *quizUsecase) UpdateQuiz(id uuid.UUID, req *models.QuizRequest) (*models.Quiz, error) {
+	quiz, err := u.GetQuizByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if quiz.Status == constant.QuizPublished {
+		if quiz, err = u.draftRevision(quiz); err != nil {
+			return nil, err
+		}
+	}
+	switch quiz.Status {
+	case constant.QuizArchived:
+		return nil, ErrQuizArchived
+	case constant.QuizInReview:
+		quiz.Status = constant.QuizDraft
+		quiz.SubmittedBy = nil
+	}
+
+	if err := checkCategory(u.categoryRepo, req.CategoryID); err != nil {
+		return nil, err
+	}
+
+	quiz.Title = req.Title
+	quiz.Description = req.Description
+	quiz.CategoryID = req.CategoryID
+	quiz.Difficulty = req.Difficulty
+	quiz.Duration = time.Duration(req.DurationMinutes) * time.Minute
+	applyReleasePolicies(quiz, req)
+	applyAdaptive(quiz, req)
+	quiz.UpdatedAt = time.Now()
+
+	if req.Questions != nil {
+		if quiz.RevisionOf == nil {
+			if err := u.checkNoAttempts(quiz.ID); err != nil {
+				return nil, err
+			}
+		}
+		quiz.QuestionsChanged = quiz.RevisionOf != nil
+
+		questions, err := buildQuestions(req.Questions, req.Difficulty)
+		if err != nil {
+			return nil, err
+		}
+		keepAttachments(quiz.Questions, questions)
+		quiz.Questions = questions
+		if err := resolveTags(u.tagRepo, quiz); err != nil {
+			return nil, err
+		}
+		if err := u.quizRepo.ReplaceQuestions(quiz); err != nil {
+			return nil, dbError(err, "quiz")
+		}
+	}
+
+	if req.Tags != nil {
+		tags, err := buildTags(req.Tags, "tags")
+		if err != nil {
+			return nil, err
+		}
+		quiz.Tags = tags
+		if err := resolveTags(u.tagRepo, quiz); err != nil {
+			return nil, err
+		}
+		if err := u.quizRepo.ReplaceQuizTags(quiz); err != nil {
+			return nil, dbError(err, "quiz")
+		}
+	}
+
+	if _, err := u.quizRepo.UpdateQuiz(quiz); err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	return u.reindex(quiz.ID)
+}
+
+// DuplicateQuiz creates a draft copy of the quiz with its questions,
+// options, answer key, rubrics and attachments under new IDs, titled
+// "<title> (copy)" unless req names it. Copying a published quiz copies
+// what students see, not its draft revision. The copy belongs to the actor.
+func (u *quizUsecase) DuplicateQuiz(actor models.Actor, id uuid.UUID, req *models.CopyQuizRequest) (*models.Quiz, error) {
+	source, err := u.GetQuizByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	quiz := copyQuiz(source)
+	quiz.OwnerID = &actor.ID
+	quiz.Title = firstNonEmpty(req.Title, copyTitle(source.Title))
+	if req.CategoryID != 0 {
+		quiz.CategoryID = req.CategoryID
+	}
+	if err := checkCategory(u.categoryRepo, quiz.CategoryID); err != nil {
+		return nil, err
+	}
+	if err := resolveTags(u.tagRepo, quiz); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.quizRepo.CreateQuiz(quiz); err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	return u.reindex(quiz.ID)
+}
+
+// DeleteQuiz deletes the quiz along with its draft revision; deleting a
+// revision discards it.
+func (u *quizUsecase) DeleteQuiz(id uuid.UUID) (*models.Quiz, error) {
+	quiz, err := u.GetQuizByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.checkNoAttempts(id); err != nil {
+		return nil, err
+	}
+
+	revision, err := u.quizRepo.FindRevision(id)
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	if revision != nil {
+		if err := u.quizRepo.DeleteQuiz(revision); err != nil {
+			return nil, dbError(err, "quiz")
+		}
+	}
+	if err := u.quizRepo.DeleteQuiz(quiz); err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	if err := u.searchRepo.RemoveQuiz(id); err != nil {
+		log.Printf("Search: failed to remove quiz %s: %v", id, err)
+	}
+	return quiz, nil
+}
+
+func (u *quizUsecase) reindex(id uuid.UUID) (*models.Quiz, error) {
+	return reindexQuiz(u.quizRepo, u.searchRepo, u.store, id)
+}
+
+// reindexQuiz reloads the quiz and refreshes its search documents; draft
+// revisions are not indexed. Indexing failures are only logged so they
+// never fail the change itself.
+func reindexQuiz(quizRepo repositories.QuizRepository, searchRepo repositories.SearchRepository, store storage.Storage, id uuid.UUID) (*models.Quiz, error) {
+	quiz, err := quizRepo.FindQuizByID(id)
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	signAttachments(store, quiz.Questions)
+	if quiz.RevisionOf != nil {
+		return quiz, nil
+	}
+	if err := searchRepo.IndexQuiz(quiz); err != nil {
+		log.Printf("Search: failed to index quiz %s: %v", id, err)
+	}
+	return quiz, nil
+}
+
+func (u *quizUsecase) GetQuizByID(id uuid.UUID) (*models.Quiz, error) {
+	quiz, err := u.quizRepo.FindQuizByID(id)
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	signAttachments(u.store, quiz.Questions)
+	return quiz, nil
+}
+
+// GetAllQuizzes lists quizzes, newest first, limited to a category subtree
+// when the filter names a category and to the filter's tags. Teachers only
+// see the quizzes they may open.
+func (u *quizUsecase) GetAllQuizzes(actor models.Actor, filter models.QuizFilter) ([]models.QuizList, error) {
+	if err := resolveQuizFilter(u.categoryRepo, &filter); err != nil {
+		return nil, err
+	}
+	filter.ViewerID = quizViewer(actor)
+
+	quiz, err := u.quizRepo.FindAllQuizzes(filter)
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+
+	quizzes := []models.QuizList{}
+	for _, q := range quiz {
+		quizzes = append(quizzes, models.QuizList{
+			ID:          q.ID,
+			Title:       q.Title,
+			Description: q.Description,
+			Category:    q.Category.Name,
+			Difficulty:  q.Difficulty,
+			Status:      q.Status,
+			OwnerID:     q.OwnerID,
+			Tags:        q.Tags,
+		})
+	}
+	return quizzes, nil
+}
+
+// resolveQuizFilter turns the category the filter names into the IDs of
+// its subtree and normalizes the filter's tags.
+func resolveQuizFilter(categoryRepo repositories.CategoryRepository, filter *models.QuizFilter) error {
+	filter.CategoryIDs = nil
+	if filter.CategoryID != 0 || filter.CategorySlug != "" {
+		var category *models.Category
+		var err error
+		if filter.CategoryID != 0 {
+			category, err = categoryRepo.GetCategoryByID(filter.CategoryID)
+		} else {
+			category, err = categoryRepo.GetCategoryBySlug(filter.CategorySlug)
+		}
+		if err != nil {
+			return dbError(err, "category")
+		}
+		if filter.CategoryIDs, err = categorySubtree(categoryRepo, category.ID); err != nil {
+			return err
+		}
+	}
+	filter.Tags = normalizeTagFilter(filter.Tags)
+	return nil
+}
+
+func checkCategory(categoryRepo repositories.CategoryRepository, id uint) error {
+	_, err := categoryRepo.GetCategoryByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.Validation("category does not exist", apperror.FieldError{
+			Field:   "category_id",
+			Rule:    "exists",
+			Message: "does not match an existing category",
+		})
+	}
+	return dbError(err, "category")
+}
+
+// CalibrateQuiz estimates the IRT parameters and success rate of the quiz's
+// multiple choice questions from the answers in finished attempts, and
+// suggests a difficulty from each success rate. Each participant's rest
+// score is their share of correct answers on the other questions they
+// answered. Questions with fewer than constant.CalibrationMinResponses
+// answers, and the quiz itself with fewer attempts, are left as they are.
+func (u *quizUsecase) CalibrateQuiz(id uuid.UUID) (*models.Quiz, error) {
+	quiz, err := u.GetQuizByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	answers, err := u.quizRepo.FindCalibrationAnswers(id)
+	if err != nil {
+		return nil, dbError(err, "answer")
+	}
+
+	type tally struct{ answered, correct int }
+	totals := map[uuid.UUID]*tally{}
+	byQuestion := map[uuid.UUID][]models.CalibrationAnswer{}
+	for _, a := range answers {
+		t, ok := totals[a.ParticipantID]
+		if !ok {
+			t = &tally{}
+			totals[a.ParticipantID] = t
+		}
+		t.answered++
+		if a.Correct {
+			t.correct++
+		}
+		byQuestion[a.QuestionID] = append(byQuestion[a.QuestionID], a)
+	}
+
+	if len(totals) >= constant.CalibrationMinResponses {
+		right := 0
+		for _, t := range totals {
+			right += t.correct
+		}
+		rate := float64(right) / float64(len(answers))
+		quiz.SuccessRate = &rate
+		quiz.SuggestedDifficulty = suggestDifficulty(rate)
+	}
+
+	now := time.Now()
+	calibrated := []models.Question{}
+	for _, q := range quiz.Questions {
+		responses := byQuestion[q.ID]
+		if q.Type == constant.QuestionEssay || len(responses) < constant.CalibrationMinResponses {
+			continue
+		}
+
+		correct := []bool{}
+		rest := []float64{}
+		for _, a := range responses {
+			t := totals[a.ParticipantID]
+			others, right := t.answered-1, t.correct
+			if a.Correct {
+				right--
+			}
+			share := 0.0
+			if others > 0 {
+				share = float64(right) / float64(others)
+			}
+			correct = append(correct, a.Correct)
+			rest = append(rest, share)
+		}
+
+		right := 0
+		for _, c := range correct {
+			if c {
+				right++
+			}
+		}
+		rate := float64(right) / float64(len(correct))
+		q.SuccessRate = &rate
+		q.SuggestedDifficulty = suggestDifficulty(rate)
+
+		item := irt.Calibrate(correct, rest)
+		q.IRTDiscrimination = item.Discrimination
+		q.IRTDifficulty = item.Difficulty
+		q.IRTResponses = len(responses)
+		q.CalibratedAt = &now
+		calibrated = append(calibrated, q)
+	}
+
+	if err := u.quizRepo.SaveCalibration(quiz, calibrated); err != nil {
+		return nil, dbError(err, "question")
+	}
+	return u.GetQuizByID(id)
+}
+
+// GetDifficultySuggestions lists the quizzes and questions whose success
+// rate points at another difficulty than the one they were given.
+func (u *quizUsecase) GetDifficultySuggestions() ([]models.DifficultySuggestion, error) {
+	suggestions, err := u.quizRepo.FindDifficultySuggestions()
+	return suggestions, dbError(err, "quiz")
+}
+
+// GetQuestions lists a page of the question bank, optionally limited to a
+// quiz and to the filter's tags, leaving out the quizzes the actor may not
+// see.
+func (u *quizUsecase) GetQuestions(actor models.Actor, filter models.QuestionFilter) ([]models.Question, int64, error) {
+	filter.ViewerID = quizViewer(actor)
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = constant.DefaultPageSize
+	}
+	if filter.PageSize > constant.MaxPageSize {
+		filter.PageSize = constant.MaxPageSize
+	}
+	filter.Tags = normalizeTagFilter(filter.Tags)
+
+	questions, total, err := u.quizRepo.FindQuestions(filter)
+	if err != nil {
+		return nil, 0, dbError(err, "question")
+	}
+	signAttachments(u.store, questions)
+	return questions, total, nil
+}
+
+// GetRevision returns the draft revision of a published quiz.
+func (u *quizUsecase) GetRevision(id uuid.UUID) (*models.Quiz, error) {
+	if _, err := u.GetQuizByID(id); err != nil {
+		return nil, err
+	}
+	revision, err := u.quizRepo.FindRevision(id)
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	if revision == nil {
+		return nil, ErrNoQuizRevision
+	}
+	signAttachments(u.store, revision.Questions)
+	return revision, nil
+}
+
+// SubmitQuiz asks for a review of a draft quiz or revision.
+func (u *quizUsecase) SubmitQuiz(actor models.Actor, id uuid.UUID) (*models.Quiz, error) {
+	quiz, err := u.GetQuizByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if quiz.Status != constant.QuizDraft {
+		return nil, ErrQuizNotDraft
+	}
+
+	quiz.Status = constant.QuizInReview
+	quiz.SubmittedBy = &actor.ID
+	quiz.ReviewedBy = nil
+	quiz.ReviewComment = ""
+	quiz.UpdatedAt = time.Now()
+	if _, err := u.quizRepo.UpdateQuiz(quiz); err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	return u.GetQuizByID(id)
+}
+
+// ApproveQuiz publishes a quiz in review, or applies a revision in review
+// to its quiz, and returns the published quiz.
+func (u *quizUsecase) ApproveQuiz(actor models.Actor, id uuid.UUID, comment string) (*models.Quiz, error) {
+	quiz, err := u.reviewable(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	quiz.ReviewedBy = &actor.ID
+	quiz.ReviewComment = comment
+	return u.publish(quiz)
+}
+
+// RejectQuiz sends a quiz in review back to draft with the reviewer's
+// comment.
+func (u *quizUsecase) RejectQuiz(actor models.Actor, id uuid.UUID, comment string) (*models.Quiz, error) {
+	quiz, err := u.reviewable(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	quiz.Status = constant.QuizDraft
+	quiz.ReviewedBy = &actor.ID
+	quiz.ReviewComment = comment
+	quiz.UpdatedAt = time.Now()
+	if _, err := u.quizRepo.UpdateQuiz(quiz); err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	return u.GetQuizByID(id)
+}
+
+// PublishQuiz publishes a draft quiz or revision without review, unless
+// reviews are required, and republishes an archived quiz.
+func (u *quizUsecase) PublishQuiz(id uuid.UUID) (*models.Quiz, error) {
+	quiz, err := u.GetQuizByID(id)
+	if err != nil {
+		return nil, err
+	}
+	switch quiz.Status {
+	case constant.QuizPublished:
+		return nil, ErrQuizPublished
+	case constant.QuizArchived:
+		// Unchanged since it was last published.
+	default:
+		if u.reviewRequired {
+			return nil, ErrQuizReviewRequired
+		}
+	}
+	return u.publish(quiz)
+}
+
+// ArchiveQuiz retires a published quiz: students no longer see it or start
+// attempts, while attempts in progress can still be finished.
+func (u *quizUsecase) ArchiveQuiz(id uuid.UUID) (*models.Quiz, error) {
+	quiz, err := u.GetQuizByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if quiz.Status != constant.QuizPublished {
+		return nil, ErrQuizNotPublished
+	}
+
+	quiz.Status = constant.QuizArchived
+	quiz.UpdatedAt = time.Now()
+	if _, err := u.quizRepo.UpdateQuiz(quiz); err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	return u.GetQuizByID(id)
+}
+
+// GetVersions lists the quiz's versions, newest first.
+func (u *quizUsecase) GetVersions(id uuid.UUID) ([]models.QuizVersion, error) {
+	if _, err := u.GetQuizByID(id); err != nil {
+		return nil, err
+	}
+	versions, err := u.quizRepo.FindVersions(id)
+	return versions, dbError(err, "quiz version")
+}
+
+// GetVersion returns one version of the quiz with the quiz as it was then.
+func (u *quizUsecase) GetVersion(id uuid.UUID, version int) (*models.QuizVersion, *models.Quiz, error) {
+	v, err := u.quizRepo.FindVersion(id, version)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrQuizVersionNotFound
+	}
+	if err != nil {
+		return nil, nil, dbError(err, "quiz version")
+	}
+	quiz, err := v.Quiz()
+	if err != nil {
+		return nil, nil, apperror.Internal(err)
+	}
+	signAttachments(u.store, quiz.Questions)
+	return v, quiz, nil
+}
+
+// DiffVersions compares two versions of the quiz. to defaults to the
+// latest version and from to the one before to.
+func (u *quizUsecase) DiffVersions(id uuid.UUID, from, to int) (*models.QuizDiff, error) {
+	quiz, err := u.GetQuizByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if to == 0 {
+		to = quiz.Version
+	}
+	if from == 0 {
+		from = to - 1
+	}
+	if from < 1 {
+		return nil, apperror.Validation("nothing to compare", apperror.FieldError{Field: "from", Rule: "min", Message: "must be an earlier version; the quiz has a single version"})
+	}
+
+	_, before, err := u.GetVersion(id, from)
+	if err != nil {
+		return nil, err
+	}
+	_, after, err := u.GetVersion(id, to)
+	if err != nil {
+		return nil, err
+	}
+	diff := diffQuizzes(before, after)
+	diff.QuizID, diff.From, diff.To = id, from, to
+	return diff, nil
+}
+
+// CorrectAnswerKey points a multiple choice question of a published quiz
+// at another option. The quiz gets a new version; attempts keep their
+// version, and so their score, until the quiz is regraded.
+func (u *quizUsecase) CorrectAnswerKey(id, questionID, optionID uuid.UUID) (*models.Quiz, error) {
+	quiz, err := u.GetQuizByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if quiz.Version == 0 {
+		return nil, ErrQuizNeverPublished
+	}
+
+	question := findQuestion(quiz, questionID)
+	if question == nil {
+		return nil, apperror.NotFound("question not found")
+	}
+	if question.Type == constant.QuestionEssay {
+		return nil, apperror.Conflict("essay questions have no answer key")
+	}
+	if !hasOption(question, optionID) {
+		return nil, apperror.Validation("option does not belong to the question", apperror.FieldError{Field: "option_id", Rule: "exists", Message: "is not an option of this question"})
+	}
+	if question.AnswerID == optionID {
+		return nil, apperror.Conflict("option is already the answer")
+	}
+
+	question.AnswerID = optionID
+	quiz.UpdatedAt = time.Now()
+	version, err := nextVersion(quiz, constant.VersionAnswerKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.quizRepo.CorrectAnswerKey(question, version); err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	return u.GetQuizByID(id)
+}
+
+// reviewable loads a quiz in review that the actor may review: admins any,
+// teachers those they did not submit themselves. Owners and editors are
+// turned away before, by the reviewer access check.
+func (u *quizUsecase) reviewable(actor models.Actor, id uuid.UUID) (*models.Quiz, error) {
+	quiz, err := u.GetQuizByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if quiz.Status != constant.QuizInReview {
+		return nil, ErrQuizNotInReview
+	}
+	if !actor.IsAdmin() && quiz.SubmittedBy != nil && *quiz.SubmittedBy == actor.ID {
+		return nil, ErrOwnQuizReview
+	}
+	return quiz, nil
+}
+
+// publish makes the quiz live or, for a draft revision, copies it onto its
+// quiz and deletes it; the revision's questions replace the quiz's only if
+// they were changed. Either way the live quiz gets a new version, and
+// attempts already made stay pinned to theirs.
+func (u *quizUsecase) publish(quiz *models.Quiz) (*models.Quiz, error) {
+	now := time.Now()
+	if quiz.RevisionOf == nil {
+		quiz.Status = constant.QuizPublished
+		quiz.PublishedAt = &now
+		quiz.UpdatedAt = now
+		version, err := nextVersion(quiz, constant.VersionPublished)
+		if err != nil {
+			return nil, err
+		}
+		if err := u.quizRepo.PublishQuiz(quiz, version); err != nil {
+			return nil, dbError(err, "quiz")
+		}
+		return u.reindex(quiz.ID)
+	}
+
+	live, err := u.GetQuizByID(*quiz.RevisionOf)
+	if err != nil {
+		return nil, err
+	}
+	if quiz.QuestionsChanged {
+		live.Questions = copyQuestions(quiz.Questions)
+	}
+	copyQuizContent(live, quiz)
+	live.Status = constant.QuizPublished
+	live.SubmittedBy = quiz.SubmittedBy
+	live.ReviewedBy = quiz.ReviewedBy
+	live.ReviewComment = quiz.ReviewComment
+	live.PublishedAt = &now
+	live.UpdatedAt = now
+	version, err := nextVersion(live, constant.VersionPublished)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.quizRepo.PublishRevision(live, quiz, version); err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	return u.reindex(live.ID)
+}
+
+// nextVersion moves the quiz on to its next version number and snapshots
+// it as that version.
+func nextVersion(quiz *models.Quiz, reason string) (*models.QuizVersion, error) {
+	quiz.Version++
+	version, err := quiz.Snapshot(quiz.Version, reason)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	version.CreatedAt = quiz.UpdatedAt
+	return version, nil
+}
+
+// draftRevision returns the published quiz's draft revision, creating it
+// as a copy of the quiz when there is none.
+func (u *quizUsecase) draftRevision(quiz *models.Quiz) (*models.Quiz, error) {
+	revision, err := u.quizRepo.FindRevision(quiz.ID)
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	if revision != nil {
+		return revision, nil
+	}
+
+	revision = copyQuiz(quiz)
+	revision.RevisionOf = &quiz.ID
+	revision.OwnerID = quiz.OwnerID
+	if _, err := u.quizRepo.CreateQuiz(revision); err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	return u.GetQuizByID(revision.ID)
+}
+
+// checkNoAttempts refuses changes that would invalidate answers already
+// given to the quiz.
+func (u *quizUsecase) checkNoAttempts(id uuid.UUID) error {
+	attempts, err := u.quizRepo.CountParticipantsByQuizID(id)
+	if err != nil {
+		return dbError(err, "quiz")
+	}
+	if attempts > 0 {
+		return ErrQuizHasAttempts
+	}
+	return nil
+}
+
+// copyQuiz returns an unsaved draft copy of the quiz, with its questions,
+// options, rubrics and attachments under new IDs.
+func copyQuiz(quiz *models.Quiz) *models.Quiz {
+	dup := &models.Quiz{
+		Status:    constant.QuizDraft,
+		Questions: copyQuestions(quiz.Questions),
+	}
+	copyQuizContent(dup, quiz)
+	return dup
+}
+
+// copyTitle marks a title as a copy's, shortening it to fit.
+func copyTitle(title string) string {
+	const suffix = " (copy)"
+	runes := []rune(title)
+	if max := 255 - len(suffix); len(runes) > max {
+		runes = runes[:max]
+	}
+	return string(runes) + suffix
+}
+
+// copyQuizContent copies what teachers edit on a quiz, apart from its
+// questions, leaving out its identity, workflow state and statistics.
+func copyQuizContent(dst, src *models.Quiz) {
+	dst.Title = src.Title
+	dst.Description = src.Description
+	dst.CategoryID = src.CategoryID
+	dst.Difficulty = src.Difficulty
+	dst.Duration = src.Duration
+	dst.ReleaseScore = src.ReleaseScore
+	dst.ReleaseMistakes = src.ReleaseMistakes
+	dst.ReleaseAnswers = src.ReleaseAnswers
+	dst.Adaptive = src.Adaptive
+	dst.AdaptiveMaxSE = src.AdaptiveMaxSE
+	dst.AdaptiveMaxItems = src.AdaptiveMaxItems
+	dst.Tags = append([]models.Tag{}, src.Tags...)
+}
+
+// copyQuestions copies questions with their options, rubrics and
+// attachments under new IDs, pointing AnswerID at the copy of the correct
+// option. Copied attachments share the original's stored files.
+func copyQuestions(questions []models.Question) []models.Question {
+	copies := []models.Question{}
+	for _, q := range questions {
+		dup := q
+		dup.ID = uuid.New()
+		dup.QuizID = uuid.Nil
+		dup.Tags = append([]models.Tag{}, q.Tags...)
+		dup.Options = []models.Option{}
+		optionIDs := map[uuid.UUID]uuid.UUID{}
+		for _, o := range q.Options {
+			option := o
+			option.ID = uuid.New()
+			option.QuestionID = dup.ID
+			if o.ID == q.AnswerID {
+				dup.AnswerID = option.ID
+			}
+			optionIDs[o.ID] = option.ID
+			dup.Options = append(dup.Options, option)
+		}
+		dup.Attachments = nil
+		for _, a := range q.Attachments {
+			attachment := a
+			attachment.ID = uuid.New()
+			attachment.QuestionID = dup.ID
+			if a.OptionID != nil {
+				optionID := optionIDs[*a.OptionID]
+				attachment.OptionID = &optionID
+			}
+			dup.Attachments = append(dup.Attachments, attachment)
+		}
+		dup.Rubric = nil
+		for _, c := range q.Rubric {
+			criterion := c
+			criterion.ID = uuid.New()
+			criterion.QuestionID = dup.ID
+			dup.Rubric = append(dup.Rubric, criterion)
+		}
+		copies = append(copies, dup)
+	}
+	return copies
+}
+
+// keepAttachments moves the attachments of the old questions onto the
+// new questions at the same positions, and those of options onto the new
+// options at the same positions. Attachments of options that no longer
+// exist are dropped.
+func keepAttachments(old, questions []models.Question) {
+	for i := range questions {
+		q := &questions[i]
+		for _, prev := range old {
+			if prev.Position != q.Position {
+				continue
+			}
+			for _, a := range prev.Attachments {
+				attachment := a
+				attachment.QuestionID = q.ID
+				if a.OptionID != nil {
+					position := optionPosition(&prev, *a.OptionID)
+					if position == 0 || position > len(q.Options) {
+						continue
+					}
+					attachment.OptionID = &q.Options[position-1].ID
+				}
+				q.Attachments = append(q.Attachments, attachment)
+			}
+		}
+	}
+}
+
+func optionPosition(question *models.Question, id uuid.UUID) int {
+	for _, o := range question.Options {
+		if o.ID == id {
+			return o.Position
+		}
+	}
+	return 0
+}
+
+// diffQuizzes lists the changes from one version of a quiz to another,
+// matching questions by position.
+func diffQuizzes(from, to *models.Quiz) *models.QuizDiff {
+	diff := &models.QuizDiff{Changes: []models.FieldChange{}, Questions: []models.QuestionDiff{}}
+	change := func(changes *[]models.FieldChange, field string, a, b interface{}) {
+		if !reflect.DeepEqual(a, b) {
+			*changes = append(*changes, models.FieldChange{Field: field, From: a, To: b})
+		}
+	}
+
+	change(&diff.Changes, "title", from.Title, to.Title)
+	change(&diff.Changes, "description", from.Description, to.Description)
+	change(&diff.Changes, "category_id", from.CategoryID, to.CategoryID)
+	change(&diff.Changes, "difficulty", from.Difficulty, to.Difficulty)
+	change(&diff.Changes, "duration_minutes", int(from.Duration/time.Minute), int(to.Duration/time.Minute))
+	change(&diff.Changes, "release_score", from.ReleaseScore, to.ReleaseScore)
+	change(&diff.Changes, "release_mistakes", from.ReleaseMistakes, to.ReleaseMistakes)
+	change(&diff.Changes, "release_answers", from.ReleaseAnswers, to.ReleaseAnswers)
+	change(&diff.Changes, "adaptive", from.Adaptive, to.Adaptive)
+	change(&diff.Changes, "adaptive_max_se", from.AdaptiveMaxSE, to.AdaptiveMaxSE)
+	change(&diff.Changes, "adaptive_max_items", from.AdaptiveMaxItems, to.AdaptiveMaxItems)
+	change(&diff.Changes, "tags", tagNames(from.Tags), tagNames(to.Tags))
+
+	before := map[int]*models.Question{}
+	after := map[int]*models.Question{}
+	positions := []int{}
+	for i := range from.Questions {
+		before[from.Questions[i].Position] = &from.Questions[i]
+		positions = append(positions, from.Questions[i].Position)
+	}
+	for i := range to.Questions {
+		after[to.Questions[i].Position] = &to.Questions[i]
+		if before[to.Questions[i].Position] == nil {
+			positions = append(positions, to.Questions[i].Position)
+		}
+	}
+	sort.Ints(positions)
+
+	for _, pos := range positions {
+		a, b := before[pos], after[pos]
+		switch {
+		case b == nil:
+			diff.Questions = append(diff.Questions, models.QuestionDiff{Position: pos, Change: constant.DiffRemoved})
+		case a == nil:
+			diff.Questions = append(diff.Questions, models.QuestionDiff{Position: pos, Change: constant.DiffAdded})
+		default:
+			changes := []models.FieldChange{}
+			change(&changes, "type", a.Type, b.Type)
+			change(&changes, "text", a.Text, b.Text)
+			change(&changes, "difficulty", a.Difficulty, b.Difficulty)
+			change(&changes, "explanation", a.Explanation, b.Explanation)
+			change(&changes, "options", optionTexts(a), optionTexts(b))
+			change(&changes, "answer", answerText(a), answerText(b))
+			change(&changes, "rubric", rubricLines(a), rubricLines(b))
+			change(&changes, "tags", tagNames(a.Tags), tagNames(b.Tags))
+			change(&changes, "attachments", attachmentNames(a), attachmentNames(b))
+			if len(changes) > 0 {
+				diff.Questions = append(diff.Questions, models.QuestionDiff{Position: pos, Change: constant.DiffChanged, Changes: changes})
+			}
+		}
+	}
+	return diff
+}
+
+func tagNames(tags []models.Tag) []string {
+	names := []string{}
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// attachmentNames names the question's attachments by file, prefixed with
+// the option position for those of options.
+func attachmentNames(question *models.Question) []string {
+	names := []string{}
+	for _, a := range question.Attachments {
+		name := a.Filename
+		if a.OptionID != nil {
+			name = fmt.Sprintf("option %d: %s", optionPosition(question, *a.OptionID), name)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func optionTexts(question *models.Question) []string {
+	texts := []string{}
+	for _, o := range question.Options {
+		texts = append(texts, o.Text)
+	}
+	return texts
+}
+
+// answerText is the text of the question's correct option, empty for
+// essays.
+func answerText(question *models.Question) string {
+	for _, o := range question.Options {
+		if o.ID == question.AnswerID {
+			return o.Text
+		}
+	}
+	return ""
+}
+
+func rubricLines(question *models.Question) []string {
+	lines := []string{}
+	for _, c := range question.Rubric {
+		lines = append(lines, fmt.Sprintf("%s (%d)", c.Description, c.Points))
+	}
+	return lines
+}
+
+// resolveTags swaps the named tags of the quiz and its questions for the
+// stored ones, creating new tags as needed.
+func resolveTags(tagRepo repositories.TagRepository, quiz *models.Quiz) error {
+	names := []string{}
+	for _, t := range quiz.Tags {
+		names = append(names, t.Name)
+	}
+	for _, q := range quiz.Questions {
+		for _, t := range q.Tags {
+			names = append(names, t.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	tags, err := tagRepo.FindOrCreateTags(names)
+	if err != nil {
+		return dbError(err, "tag")
+	}
+	byName := map[string]models.Tag{}
+	for _, t := range tags {
+		byName[t.Name] = t
+	}
+
+	for i := range quiz.Tags {
+		quiz.Tags[i] = byName[quiz.Tags[i].Name]
+	}
+	for i := range quiz.Questions {
+		for j := range quiz.Questions[i].Tags {
+			quiz.Questions[i].Tags[j] = byName[quiz.Questions[i].Tags[j].Name]
+		}
+	}
+	return nil
+}
+
+// suggestDifficulty maps a success rate to the difficulty it points at.
+func suggestDifficulty(rate float64) string {
+	switch {
+	case rate >= constant.DifficultyEasyRate:
+		return constant.DifficultyEasy
+	case rate < constant.DifficultyHardRate:
+		return constant.DifficultyHard
+	default:
+		return constant.DifficultyMedium
+	}
+}
+
+// applyReleasePolicies sets the policies given in the request, falling back
+// to the quiz's current ones and then to the defaults.
+func applyReleasePolicies(quiz *models.Quiz, req *models.QuizRequest) {
+	quiz.ReleaseScore = firstNonEmpty(req.ReleaseScore, quiz.ReleaseScore, constant.ReleaseImmediately)
+	quiz.ReleaseMistakes = firstNonEmpty(req.ReleaseMistakes, quiz.ReleaseMistakes, constant.ReleaseImmediately)
+	quiz.ReleaseAnswers = firstNonEmpty(req.ReleaseAnswers, quiz.ReleaseAnswers, constant.ReleaseAfterClose)
+}
+
+func applyAdaptive(quiz *models.Quiz, req *models.QuizRequest) {
+	quiz.Adaptive = req.Adaptive
+	quiz.AdaptiveMaxSE = req.AdaptiveMaxSE
+	if quiz.AdaptiveMaxSE == 0 {
+		quiz.AdaptiveMaxSE = constant.AdaptiveDefaultMaxSE
+	}
+	quiz.AdaptiveMaxItems = req.AdaptiveMaxItems
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// buildQuestions turns the request into questions with option IDs assigned
+// up front, so each multiple choice question can point at its correct
+// option. Essay questions carry a rubric instead of options. Questions
+// without a difficulty take the quiz's.
+func buildQuestions(reqs []models.QuestionRequest, difficulty string) ([]models.Question, error) {
+	questions := []models.Question{}
+	for i, qr := range reqs {
+		question := models.Question{
+			ID:                uuid.New(),
+			Position:          i + 1,
+			Type:              qr.Type,
+			Text:              qr.Text,
+			Difficulty:        firstNonEmpty(qr.Difficulty, difficulty),
+			Explanation:       qr.Explanation,
+			Tags:              []models.Tag{},
+			IRTDiscrimination: irt.DefaultDiscrimination,
+			IRTDifficulty:     irt.DefaultDifficulty,
+		}
+		if question.Type == "" {
+			question.Type = constant.QuestionMultipleChoice
+		}
+
+		tags, err := buildTags(qr.Tags, fmt.Sprintf("questions[%d].tags", i))
+		if err != nil {
+			return nil, err
+		}
+		question.Tags = tags
+
+		if question.Type == constant.QuestionEssay {
+			err = buildRubric(&question, qr, i)
+		} else {
+			err = buildOptions(&question, qr, i)
+		}
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, question)
+	}
+	return questions, nil
+}
+
+func buildOptions(question *models.Question, qr models.QuestionRequest, i int) error {
+	if len(qr.Options) < 2 {
+		return questionError(i, "options", "min", "must have at least 2 options")
+	}
+	if len(qr.Rubric) > 0 {
+		return questionError(i, "rubric", "excluded", "is only allowed on essay questions")
+	}
+
+	correct := 0
+	for j, or := range qr.Options {
+		option := models.Option{
+			ID:          uuid.New(),
+			QuestionID:  question.ID,
+			Position:    j + 1,
+			Text:        or.Text,
+			Explanation: or.Explanation,
+		}
+		if or.Correct {
+			correct++
+			question.AnswerID = option.ID
+		}
+		question.Options = append(question.Options, option)
+	}
+
+	if correct != 1 {
+		return questionError(i, "options", "one_correct", "must have exactly one correct option")
+	}
+	return nil
+}
+
+func buildRubric(question *models.Question, qr models.QuestionRequest, i int) error {
+	if len(qr.Options) > 0 {
+		return questionError(i, "options", "excluded", "are not allowed on essay questions")
+	}
+	if len(qr.Rubric) == 0 {
+		return questionError(i, "rubric", "required", "must have at least one criterion")
+	}
+
+	for j, cr := range qr.Rubric {
+		question.Rubric = append(question.Rubric, models.RubricCriterion{
+			QuestionID:  question.ID,
+			Position:    j + 1,
+			Description: cr.Description,
+			Points:      cr.Points,
+		})
+	}
+	return nil
+}
+
+func questionError(i int, field, rule, message string) error {
+	return apperror.Validation("invalid question", apperror.FieldError{
+		Field:   fmt.Sprintf("questions[%d].%s", i, field),
+		Rule:    rule,
+		Message: message,
+	})
+}
+
+// buildTags normalizes tag names into unsaved tags, dropping duplicates.
+// Errors name the offending entry under field.
+func buildTags(names []string, field string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	seen := map[string]bool{}
+	for i, name := range names {
+		name = normalizeTag(name)
+		switch {
+		case name == "":
+			return nil, apperror.Validation("invalid tag", apperror.FieldError{
+				Field:   fmt.Sprintf("%s[%d]", field, i),
+				Rule:    "required",
+				Message: "is required",
+			})
+		case len(name) > constant.MaxTagLength:
+			return nil, apperror.Validation("invalid tag", apperror.FieldError{
+				Field:   fmt.Sprintf("%s[%d]", field, i),
+				Rule:    "max",
+				Message: fmt.Sprintf("must be at most %d characters", constant.MaxTagLength),
+			})
+		}
+		if !seen[name] {
+			seen[name] = true
+			tags = append(tags, models.Tag{Name: name})
+		}
+	}
+	return tags, nil
+}
+
+// normalizeTagFilter normalizes the tags to filter by, dropping empty and
+// duplicate ones.
+func normalizeTagFilter(names []string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = normalizeTag(name)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			tags = append(tags, name)
+		}
+	}
+	return tags
+}
+
+// normalizeTag trims and lowercases a tag name and collapses its inner
+// whitespace to single spaces.
+func normalizeTag(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
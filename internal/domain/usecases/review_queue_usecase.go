@@ -0,0 +1,270 @@
+package usecases
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrReviewCardNotFound is also returned for other users' cards.
+var ErrReviewCardNotFound = apperror.NotFound("review card not found")
+
+type ReviewQueueUsecase interface {
+	GetDueReviews(userID uint, limit int) ([]models.DueReview, error)
+	RecordRecall(userID, cardID uint, quality int) (*models.ReviewCard, error)
+}
+
+type reviewQueueUsecase struct {
+	cardRepo       repositories.ReviewCardRepository
+	attemptRepo    repositories.AttemptRepository
+	assignmentRepo repositories.AssignmentRepository
+	quizRepo       repositories.QuizRepository
+}
+
+func NewReviewQueueUsecase(cardRepo repositories.ReviewCardRepository, attemptRepo repositories.AttemptRepository, assignmentRepo repositories.AssignmentRepository, quizRepo repositories.QuizRepository) ReviewQueueUsecase {
+	return &reviewQueueUsecase{
+		cardRepo:       cardRepo,
+		attemptRepo:    attemptRepo,
+		assignmentRepo: assignmentRepo,
+		quizRepo:       quizRepo,
+	}
+}
+
+// GetDueReviews first turns the user's newly missed questions into cards,
+// then returns the cards due now.
+func (u *reviewQueueUsecase) GetDueReviews(userID uint, limit int) ([]models.DueReview, error) {
+	if limit < 1 {
+		limit = constant.DefaultPageSize
+	}
+	if limit > constant.MaxPageSize {
+		limit = constant.MaxPageSize
+	}
+
+	now := time.Now()
+	results := newAttemptResults(u.attemptRepo, u.assignmentRepo, u.quizRepo, now)
+	if err := u.collectMissed(userID, results, now); err != nil {
+		return nil, err
+	}
+
+	cards, err := u.cardRepo.FindDueCards(userID, now, limit)
+	if err != nil {
+		return nil, dbError(err, "review card")
+	}
+
+	due := []models.DueReview{}
+	for _, card := range cards {
+		attempt, quiz, release, err := results.get(card.AttemptID)
+		if err != nil {
+			return nil, err
+		}
+		if attempt == nil {
+			continue
+		}
+		question := findQuestion(quiz, card.QuestionID)
+		if question == nil {
+			continue
+		}
+
+		item := models.DueReview{
+			CardID:       card.ID,
+			QuizID:       quiz.ID,
+			QuizTitle:    quiz.Title,
+			QuestionID:   question.ID,
+			Text:         question.Text,
+			TextHTML:     question.TextHTML,
+			Options:      []models.AttemptOption{},
+			Repetitions:  card.Repetitions,
+			IntervalDays: card.IntervalDays,
+			DueAt:        card.DueAt,
+		}
+		for _, o := range question.Options {
+			option := attemptOption(o)
+			if release.Answers {
+				option.Explanation, option.ExplanationHTML = o.Explanation, o.ExplanationHTML
+			}
+			item.Options = append(item.Options, option)
+		}
+		if release.Answers {
+			answerID := question.AnswerID
+			item.CorrectOptionID = &answerID
+			item.Explanation, item.ExplanationHTML = question.Explanation, question.ExplanationHTML
+		}
+		due = append(due, item)
+	}
+	return due, nil
+}
+
+// RecordRecall reschedules a due card by how well the user recalled it.
+func (u *reviewQueueUsecase) RecordRecall(userID, cardID uint, quality int) (*models.ReviewCard, error) {
+	card, err := u.cardRepo.FindCardByID(cardID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReviewCardNotFound
+	}
+	if err != nil {
+		return nil, dbError(err, "review card")
+	}
+	if card.UserID != userID {
+		return nil, ErrReviewCardNotFound
+	}
+
+	now := time.Now()
+	if card.DueAt.After(now) {
+		return nil, apperror.Conflict("review card is not due yet")
+	}
+
+	scheduleRecall(card, quality, now)
+	if err := u.cardRepo.SaveCard(card); err != nil {
+		return nil, dbError(err, "review card")
+	}
+	return card, nil
+}
+
+// collectMissed creates or resets a card for every newly missed question
+// whose mistakes the quiz has released, so the queue never reveals a wrong
+// answer the review page would still hide.
+func (u *reviewQueueUsecase) collectMissed(userID uint, results *attemptResults, now time.Time) error {
+	missed, err := u.cardRepo.FindMissedAnswers(userID)
+	if err != nil {
+		return dbError(err, "answer")
+	}
+
+	for _, m := range missed {
+		attempt, quiz, release, err := results.get(m.ParticipantID)
+		if err != nil {
+			return err
+		}
+		if attempt == nil || !release.Mistakes {
+			continue
+		}
+		if question := findQuestion(quiz, m.QuestionID); question == nil || question.Type != constant.QuestionMultipleChoice {
+			continue
+		}
+
+		card, err := u.cardRepo.FindCard(userID, m.QuestionID)
+		if err != nil {
+			return dbError(err, "review card")
+		}
+		if card == nil {
+			card = &models.ReviewCard{
+				UserID:     userID,
+				QuestionID: m.QuestionID,
+				EaseFactor: constant.ReviewInitialEase,
+			}
+		}
+		card.QuizID = m.QuizID
+		card.AttemptID = m.ParticipantID
+		card.Repetitions = 0
+		card.IntervalDays = 0
+		card.DueAt = now
+		card.LastMissedAt = m.AnsweredAt
+		if err := u.cardRepo.SaveCard(card); err != nil {
+			return dbError(err, "review card")
+		}
+	}
+	return nil
+}
+
+// scheduleRecall applies SM-2: a pass (quality 3 or more) grows the interval
+// from 1 to 6 days and then by the ease factor, a fail starts over at one
+// day. The ease factor moves with every answer but never drops below 1.3.
+func scheduleRecall(card *models.ReviewCard, quality int, now time.Time) {
+	if quality >= constant.ReviewPassQuality {
+		switch card.Repetitions {
+		case 0:
+			card.IntervalDays = 1
+		case 1:
+			card.IntervalDays = 6
+		default:
+			card.IntervalDays = int(math.Round(float64(card.IntervalDays) * card.EaseFactor))
+		}
+		card.Repetitions++
+	} else {
+		card.Repetitions = 0
+		card.IntervalDays = 1
+	}
+
+	miss := float64(5 - quality)
+	card.EaseFactor += 0.1 - miss*(0.08+miss*0.02)
+	if card.EaseFactor < constant.ReviewMinEase {
+		card.EaseFactor = constant.ReviewMinEase
+	}
+
+	card.DueAt = now.AddDate(0, 0, card.IntervalDays)
+	card.LastReviewedAt = &now
+	card.LastQuality = &quality
+}
+
+// attemptResults loads attempts with their quiz, at the attempt's version,
+// and result release once per request.
+type attemptResults struct {
+	attemptRepo    repositories.AttemptRepository
+	assignmentRepo repositories.AssignmentRepository
+	quizRepo       repositories.QuizRepository
+	now            time.Time
+	attempts       map[uuid.UUID]*attemptResult
+	quizzes        map[uuid.UUID]*models.Quiz
+}
+
+type attemptResult struct {
+	attempt *models.Participant
+	quiz    *models.Quiz
+	release models.ResultRelease
+}
+
+func newAttemptResults(attemptRepo repositories.AttemptRepository, assignmentRepo repositories.AssignmentRepository, quizRepo repositories.QuizRepository, now time.Time) *attemptResults {
+	return &attemptResults{
+		attemptRepo:    attemptRepo,
+		assignmentRepo: assignmentRepo,
+		quizRepo:       quizRepo,
+		now:            now,
+		attempts:       map[uuid.UUID]*attemptResult{},
+		quizzes:        map[uuid.UUID]*models.Quiz{},
+	}
+}
+
+// get returns a nil attempt when it or its quiz no longer exists.
+func (r *attemptResults) get(attemptID uuid.UUID) (*models.Participant, *models.Quiz, models.ResultRelease, error) {
+	if result, ok := r.attempts[attemptID]; ok {
+		return result.attempt, result.quiz, result.release, nil
+	}
+
+	result := &attemptResult{}
+	r.attempts[attemptID] = result
+
+	attempt, err := r.attemptRepo.FindAttemptByID(attemptID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, result.release, nil
+	}
+	if err != nil {
+		return nil, nil, result.release, dbError(err, "attempt")
+	}
+
+	quiz, ok := r.quizzes[attempt.QuizID]
+	if !ok {
+		quiz, err = r.quizRepo.FindQuizByID(attempt.QuizID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, result.release, nil
+		}
+		if err != nil {
+			return nil, nil, result.release, dbError(err, "quiz")
+		}
+		r.quizzes[attempt.QuizID] = quiz
+	}
+	if quiz, err = pinQuestions(r.quizRepo, quiz, attempt); err != nil {
+		return nil, nil, result.release, err
+	}
+
+	release, err := resultRelease(r.assignmentRepo, attempt, quiz, r.now)
+	if err != nil {
+		return nil, nil, release, err
+	}
+	result.attempt, result.quiz, result.release = attempt, quiz, release
+	return attempt, quiz, release, nil
+}
@@ -0,0 +1,56 @@
+package usecases
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+)
+
+func TestScheduleRecall(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		card         models.ReviewCard
+		quality      int
+		repetitions  int
+		intervalDays int
+		ease         float64
+	}{
+		{"first pass", models.ReviewCard{EaseFactor: 2.5}, 4, 1, 1, 2.5},
+		{"second pass", models.ReviewCard{Repetitions: 1, IntervalDays: 1, EaseFactor: 2.5}, 4, 2, 6, 2.5},
+		{"later pass grows by ease", models.ReviewCard{Repetitions: 2, IntervalDays: 6, EaseFactor: 2.5}, 4, 3, 15, 2.5},
+		{"interval rounds", models.ReviewCard{Repetitions: 3, IntervalDays: 15, EaseFactor: 2.3}, 4, 4, 35, 2.3},
+		{"perfect recall eases", models.ReviewCard{Repetitions: 2, IntervalDays: 6, EaseFactor: 2.5}, 5, 3, 15, 2.6},
+		{"hard pass stiffens", models.ReviewCard{Repetitions: 2, IntervalDays: 6, EaseFactor: 2.5}, 3, 3, 15, 2.36},
+		{"fail starts over", models.ReviewCard{Repetitions: 4, IntervalDays: 40, EaseFactor: 2.5}, 2, 0, 1, 2.18},
+		{"blackout", models.ReviewCard{Repetitions: 4, IntervalDays: 40, EaseFactor: 2.5}, 0, 0, 1, 1.7},
+		{"ease floor", models.ReviewCard{Repetitions: 1, IntervalDays: 1, EaseFactor: 1.4}, 0, 0, 1, constant.ReviewMinEase},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := tt.card
+			scheduleRecall(&card, tt.quality, now)
+
+			if card.Repetitions != tt.repetitions {
+				t.Errorf("Repetitions = %d, want %d", card.Repetitions, tt.repetitions)
+			}
+			if card.IntervalDays != tt.intervalDays {
+				t.Errorf("IntervalDays = %d, want %d", card.IntervalDays, tt.intervalDays)
+			}
+			if math.Abs(card.EaseFactor-tt.ease) > 1e-9 {
+				t.Errorf("EaseFactor = %v, want %v", card.EaseFactor, tt.ease)
+			}
+			if want := now.AddDate(0, 0, tt.intervalDays); !card.DueAt.Equal(want) {
+				t.Errorf("DueAt = %v, want %v", card.DueAt, want)
+			}
+			if card.LastQuality == nil || *card.LastQuality != tt.quality {
+				t.Errorf("LastQuality = %v, want %d", card.LastQuality, tt.quality)
+			}
+		})
+	}
+}
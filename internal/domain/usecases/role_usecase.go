@@ -6,6 +6,7 @@ import (
 
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
 	"gorm.io/gorm"
 )
 
@@ -16,6 +17,10 @@ type RoleUsecase interface {
 	GetRoleByID(id uint) (*models.Role, error)
 	GetRoleByName(rolename string) (*models.Role, error)
 	GetAllRoles() ([]models.RoleList, error)
+	GetTrashedRoles() ([]models.Role, error)
+	RestoreRole(id uint, name string) (*models.Role, error)
+	PurgeRole(id uint) (*models.Role, error)
+	PurgeExpiredRoles(before time.Time) ([]models.Role, error)
 }
 
 type roleUsecase struct {
@@ -36,38 +41,54 @@ func (u *roleUsecase) CreateRole(req *models.Role) (*models.Role, error) {
 	}
 
 	if req.Name == "" {
-		return nil, errors.New("role name is required")
+		return nil, apperror.Validation("role name is required", apperror.FieldError{Field: "name", Rule: "required", Message: "is required"})
 	}
-	return u.roleRepo.Create(role)
+
+	if err := u.checkNameAvailable(req.Name, 0); err != nil {
+		return nil, err
+	}
+
+	role, err := u.roleRepo.Create(role)
+	return role, dbError(err, "role")
 }
 
 func (u *roleUsecase) UpdateRole(role *models.Role) (*models.Role, error) {
 	if role.ID == 0 {
-		return nil, errors.New("role id is required")
+		return nil, apperror.Validation("role id is required")
+	}
+	if role.Name == "" {
+		return nil, apperror.Validation("role name is required", apperror.FieldError{Field: "name", Rule: "required", Message: "is required"})
+	}
+
+	if err := u.checkNameAvailable(role.Name, role.ID); err != nil {
+		return nil, err
 	}
 
-	return u.roleRepo.Update(role)
+	role, err := u.roleRepo.Update(role)
+	return role, dbError(err, "role")
 }
 
 func (u *roleUsecase) DeleteRole(role *models.Role) error {
 	if role.ID == 0 {
-		return errors.New("role id is required")
+		return apperror.Validation("role id is required")
 	}
-	return u.roleRepo.Delete(role)
+	return dbError(u.roleRepo.Delete(role), "role")
 }
 
 func (u *roleUsecase) GetRoleByID(id uint) (*models.Role, error) {
-	return u.roleRepo.FindRoleByID(id)
+	role, err := u.roleRepo.FindRoleByID(id)
+	return role, dbError(err, "role")
 }
 
 func (u *roleUsecase) GetRoleByName(rolename string) (*models.Role, error) {
-	return u.roleRepo.FindRoleByName(rolename)
+	role, err := u.roleRepo.FindRoleByName(rolename)
+	return role, dbError(err, "role")
 }
 
 func (u *roleUsecase) GetAllRoles() ([]models.RoleList, error) {
 	role, err := u.roleRepo.FindAllRoles()
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, "role")
 	}
 
 	roles := []models.RoleList{}
@@ -79,3 +100,96 @@ func (u *roleUsecase) GetAllRoles() ([]models.RoleList, error) {
 	}
 	return roles, nil
 }
+
+func (u *roleUsecase) GetTrashedRoles() ([]models.Role, error) {
+	roles, err := u.roleRepo.FindDeletedRoles()
+	return roles, dbError(err, "role")
+}
+
+// RestoreRole brings a trashed role back. When another active role already
+// uses its name, a new name must be given.
+func (u *roleUsecase) RestoreRole(id uint, name string) (*models.Role, error) {
+	role, err := u.roleRepo.FindDeletedRoleByID(id)
+	if err != nil {
+		return nil, dbError(err, "trashed role")
+	}
+
+	if name != "" {
+		role.Name = name
+	}
+
+	if err := u.checkNameAvailable(role.Name, role.ID); err != nil {
+		if apperror.Is(err, apperror.KindConflict) {
+			return nil, ErrRestoreConflict
+		}
+		return nil, err
+	}
+
+	if err := u.roleRepo.Restore(role); err != nil {
+		return nil, dbError(err, "role")
+	}
+
+	role.DeletedAt = gorm.DeletedAt{}
+	return role, nil
+}
+
+func (u *roleUsecase) PurgeRole(id uint) (*models.Role, error) {
+	role, err := u.roleRepo.FindDeletedRoleByID(id)
+	if err != nil {
+		return nil, dbError(err, "trashed role")
+	}
+
+	if err := u.purge(role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// PurgeExpiredRoles permanently deletes roles trashed before the given time,
+// skipping any still assigned to users.
+func (u *roleUsecase) PurgeExpiredRoles(before time.Time) ([]models.Role, error) {
+	roles, err := u.roleRepo.FindDeletedRolesBefore(before)
+	if err != nil {
+		return nil, dbError(err, "role")
+	}
+
+	purged := []models.Role{}
+	for i := range roles {
+		if err := u.purge(&roles[i]); err != nil {
+			if err == ErrStillReferenced {
+				continue
+			}
+			return purged, err
+		}
+		purged = append(purged, roles[i])
+	}
+	return purged, nil
+}
+
+func (u *roleUsecase) purge(role *models.Role) error {
+	users, err := u.roleRepo.CountUsersByRoleID(role.ID)
+	if err != nil {
+		return dbError(err, "role")
+	}
+	if users > 0 {
+		return ErrStillReferenced
+	}
+
+	return dbError(u.roleRepo.Purge(role), "role")
+}
+
+// checkNameAvailable fails with a conflict when an active role other than
+// exceptID already uses name.
+func (u *roleUsecase) checkNameAvailable(name string, exceptID uint) error {
+	existing, err := u.roleRepo.FindRoleByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return dbError(err, "role")
+	}
+	if existing.ID != exceptID {
+		return apperror.Conflict("role " + name + " already exists")
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package usecases
+
+import (
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/search"
+)
+
+// snippetLength caps the excerpt of a matching description or options.
+const snippetLength = 160
+
+type SearchUsecase interface {
+	Search(actor models.Actor, query string, limit int) ([]models.SearchHit, error)
+}
+
+type searchUsecase struct {
+	searchRepo repositories.SearchRepository
+}
+
+func NewSearchUsecase(searchRepo repositories.SearchRepository) SearchUsecase {
+	return &searchUsecase{searchRepo: searchRepo}
+}
+
+// Search finds the quizzes and questions with a word starting with every
+// word of the query, best match first, and highlights the matches. Teachers
+// only find the quizzes they may see.
+func (u *searchUsecase) Search(actor models.Actor, query string, limit int) ([]models.SearchHit, error) {
+	terms := search.Terms(query)
+	if len(terms) == 0 {
+		return nil, apperror.Validation("search query is required", apperror.FieldError{
+			Field:   "q",
+			Rule:    "required",
+			Message: "must contain at least one word",
+		})
+	}
+	if limit < 1 {
+		limit = constant.DefaultPageSize
+	}
+	if limit > constant.MaxPageSize {
+		limit = constant.MaxPageSize
+	}
+
+	hits, err := u.searchRepo.Search(terms, limit, quizViewer(actor))
+	if err != nil {
+		return nil, dbError(err, "search")
+	}
+	for i := range hits {
+		hits[i].Highlight = search.Highlight(hits[i].Text, terms, 0)
+		hits[i].Snippet = search.Highlight(hits[i].Body, terms, snippetLength)
+	}
+	return hits, nil
+}
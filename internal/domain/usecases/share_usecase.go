@@ -0,0 +1,212 @@
+package usecases
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotQuizOwner    = apperror.Forbidden("only the quiz's owner can do this")
+	ErrQuizReadOnly    = apperror.Forbidden("quiz is shared with you read-only")
+	ErrShareNotFound   = apperror.NotFound("quiz is not shared with this user")
+	ErrNotQuizReviewer = apperror.Forbidden("quizzes can't be reviewed by their owner or editors")
+)
+
+// quizRoleRank orders the quiz roles so that each includes those below it.
+var quizRoleRank = map[string]int{
+	constant.QuizRoleViewer: 1,
+	constant.QuizRoleEditor: 2,
+	constant.QuizRoleOwner:  3,
+}
+
+type ShareUsecase interface {
+	Authorize(actor models.Actor, quizID uuid.UUID, role string) error
+	GetCollaborators(quizID uuid.UUID) ([]models.Collaborator, error)
+	ShareQuiz(quizID uuid.UUID, req *models.ShareQuizRequest) (*models.QuizShare, error)
+	UnshareQuiz(quizID uuid.UUID, userID uint) (*models.QuizShare, error)
+	TransferQuiz(quizID uuid.UUID, req *models.TransferQuizRequest) ([]models.Collaborator, error)
+}
+
+type shareUsecase struct {
+	shareRepo repositories.ShareRepository
+	userRepo  repositories.UserRepository
+}
+
+func NewShareUsecase(shareRepo repositories.ShareRepository, userRepo repositories.UserRepository) ShareUsecase {
+	return &shareUsecase{
+		shareRepo: shareRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// Authorize checks that the actor has at least the role on the quiz, or
+// for the reviewer role that they can view it without owning or editing
+// it. Admins may do anything, and they alone may change a quiz without an
+// owner. Every teacher may view a quiz past the draft stage. Quizzes the
+// actor can't view are reported as not found.
+func (u *shareUsecase) Authorize(actor models.Actor, quizID uuid.UUID, role string) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+	access, err := u.findAccess(quizID, actor.ID)
+	if err != nil {
+		return err
+	}
+	if role == constant.QuizRoleReviewer {
+		if quizRoleRank[access.Role] >= quizRoleRank[constant.QuizRoleEditor] {
+			return ErrNotQuizReviewer
+		}
+		role = constant.QuizRoleViewer
+	}
+
+	granted := access.Role
+	if granted == "" {
+		switch access.Status {
+		case constant.QuizInReview, constant.QuizPublished, constant.QuizArchived:
+			granted = constant.QuizRoleViewer
+		default:
+			return ErrQuizNotFound
+		}
+	}
+
+	if quizRoleRank[granted] >= quizRoleRank[role] {
+		return nil
+	}
+	if role == constant.QuizRoleOwner {
+		return ErrNotQuizOwner
+	}
+	return ErrQuizReadOnly
+}
+
+// GetCollaborators returns the quiz's owner and the teachers it is shared
+// with. A draft revision has those of its quiz.
+func (u *shareUsecase) GetCollaborators(quizID uuid.UUID) ([]models.Collaborator, error) {
+	access, err := u.findAccess(quizID, 0)
+	if err != nil {
+		return nil, err
+	}
+	collaborators, err := u.shareRepo.FindCollaborators(sharedQuizID(access))
+	return collaborators, dbError(err, "quiz share")
+}
+
+// ShareQuiz shares the quiz with a teacher or changes their role on it.
+func (u *shareUsecase) ShareQuiz(quizID uuid.UUID, req *models.ShareQuizRequest) (*models.QuizShare, error) {
+	access, err := u.findAccess(quizID, 0)
+	if err != nil {
+		return nil, err
+	}
+	user, err := u.findTeacher(req.User)
+	if err != nil {
+		return nil, err
+	}
+	if access.OwnerID != nil && *access.OwnerID == user.ID {
+		return nil, apperror.Validation("user owns the quiz", apperror.FieldError{Field: "user", Rule: "owner", Message: "already owns the quiz"})
+	}
+
+	shared := sharedQuizID(access)
+	share, err := u.shareRepo.FindShare(shared, user.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		share = &models.QuizShare{QuizID: shared, UserID: user.ID}
+	} else if err != nil {
+		return nil, dbError(err, "quiz share")
+	}
+	share.Role = req.Role
+	if err := u.shareRepo.SaveShare(share); err != nil {
+		return nil, dbError(err, "quiz share")
+	}
+	return share, nil
+}
+
+// UnshareQuiz takes the teacher's access to the quiz away.
+func (u *shareUsecase) UnshareQuiz(quizID uuid.UUID, userID uint) (*models.QuizShare, error) {
+	access, err := u.findAccess(quizID, 0)
+	if err != nil {
+		return nil, err
+	}
+	share, err := u.shareRepo.FindShare(sharedQuizID(access), userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrShareNotFound
+	}
+	if err != nil {
+		return nil, dbError(err, "quiz share")
+	}
+	if err := u.shareRepo.DeleteShare(share); err != nil {
+		return nil, dbError(err, "quiz share")
+	}
+	return share, nil
+}
+
+// TransferQuiz makes another teacher the owner of the quiz and returns its
+// collaborators afterwards. The previous owner stays on as an editor.
+func (u *shareUsecase) TransferQuiz(quizID uuid.UUID, req *models.TransferQuizRequest) ([]models.Collaborator, error) {
+	access, err := u.findAccess(quizID, 0)
+	if err != nil {
+		return nil, err
+	}
+	user, err := u.findTeacher(req.User)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := sharedQuizID(access)
+	if err := u.shareRepo.TransferQuiz(shared, access.OwnerID, user.ID); err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	collaborators, err := u.shareRepo.FindCollaborators(shared)
+	return collaborators, dbError(err, "quiz share")
+}
+
+func (u *shareUsecase) findAccess(quizID uuid.UUID, userID uint) (*models.QuizAccess, error) {
+	access, err := u.shareRepo.FindAccess(quizID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrQuizNotFound
+	}
+	return access, dbError(err, "quiz")
+}
+
+// findTeacher looks the teacher up by email when the value has an @, by
+// username otherwise. Quizzes can be shared with admins too.
+func (u *shareUsecase) findTeacher(value string) (*models.User, error) {
+	var user *models.User
+	var err error
+	if strings.Contains(value, "@") {
+		user, err = u.userRepo.FindUserByEmail(value)
+	} else {
+		user, err = u.userRepo.FindUserByUsername(value)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.Validation("user does not exist", apperror.FieldError{Field: "user", Rule: "exists", Message: "does not match an existing user"})
+	}
+	if err != nil {
+		return nil, dbError(err, "user")
+	}
+	if user.RoleID != constant.RoleTeacherID && user.RoleID != constant.RoleAdminID {
+		return nil, apperror.Validation("user is not a teacher", apperror.FieldError{Field: "user", Rule: "teacher", Message: "must be a teacher"})
+	}
+	return user, nil
+}
+
+// sharedQuizID is the quiz whose shares apply: the quiz itself, or the one
+// a draft revision belongs to.
+func sharedQuizID(access *models.QuizAccess) uuid.UUID {
+	if access.RevisionOf != nil {
+		return *access.RevisionOf
+	}
+	return access.QuizID
+}
+
+// quizViewer is the user whose visible quizzes a listing is limited to, or
+// 0 for admins, who see them all.
+func quizViewer(actor models.Actor) uint {
+	if actor.IsAdmin() {
+		return 0
+	}
+	return actor.ID
+}
@@ -0,0 +1,112 @@
+package usecases
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTagNotFound = apperror.NotFound("tag not found")
+
+	// ErrTagNameTaken is returned when renaming a tag to the name of
+	// another one; merging them is the way to combine the two.
+	ErrTagNameTaken = apperror.Conflict("another tag already has this name, merge the tags instead")
+)
+
+type TagUsecase interface {
+	SearchTags(prefix string, limit int) ([]models.TagUsage, error)
+	RenameTag(id uint, name string) (*models.Tag, error)
+	MergeTag(id, targetID uint) (*models.Tag, error)
+}
+
+type tagUsecase struct {
+	tagRepo repositories.TagRepository
+}
+
+func NewTagUsecase(tagRepo repositories.TagRepository) TagUsecase {
+	return &tagUsecase{tagRepo: tagRepo}
+}
+
+// SearchTags suggests the tags starting with prefix, most used first.
+func (u *tagUsecase) SearchTags(prefix string, limit int) ([]models.TagUsage, error) {
+	if limit < 1 {
+		limit = constant.DefaultPageSize
+	}
+	if limit > constant.MaxPageSize {
+		limit = constant.MaxPageSize
+	}
+
+	tags, err := u.tagRepo.SearchTags(normalizeTag(prefix), limit)
+	return tags, dbError(err, "tag")
+}
+
+// RenameTag renames a tag on every quiz and question that carries it.
+func (u *tagUsecase) RenameTag(id uint, name string) (*models.Tag, error) {
+	tag, err := u.findTag(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := buildTags([]string{name}, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	tag.Name = tags[0].Name
+	tag.UpdatedAt = time.Now()
+	err = u.tagRepo.UpdateTag(tag)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, ErrTagNameTaken
+	}
+	if err != nil {
+		return nil, dbError(err, "tag")
+	}
+	return tag, nil
+}
+
+// MergeTag moves a tag's quizzes and questions to the target tag, deletes
+// it and returns the target.
+func (u *tagUsecase) MergeTag(id, targetID uint) (*models.Tag, error) {
+	source, err := u.findTag(id)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := u.tagRepo.FindTagByID(targetID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.Validation("target tag does not exist", apperror.FieldError{
+			Field:   "target_id",
+			Rule:    "exists",
+			Message: "does not match an existing tag",
+		})
+	}
+	if err != nil {
+		return nil, dbError(err, "tag")
+	}
+	if target.ID == source.ID {
+		return nil, apperror.Validation("cannot merge a tag into itself", apperror.FieldError{
+			Field:   "target_id",
+			Rule:    "ne",
+			Message: "must be another tag",
+		})
+	}
+
+	if err := u.tagRepo.MergeTag(source, target); err != nil {
+		return nil, dbError(err, "tag")
+	}
+	return target, nil
+}
+
+func (u *tagUsecase) findTag(id uint) (*models.Tag, error) {
+	tag, err := u.tagRepo.FindTagByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTagNotFound
+	}
+	return tag, dbError(err, "tag")
+}
@@ -0,0 +1,173 @@
+package usecases
+
+import (
+	"errors"
+
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/storage"
+	"gorm.io/gorm"
+)
+
+var ErrTemplateNotFound = apperror.NotFound("quiz template not found")
+
+type TemplateUsecase interface {
+	CreateTemplate(actor models.Actor, req *models.QuizTemplateRequest) (*models.QuizTemplate, error)
+	UpdateTemplate(id uint, req *models.UpdateQuizTemplateRequest) (*models.QuizTemplate, error)
+	DeleteTemplate(id uint) (*models.QuizTemplate, error)
+	GetTemplates(filter models.QuizFilter) ([]models.QuizTemplate, error)
+	GetTemplate(id uint) (*models.QuizTemplate, *models.Quiz, error)
+	CreateQuiz(actor models.Actor, id uint, req *models.CopyQuizRequest) (*models.Quiz, error)
+}
+
+type templateUsecase struct {
+	templateRepo repositories.TemplateRepository
+	quizRepo     repositories.QuizRepository
+	categoryRepo repositories.CategoryRepository
+	tagRepo      repositories.TagRepository
+	searchRepo   repositories.SearchRepository
+	store        storage.Storage
+}
+
+// NewTemplateUsecase returns the usecase of the template library. Quizzes
+// created from templates are saved and indexed like any other.
+func NewTemplateUsecase(templateRepo repositories.TemplateRepository, quizRepo repositories.QuizRepository, categoryRepo repositories.CategoryRepository, tagRepo repositories.TagRepository, searchRepo repositories.SearchRepository, store storage.Storage) TemplateUsecase {
+	return &templateUsecase{
+		templateRepo: templateRepo,
+		quizRepo:     quizRepo,
+		categoryRepo: categoryRepo,
+		tagRepo:      tagRepo,
+		searchRepo:   searchRepo,
+		store:        store,
+	}
+}
+
+// CreateTemplate adds a snapshot of the quiz to the library.
+func (u *templateUsecase) CreateTemplate(actor models.Actor, req *models.QuizTemplateRequest) (*models.QuizTemplate, error) {
+	quiz, err := u.quizRepo.FindQuizByID(req.QuizID)
+	if err != nil {
+		return nil, templateQuizError(err)
+	}
+
+	template := &models.QuizTemplate{
+		Name:        firstNonEmpty(req.Name, quiz.Title),
+		Description: req.Description,
+		CreatedBy:   actor.ID,
+	}
+	if err := template.SetQuiz(quiz); err != nil {
+		return nil, apperror.Internal(err)
+	}
+	if err := u.templateRepo.CreateTemplate(template); err != nil {
+		return nil, dbError(err, "quiz template")
+	}
+	return template, nil
+}
+
+// UpdateTemplate renames the template and, when req names a quiz, replaces
+// its content with a new snapshot of that quiz.
+func (u *templateUsecase) UpdateTemplate(id uint, req *models.UpdateQuizTemplateRequest) (*models.QuizTemplate, error) {
+	template, err := u.findTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	template.Name = req.Name
+	template.Description = req.Description
+	if req.QuizID != nil {
+		quiz, err := u.quizRepo.FindQuizByID(*req.QuizID)
+		if err != nil {
+			return nil, templateQuizError(err)
+		}
+		if err := template.SetQuiz(quiz); err != nil {
+			return nil, apperror.Internal(err)
+		}
+	}
+	if err := u.templateRepo.UpdateTemplate(template); err != nil {
+		return nil, dbError(err, "quiz template")
+	}
+	return template, nil
+}
+
+// DeleteTemplate removes the template from the library. Quizzes created
+// from it stay as they are.
+func (u *templateUsecase) DeleteTemplate(id uint) (*models.QuizTemplate, error) {
+	template, err := u.findTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.templateRepo.DeleteTemplate(template); err != nil {
+		return nil, dbError(err, "quiz template")
+	}
+	return template, nil
+}
+
+// GetTemplates lists the library by name, optionally only the templates
+// in a category and its subcategories.
+func (u *templateUsecase) GetTemplates(filter models.QuizFilter) ([]models.QuizTemplate, error) {
+	if err := resolveQuizFilter(u.categoryRepo, &filter); err != nil {
+		return nil, err
+	}
+	templates, err := u.templateRepo.FindTemplates(filter.CategoryIDs)
+	return templates, dbError(err, "quiz template")
+}
+
+// GetTemplate returns the template with the quiz it holds.
+func (u *templateUsecase) GetTemplate(id uint) (*models.QuizTemplate, *models.Quiz, error) {
+	template, err := u.findTemplate(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	quiz, err := template.Quiz()
+	if err != nil {
+		return nil, nil, apperror.Internal(err)
+	}
+	signAttachments(u.store, quiz.Questions)
+	return template, quiz, nil
+}
+
+// CreateQuiz creates a draft quiz from the template, with its questions,
+// options, answer key, rubrics and attachments under new IDs. It takes the
+// title and category of the template's quiz unless req gives others, and
+// belongs to the actor.
+func (u *templateUsecase) CreateQuiz(actor models.Actor, id uint, req *models.CopyQuizRequest) (*models.Quiz, error) {
+	_, source, err := u.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	quiz := copyQuiz(source)
+	quiz.OwnerID = &actor.ID
+	quiz.Title = firstNonEmpty(req.Title, source.Title)
+	if req.CategoryID != 0 {
+		quiz.CategoryID = req.CategoryID
+	}
+	if err := checkCategory(u.categoryRepo, quiz.CategoryID); err != nil {
+		return nil, err
+	}
+	// The template's tags may have been renamed or merged since.
+	if err := resolveTags(u.tagRepo, quiz); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.quizRepo.CreateQuiz(quiz); err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	return reindexQuiz(u.quizRepo, u.searchRepo, u.store, quiz.ID)
+}
+
+func (u *templateUsecase) findTemplate(id uint) (*models.QuizTemplate, error) {
+	template, err := u.templateRepo.FindTemplateByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTemplateNotFound
+	}
+	return template, dbError(err, "quiz template")
+}
+
+// templateQuizError reports a missing quiz against the request's quiz_id.
+func templateQuizError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.Validation("quiz does not exist", apperror.FieldError{Field: "quiz_id", Rule: "exists", Message: "does not match an existing quiz"})
+	}
+	return dbError(err, "quiz")
+}
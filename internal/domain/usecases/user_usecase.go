@@ -2,14 +2,21 @@ package usecases
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
 	"github.com/Arasy41/go-gin-quiz-api/pkg/utils"
 	"gorm.io/gorm"
 )
 
+// ErrInvalidCredentials is returned by Login for an unknown user or a wrong
+// password alike, so callers can't probe which usernames exist.
+var ErrInvalidCredentials = apperror.Unauthorized("invalid username or password")
+
 type UserUsecase interface {
 	CreateUser(username, email, password string, roleId uint) (*models.User, error)
 	UpdateUser(user *models.User) (*models.User, error)
@@ -19,7 +26,12 @@ type UserUsecase interface {
 	GetUserByEmail(email string) (*models.User, error)
 	GetAllUsers() ([]models.UserList, error)
 	GetUsersByRoleID(roleID uint) ([]models.User, error)
+	Login(username, password string) (*models.User, error)
 	ChangePassword(userID uint, oldPassword, newPassword string) error
+	GetTrashedUsers() ([]models.UserList, error)
+	RestoreUser(id uint, username, email string) (*models.User, error)
+	PurgeUser(id uint) (*models.User, error)
+	PurgeExpiredUsers(before time.Time) ([]models.User, error)
 }
 
 type userUsecase struct {
@@ -31,9 +43,17 @@ func NewUserUsecase(repo repositories.UserRepository) UserUsecase {
 }
 
 func (u *userUsecase) CreateUser(username, email, password string, roleId uint) (*models.User, error) {
+	if err := validatePassword("password", password); err != nil {
+		return nil, err
+	}
+
+	if err := u.checkUsernameAndEmail(username, email, 0); err != nil {
+		return nil, err
+	}
+
 	hashedPassword, err := utils.HashPassword(password)
 	if err != nil {
-		return nil, err
+		return nil, apperror.Internal(err)
 	}
 
 	user := &models.User{
@@ -46,19 +66,28 @@ func (u *userUsecase) CreateUser(username, email, password string, roleId uint)
 		DeletedAt: gorm.DeletedAt{},
 	}
 
-	return u.userRepo.CreateUser(user)
+	user, err = u.userRepo.CreateUser(user)
+	return user, dbError(err, "user")
 }
 
 func (u *userUsecase) UpdateUser(user *models.User) (*models.User, error) {
 	if user.ID == 0 {
-		return nil, errors.New("user id is required")
+		return nil, apperror.Validation("user id is required")
+	}
+
+	if err := u.checkUsernameAndEmail(user.Username, user.Email, user.ID); err != nil {
+		return nil, err
 	}
 
 	// Cek apakah password diubah, jika ya maka hash ulang
 	if user.Password != "" {
+		if err := validatePassword("password", user.Password); err != nil {
+			return nil, err
+		}
+
 		hashedPassword, err := utils.HashPassword(user.Password)
 		if err != nil {
-			return nil, err
+			return nil, apperror.Internal(err)
 		}
 		user.Password = hashedPassword
 	}
@@ -67,26 +96,26 @@ func (u *userUsecase) UpdateUser(user *models.User) (*models.User, error) {
 	user.UpdatedAt = time.Now()
 
 	// Gunakan repository untuk melakukan update
-	updatedUser, err := u.userRepo.UpdateUser(user)
-	if err != nil {
-		return nil, err
+	if _, err := u.userRepo.UpdateUser(user); err != nil {
+		return nil, dbError(err, "user")
 	}
 
-	return updatedUser, nil
+	// Reload so fields left out of the update are returned as stored.
+	return u.GetUserByID(user.ID)
 }
 
 func (u *userUsecase) DeleteUser(user *models.User) error {
-	return u.userRepo.DeleteUser(user)
+	return dbError(u.userRepo.DeleteUser(user), "user")
 }
 
 func (u *userUsecase) GetUserByID(id uint) (*models.User, error) {
 	user, err := u.userRepo.FindUserByID(id)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, "user")
 	}
 
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, apperror.NotFound("user not found")
 	}
 
 	return user, nil
@@ -94,22 +123,30 @@ func (u *userUsecase) GetUserByID(id uint) (*models.User, error) {
 
 func (u *userUsecase) GetUserByUsername(username string) (*models.User, error) {
 	if username == "" {
-		return nil, errors.New("username is required")
+		return nil, apperror.Validation("username is required")
+	}
+	user, err := u.userRepo.FindUserByUsername(username)
+	if err != nil {
+		return nil, dbError(err, "user")
 	}
-	return u.userRepo.FindUserByUsername(username)
+	return user, nil
 }
 
 func (u *userUsecase) GetUserByEmail(email string) (*models.User, error) {
 	if email == "" {
-		return nil, errors.New("email is required")
+		return nil, apperror.Validation("email is required")
+	}
+	user, err := u.userRepo.FindUserByEmail(email)
+	if err != nil {
+		return nil, dbError(err, "user")
 	}
-	return u.userRepo.FindUserByEmail(email)
+	return user, nil
 }
 
 func (u *userUsecase) GetAllUsers() ([]models.UserList, error) {
 	user, err := u.userRepo.FindAllUsers()
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, "user")
 	}
 
 	users := []models.UserList{}
@@ -118,7 +155,6 @@ func (u *userUsecase) GetAllUsers() ([]models.UserList, error) {
 			ID:       u.ID,
 			Username: u.Username,
 			Email:    u.Email,
-			Password: u.Password,
 			RoleName: u.Role.Name,
 		})
 	}
@@ -126,31 +162,172 @@ func (u *userUsecase) GetAllUsers() ([]models.UserList, error) {
 }
 
 func (u *userUsecase) GetUsersByRoleID(roleID uint) ([]models.User, error) {
-	return u.userRepo.FindUserByRoleID(roleID)
+	users, err := u.userRepo.FindUserByRoleID(roleID)
+	return users, dbError(err, "user")
+}
+
+// Login checks the credentials and returns the user with its role loaded.
+func (u *userUsecase) Login(username, password string) (*models.User, error) {
+	user, err := u.userRepo.FindUserByUsername(username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, dbError(err, "user")
+	}
+
+	if !utils.CheckPasswordHash(password, user.Password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
 }
 
 func (u *userUsecase) ChangePassword(userID uint, oldPassword, newPassword string) error {
-	user, err := u.userRepo.FindUserByID(userID)
+	user, err := u.GetUserByID(userID)
 	if err != nil {
 		return err
 	}
 
 	ok := utils.CheckPasswordHash(oldPassword, user.Password)
 	if !ok {
-		return errors.New("old password does not match")
+		return apperror.Validation("old password does not match", apperror.FieldError{
+			Field:   "old_password",
+			Rule:    "match",
+			Message: "does not match the current password",
+		})
+	}
+
+	if err := validatePassword("new_password", newPassword); err != nil {
+		return err
 	}
 
 	hashedPassword, err := utils.HashPassword(newPassword)
 	if err != nil {
-		return err
+		return apperror.Internal(err)
 	}
 
 	user.Password = hashedPassword
 
 	_, err = u.userRepo.UpdateUser(user)
 	if err != nil {
-		return err
+		return dbError(err, "user")
+	}
+
+	return nil
+}
+
+func (u *userUsecase) GetTrashedUsers() ([]models.UserList, error) {
+	user, err := u.userRepo.FindDeletedUsers()
+	if err != nil {
+		return nil, dbError(err, "user")
+	}
+
+	users := []models.UserList{}
+	for _, u := range user {
+		users = append(users, models.UserList{
+			ID:       u.ID,
+			Username: u.Username,
+			Email:    u.Email,
+			RoleName: u.Role.Name,
+		})
+	}
+	return users, nil
+}
+
+// RestoreUser brings a trashed user back. A new username or email can be
+// given when an active account has taken the old one in the meantime.
+func (u *userUsecase) RestoreUser(id uint, username, email string) (*models.User, error) {
+	user, err := u.userRepo.FindDeletedUserByID(id)
+	if err != nil {
+		return nil, dbError(err, "trashed user")
+	}
+
+	if username != "" {
+		user.Username = username
+	}
+	if email != "" {
+		user.Email = email
+	}
+
+	conflicts, err := u.userRepo.CountActiveUsersByUsernameOrEmail(user.Username, user.Email)
+	if err != nil {
+		return nil, dbError(err, "user")
+	}
+	if conflicts > 0 {
+		return nil, ErrRestoreConflict
+	}
+
+	if err := u.userRepo.RestoreUser(user); err != nil {
+		return nil, dbError(err, "user")
+	}
+
+	user.DeletedAt = gorm.DeletedAt{}
+	return user, nil
+}
+
+func (u *userUsecase) PurgeUser(id uint) (*models.User, error) {
+	user, err := u.userRepo.FindDeletedUserByID(id)
+	if err != nil {
+		return nil, dbError(err, "trashed user")
 	}
 
+	if err := u.userRepo.PurgeUser(user); err != nil {
+		return nil, dbError(err, "user")
+	}
+	return user, nil
+}
+
+// PurgeExpiredUsers permanently deletes users trashed before the given time.
+func (u *userUsecase) PurgeExpiredUsers(before time.Time) ([]models.User, error) {
+	users, err := u.userRepo.FindDeletedUsersBefore(before)
+	if err != nil {
+		return nil, dbError(err, "user")
+	}
+
+	purged := []models.User{}
+	for i := range users {
+		if err := u.userRepo.PurgeUser(&users[i]); err != nil {
+			return purged, dbError(err, "user")
+		}
+		purged = append(purged, users[i])
+	}
+	return purged, nil
+}
+
+// checkUsernameAndEmail fails with a conflict when another user than
+// exceptID already has the username or email.
+func (u *userUsecase) checkUsernameAndEmail(username, email string, exceptID uint) error {
+	if username != "" {
+		existing, err := u.userRepo.FindUserByUsername(username)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return dbError(err, "user")
+		}
+		if err == nil && existing.ID != exceptID {
+			return apperror.Conflict("username already exists")
+		}
+	}
+
+	if email != "" {
+		existing, err := u.userRepo.FindUserByEmail(email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return dbError(err, "user")
+		}
+		if err == nil && existing.ID != exceptID {
+			return apperror.Conflict("email already exists")
+		}
+	}
+
+	return nil
+}
+
+func validatePassword(field, password string) error {
+	if len(password) < constant.MinPasswordLength || len(password) > constant.MaxPasswordLength {
+		return apperror.Validation("password does not meet the length policy", apperror.FieldError{
+			Field:   field,
+			Rule:    "length",
+			Message: fmt.Sprintf("must be between %d and %d characters", constant.MinPasswordLength, constant.MaxPasswordLength),
+		})
+	}
 	return nil
 }
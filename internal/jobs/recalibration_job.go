@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/config"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+)
+
+// RecalibrationJob refreshes every quiz's IRT parameters, success rates and
+// suggested difficulties, recomputing each from all of the quiz's finished
+// attempts.
+type RecalibrationJob struct {
+	quizUc   usecases.QuizUsecase
+	interval time.Duration
+}
+
+func NewRecalibrationJob(cfg *config.Config, quizUc usecases.QuizUsecase) *RecalibrationJob {
+	return &RecalibrationJob{
+		quizUc:   quizUc,
+		interval: time.Duration(cfg.RecalibrationIntervalHour) * time.Hour,
+	}
+}
+
+// Start runs the recalibration once immediately and then on every interval.
+func (j *RecalibrationJob) Start() {
+	go func() {
+		j.Run()
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			j.Run()
+		}
+	}()
+}
+
+// Run performs a single recalibration pass. A quiz that fails is logged and
+// skipped so the others are still recalibrated.
+func (j *RecalibrationJob) Run() {
+	// The job acts as an admin, who sees every teacher's quizzes.
+	system := models.Actor{Role: constant.RoleAdmin}
+	quizzes, err := j.quizUc.GetAllQuizzes(system, models.QuizFilter{})
+	if err != nil {
+		log.Printf("Recalibration: failed to list quizzes: %v", err)
+		return
+	}
+
+	calibrated := 0
+	for _, q := range quizzes {
+		if _, err := j.quizUc.CalibrateQuiz(q.ID); err != nil {
+			log.Printf("Recalibration: failed to calibrate quiz %s: %v", q.ID, err)
+			continue
+		}
+		calibrated++
+	}
+
+	suggestions, err := j.quizUc.GetDifficultySuggestions()
+	if err != nil {
+		log.Printf("Recalibration: failed to list difficulty suggestions: %v", err)
+	}
+
+	log.Printf("Recalibration: calibrated %d of %d quizzes, %d difficulty suggestions",
+		calibrated, len(quizzes), len(suggestions))
+}
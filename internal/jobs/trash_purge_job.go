@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/config"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/usecases"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+)
+
+// TrashPurgeJob permanently deletes users, roles and categories that have
+// been in the trash longer than the configured retention period.
+type TrashPurgeJob struct {
+	userUc     usecases.UserUsecase
+	roleUc     usecases.RoleUsecase
+	categoryUc usecases.CategoryUsecase
+	auditUc    usecases.AuditLogUsecase
+	retention  time.Duration
+	interval   time.Duration
+}
+
+func NewTrashPurgeJob(cfg *config.Config, userUc usecases.UserUsecase, roleUc usecases.RoleUsecase, categoryUc usecases.CategoryUsecase, auditUc usecases.AuditLogUsecase) *TrashPurgeJob {
+	return &TrashPurgeJob{
+		userUc:     userUc,
+		roleUc:     roleUc,
+		categoryUc: categoryUc,
+		auditUc:    auditUc,
+		retention:  time.Duration(cfg.TrashRetentionDays) * 24 * time.Hour,
+		interval:   time.Duration(cfg.TrashPurgeIntervalHour) * time.Hour,
+	}
+}
+
+// Start runs the purge once immediately and then on every interval.
+func (j *TrashPurgeJob) Start() {
+	go func() {
+		j.Run()
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			j.Run()
+		}
+	}()
+}
+
+// Run performs a single purge pass. Users go first so that roles they held
+// are no longer referenced when roles are purged.
+func (j *TrashPurgeJob) Run() {
+	before := time.Now().Add(-j.retention)
+
+	users, err := j.userUc.PurgeExpiredUsers(before)
+	if err != nil {
+		log.Printf("Trash purge: failed to purge users: %v", err)
+	}
+	for i := range users {
+		j.audit(constant.AuditEntityUser, users[i].ID, &users[i])
+	}
+
+	roles, err := j.roleUc.PurgeExpiredRoles(before)
+	if err != nil {
+		log.Printf("Trash purge: failed to purge roles: %v", err)
+	}
+	for i := range roles {
+		j.audit(constant.AuditEntityRole, roles[i].ID, &roles[i])
+	}
+
+	categories, err := j.categoryUc.PurgeExpiredCategories(before)
+	if err != nil {
+		log.Printf("Trash purge: failed to purge categories: %v", err)
+	}
+	for i := range categories {
+		j.audit(constant.AuditEntityCategory, categories[i].ID, &categories[i])
+	}
+
+	log.Printf("Trash purge: removed %d users, %d roles, %d categories deleted before %s",
+		len(users), len(roles), len(categories), before.Format(time.RFC3339))
+}
+
+func (j *TrashPurgeJob) audit(entity string, id uint, before interface{}) {
+	err := j.auditUc.Record(models.AuditEntry{
+		ActorRole: constant.AuditActorSystem,
+		Action:    constant.AuditActionPurge,
+		Entity:    entity,
+		EntityID:  fmt.Sprint(id),
+		Before:    before,
+	})
+	if err != nil {
+		log.Printf("Trash purge: failed to record audit log: %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package apperror
+
+import "net/http"
+
+// ContentTypeProblem is the media type for RFC 7807 problem details.
+const ContentTypeProblem = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body. Code and Errors are
+// extension members carrying the error kind and per-field details.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Kind         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem builds the client-facing problem for err at the given path.
+func NewProblem(err *Error, instance, requestID string) Problem {
+	status := err.Status()
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    err.Message,
+		Instance:  instance,
+		Code:      err.Kind,
+		RequestID: requestID,
+		Errors:    err.Fields,
+	}
+}
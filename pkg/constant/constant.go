@@ -16,6 +16,7 @@ var AllRoles = []string{RoleAdmin, RoleStudent, RoleTeacher}
 const (
 	ContentTypeJSON  = "application/json"
 	AuthorizationKey = "Authorization"
+	RequestIDKey     = "X-Request-ID"
 )
 
 // Application Configuration
@@ -24,8 +25,167 @@ const (
 	MaxPageSize     = 100
 )
 
+// MaxRosterCSVSize caps the size of an uploaded classroom roster.
+const MaxRosterCSVSize = 1 << 20
+
+// MaxTagLength caps the length of a normalized tag name.
+const MaxTagLength = 64
+
+// Question Types
+const (
+	QuestionMultipleChoice = "multiple_choice"
+	QuestionEssay          = "essay"
+)
+
+// Difficulty Levels of quizzes and questions
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+// Difficulty suggestions: a question answered correctly at least
+// DifficultyEasyRate of the time looks easy, one answered correctly less
+// than DifficultyHardRate of the time looks hard.
+const (
+	DifficultyEasyRate = 0.8
+	DifficultyHardRate = 0.4
+)
+
+// Quiz Statuses: quizzes move from draft through an optional review to
+// published, and are archived when retired
+const (
+	QuizDraft     = "draft"
+	QuizInReview  = "in_review"
+	QuizPublished = "published"
+	QuizArchived  = "archived"
+)
+
+// Quiz Roles: the owner of a quiz manages it and who it is shared with,
+// editors change it, viewers only see it. Reviewer is not granted: it is
+// anyone who can view the quiz but is neither its owner nor an editor.
+const (
+	QuizRoleOwner    = "owner"
+	QuizRoleEditor   = "editor"
+	QuizRoleViewer   = "viewer"
+	QuizRoleReviewer = "reviewer"
+)
+
+// Quiz Version Reasons: a version is taken when the quiz is published and
+// when its answer key is corrected
+const (
+	VersionPublished = "published"
+	VersionAnswerKey = "answer_key"
+)
+
+// Quiz Diff Changes
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// Search Result Types
+const (
+	SearchQuiz     = "quiz"
+	SearchQuestion = "question"
+)
+
+// Assignment Scoring Policies
+const (
+	ScoringBest    = "best"
+	ScoringLast    = "last"
+	ScoringAverage = "average"
+)
+
+// Result Release Policies: when students see a part of their attempt results
+const (
+	ReleaseImmediately = "immediately"
+	ReleaseAfterClose  = "after_close"
+	ReleaseNever       = "never"
+)
+
+// Assignment Statuses shown on a student's due list
+const (
+	AssignmentUpcoming = "upcoming"
+	AssignmentOpen     = "open"
+	AssignmentLate     = "late"
+)
+
+// Spaced Repetition (SM-2): recall quality runs from 0 (blackout) to 5
+// (perfect); below ReviewPassQuality the card starts over.
+const (
+	ReviewInitialEase = 2.5
+	ReviewMinEase     = 1.3
+	ReviewPassQuality = 3
+)
+
+// Adaptive Testing: the default stopping standard error, and how many
+// answers a question needs before calibration replaces its default IRT
+// parameters.
+const (
+	AdaptiveDefaultMaxSE    = 0.3
+	CalibrationMinResponses = 20
+)
+
 // Validation Constants
 const (
 	MinPasswordLength = 8
 	MaxPasswordLength = 32
 )
+
+// AuditActorSystem is the actor role recorded for scheduled jobs.
+const AuditActorSystem = "system"
+
+// Audit Log Entities
+const (
+	AuditEntityAuth       = "auth"
+	AuditEntityUser       = "user"
+	AuditEntityRole       = "role"
+	AuditEntityCategory   = "category"
+	AuditEntityProfile    = "profile"
+	AuditEntityQuiz       = "quiz"
+	AuditEntityClassroom  = "classroom"
+	AuditEntityAssignment = "assignment"
+	AuditEntityAnswer     = "answer"
+	AuditEntityTag        = "tag"
+	AuditEntityAttachment = "attachment"
+	AuditEntityTemplate   = "quiz_template"
+)
+
+// Audit Log Actions
+const (
+	AuditActionCreate         = "create"
+	AuditActionUpdate         = "update"
+	AuditActionDelete         = "delete"
+	AuditActionLogin          = "login"
+	AuditActionLoginFailed    = "login_failed"
+	AuditActionRegister       = "register"
+	AuditActionChangePassword = "change_password"
+	AuditActionRestore        = "restore"
+	AuditActionPurge          = "purge"
+	AuditActionRequestEmail   = "request_email_change"
+	AuditActionVerifyEmail    = "verify_email_change"
+	AuditActionDeleteAccount  = "delete_account"
+	AuditActionRegenerateCode = "regenerate_join_code"
+	AuditActionEnroll         = "enroll"
+	AuditActionUnenroll       = "unenroll"
+	AuditActionAssign         = "assign"
+	AuditActionUnassign       = "unassign"
+	AuditActionJoin           = "join"
+	AuditActionLeave          = "leave"
+	AuditActionGrade          = "grade"
+	AuditActionCalibrate      = "calibrate"
+	AuditActionMove           = "move"
+	AuditActionMerge          = "merge"
+	AuditActionSubmit         = "submit"
+	AuditActionApprove        = "approve"
+	AuditActionReject         = "reject"
+	AuditActionPublish        = "publish"
+	AuditActionArchive        = "archive"
+	AuditActionAnswerKey      = "correct_answer_key"
+	AuditActionRegrade        = "regrade"
+	AuditActionShare          = "share"
+	AuditActionUnshare        = "unshare"
+	AuditActionTransfer       = "transfer"
+)
@@ -1,10 +1,16 @@
 package db
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/Arasy41/go-gin-quiz-api/config"
 	"github.com/Arasy41/go-gin-quiz-api/internal/domain/models"
+	"github.com/Arasy41/go-gin-quiz-api/internal/domain/repositories"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/constant"
+	"github.com/Arasy41/go-gin-quiz-api/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // InitDB initializes the database connection using the configuration provided.
@@ -15,8 +21,19 @@ func InitDB(cfg *config.Config) {
 		log.Fatal("Could not initialize the database connection:", err)
 	}
 
-	// Add AutoMigrate or other database initialization steps here if needed
-	DB.AutoMigrate(
+	// Quizzes from before the publishing workflow need a status of their own.
+	migrator := DB.Migrator()
+	quizStatusMissing := migrator.HasTable(&models.Quiz{}) && !migrator.HasColumn(&models.Quiz{}, "status")
+	// Quizzes published before versioning get their first version.
+	quizVersionsMissing := !migrator.HasTable(&models.QuizVersion{})
+	// Quizzes created before ownership get their creator as owner.
+	quizOwnersMissing := migrator.HasTable(&models.Quiz{}) && !migrator.HasColumn(&models.Quiz{}, "owner_id")
+
+	// Every table must migrate on every provider, so a failure is fatal.
+	// UUID keys are set in BeforeCreate hooks rather than by a column
+	// default such as Postgres' uuid_generate_v4(), which sqlite and mysql
+	// reject.
+	err = DB.AutoMigrate(
 		&models.User{},
 		&models.Role{},
 		&models.Quiz{},
@@ -25,7 +42,176 @@ func InitDB(cfg *config.Config) {
 		&models.Option{},
 		&models.Participant{},
 		&models.Answer{},
+		&models.AuditLog{},
+		&models.Profile{},
+		&models.EmailChange{},
+		&models.Classroom{},
+		&models.ClassroomMember{},
+		&models.ClassroomQuiz{},
+		&models.Assignment{},
+		&models.AssignmentUser{},
+		&models.RubricCriterion{},
+		&models.RubricScore{},
+		&models.PracticeQuestion{},
+		&models.ReviewCard{},
+		&models.Tag{},
+		&models.SearchDocument{},
+		&models.QuizVersion{},
+		&models.Attachment{},
+		&models.QuizTemplate{},
+		&models.QuizShare{},
 	)
+	if err != nil {
+		log.Fatal("Could not migrate the database:", err)
+	}
+	if err := migrateCategorySlugs(DB); err != nil {
+		log.Fatal("Could not migrate category slugs:", err)
+	}
+	if quizStatusMissing {
+		if err := migrateQuizStatus(DB); err != nil {
+			log.Fatal("Could not migrate quiz statuses:", err)
+		}
+	}
+	if quizVersionsMissing {
+		if err := migrateQuizVersions(DB); err != nil {
+			log.Fatal("Could not migrate quiz versions:", err)
+		}
+	}
+	if quizOwnersMissing {
+		if err := migrateQuizOwners(DB); err != nil {
+			log.Fatal("Could not migrate quiz owners:", err)
+		}
+	}
+	if err := repositories.NewSearchRepository(DB).Migrate(); err != nil {
+		log.Fatal("Could not migrate the search index:", err)
+	}
 
 	log.Println("Database initialized successfully")
 }
+
+const categorySlugIndex = "idx_categories_slug"
+
+// migrateCategorySlugs gives categories created before slugs existed one
+// derived from their name, then adds the unique index, which AutoMigrate
+// can't create while those categories share an empty slug.
+func migrateCategorySlugs(db *gorm.DB) error {
+	categories := []models.Category{}
+	if err := db.Unscoped().Order("id").Find(&categories).Error; err != nil {
+		return err
+	}
+
+	taken := map[string]bool{}
+	for _, c := range categories {
+		taken[c.Slug] = true
+	}
+	for _, c := range categories {
+		if c.Slug != "" {
+			continue
+		}
+		base := utils.Slugify(c.Name)
+		if base == "" {
+			base = "category"
+		}
+		slug := base
+		for n := 2; taken[slug]; n++ {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+		taken[slug] = true
+		if err := db.Unscoped().Model(&c).Update("slug", slug).Error; err != nil {
+			return err
+		}
+	}
+
+	if db.Migrator().HasIndex(&models.Category{}, categorySlugIndex) {
+		return nil
+	}
+	return db.Exec("CREATE UNIQUE INDEX " + categorySlugIndex + " ON categories (slug)").Error
+}
+
+// migrateQuizStatus publishes the quizzes students could already reach
+// before the publishing workflow, those used by a classroom or an
+// assignment, and leaves the rest as drafts.
+func migrateQuizStatus(db *gorm.DB) error {
+	reachable := db.Where("id IN (?)", db.Model(&models.ClassroomQuiz{}).Select("quiz_id")).
+		Or("id IN (?)", db.Model(&models.Assignment{}).Select("quiz_id"))
+	return db.Model(&models.Quiz{}).Where(reachable).
+		Updates(map[string]interface{}{"status": constant.QuizPublished, "published_at": gorm.Expr("updated_at")}).Error
+}
+
+// migrateQuizVersions snapshots every quiz published or archived before
+// versioning as its version 1, and pins the attempts already made at it to
+// that version.
+func migrateQuizVersions(db *gorm.DB) error {
+	var ids []uuid.UUID
+	err := db.Model(&models.Quiz{}).
+		Where("status IN ? AND revision_of IS NULL AND version = 0", []string{constant.QuizPublished, constant.QuizArchived}).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	quizRepo := repositories.NewQuizRepository(db)
+	for _, id := range ids {
+		quiz, err := quizRepo.FindQuizByID(id)
+		if err != nil {
+			return err
+		}
+		quiz.Version = 1
+		version, err := quiz.Snapshot(1, constant.VersionPublished)
+		if err != nil {
+			return err
+		}
+		version.CreatedAt = quiz.UpdatedAt
+		if quiz.PublishedAt != nil {
+			version.CreatedAt = *quiz.PublishedAt
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(version).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Quiz{}).Where("id = ?", id).UpdateColumn("version", 1).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.Participant{}).Where("quiz_id = ? AND quiz_version = 0", id).UpdateColumn("quiz_version", 1).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateQuizOwners makes the user who created each quiz, as recorded in
+// the audit log, its owner and the owner of its draft revision. Quizzes
+// whose creator is unknown, deleted or gone go to the first admin.
+func migrateQuizOwners(db *gorm.DB) error {
+	logs := []models.AuditLog{}
+	err := db.Select("entity_id", "actor_id").
+		Where("action = ? AND entity = ?", constant.AuditActionCreate, constant.AuditEntityQuiz).
+		Where("actor_id IN (?)", db.Model(&models.User{}).Select("id")).
+		Order("id").Find(&logs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range logs {
+		quizID, err := uuid.Parse(entry.EntityID)
+		if err != nil {
+			continue
+		}
+		err = db.Model(&models.Quiz{}).
+			Where("(id = ? OR revision_of = ?) AND owner_id IS NULL", quizID, quizID).
+			Update("owner_id", entry.ActorID).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var admins []uint
+	err = db.Model(&models.User{}).Where("role_id = ?", constant.RoleAdminID).Order("id").Limit(1).Pluck("id", &admins).Error
+	if err != nil || len(admins) == 0 {
+		return err
+	}
+	return db.Model(&models.Quiz{}).Where("owner_id IS NULL").Update("owner_id", admins[0]).Error
+}
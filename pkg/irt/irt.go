@@ -0,0 +1,139 @@
+// Package irt implements the two-parameter logistic (2PL) item response
+// model used for adaptive quizzes: item calibration from past responses,
+// ability estimation and item information.
+package irt
+
+import "math"
+
+const (
+	// DefaultDiscrimination and DefaultDifficulty describe an item that has
+	// not been calibrated yet.
+	DefaultDiscrimination = 1.0
+	DefaultDifficulty     = 0.0
+
+	minDiscrimination = 0.2
+	maxDiscrimination = 3.0
+	maxDifficulty     = 4.0
+
+	// Ability is estimated on a grid over [-gridLimit, gridLimit].
+	gridLimit  = 4.0
+	gridPoints = 81
+)
+
+// Item holds the 2PL parameters of a question.
+type Item struct {
+	Discrimination float64
+	Difficulty     float64
+}
+
+// Response is an answer to an item.
+type Response struct {
+	Item    Item
+	Correct bool
+}
+
+// Probability is the chance that a person of ability theta answers the item
+// correctly.
+func Probability(item Item, theta float64) float64 {
+	return 1 / (1 + math.Exp(-item.Discrimination*(theta-item.Difficulty)))
+}
+
+// Information is the Fisher information the item gives at ability theta.
+func Information(item Item, theta float64) float64 {
+	p := Probability(item, theta)
+	return item.Discrimination * item.Discrimination * p * (1 - p)
+}
+
+// EstimateAbility returns the expected a posteriori (EAP) ability under a
+// standard normal prior, and its posterior standard deviation as the
+// standard error. Unlike maximum likelihood it stays finite when every
+// answer is right or every answer is wrong.
+func EstimateAbility(responses []Response) (theta, se float64) {
+	var total, mean, square float64
+	for i := 0; i < gridPoints; i++ {
+		t := -gridLimit + 2*gridLimit*float64(i)/float64(gridPoints-1)
+		weight := math.Exp(-t * t / 2)
+		for _, r := range responses {
+			p := Probability(r.Item, t)
+			if r.Correct {
+				weight *= p
+			} else {
+				weight *= 1 - p
+			}
+		}
+		total += weight
+		mean += weight * t
+		square += weight * t * t
+	}
+	if total == 0 {
+		return 0, 1
+	}
+	theta = mean / total
+	return theta, math.Sqrt(math.Max(square/total-theta*theta, 0))
+}
+
+// Calibrate estimates an item's parameters from how a group of people did
+// on it: the share who answered it correctly gives the difficulty, and the
+// point-biserial correlation between answering it correctly and their rest
+// score (their score on the other items) gives the discrimination.
+// correct[i] and rest[i] belong to the same person.
+func Calibrate(correct []bool, rest []float64) Item {
+	n := float64(len(correct))
+	if n == 0 {
+		return Item{Discrimination: DefaultDiscrimination, Difficulty: DefaultDifficulty}
+	}
+
+	right := 0.0
+	for _, c := range correct {
+		if c {
+			right++
+		}
+	}
+	// Smoothed so an item everybody (or nobody) got right stays finite.
+	p := (right + 0.5) / (n + 1)
+	difficulty := clamp(-math.Log(p/(1-p)), -maxDifficulty, maxDifficulty)
+
+	// Normal ogive conversion, scaled to the logistic metric. Items that
+	// don't separate strong from weak people get the minimum.
+	r := math.Max(pointBiserial(correct, rest), 0)
+	discrimination := clamp(1.702*r/math.Sqrt(1-math.Min(r*r, 0.99)), minDiscrimination, maxDiscrimination)
+	return Item{Discrimination: discrimination, Difficulty: difficulty}
+}
+
+// ScorePercent maps an ability to the chance, in percent, of answering an
+// item of average difficulty correctly.
+func ScorePercent(theta float64) int {
+	return int(math.Round(100 * Probability(Item{Discrimination: 1.702}, theta)))
+}
+
+func pointBiserial(correct []bool, rest []float64) float64 {
+	n := float64(len(correct))
+	var meanX, meanY float64
+	for i, c := range correct {
+		if c {
+			meanX++
+		}
+		meanY += rest[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var cov, varX, varY float64
+	for i, c := range correct {
+		x := 0.0
+		if c {
+			x = 1
+		}
+		cov += (x - meanX) * (rest[i] - meanY)
+		varX += (x - meanX) * (x - meanX)
+		varY += (rest[i] - meanY) * (rest[i] - meanY)
+	}
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varX*varY)
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
@@ -0,0 +1,117 @@
+package irt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateAbility(t *testing.T) {
+	average := Item{Discrimination: 1, Difficulty: 0}
+	hard := Item{Discrimination: 1.5, Difficulty: 2}
+	easy := Item{Discrimination: 1.5, Difficulty: -2}
+
+	tests := []struct {
+		name      string
+		responses []Response
+		min, max  float64
+	}{
+		{"no responses keeps the prior", nil, -0.001, 0.001},
+		{"one right", []Response{{average, true}}, 0.2, 0.6},
+		{"one wrong", []Response{{average, false}}, -0.6, -0.2},
+		{"all right stays finite", []Response{{average, true}, {hard, true}, {hard, true}}, 0.8, gridLimit},
+		{"all wrong stays finite", []Response{{average, false}, {easy, false}, {easy, false}}, -gridLimit, -0.8},
+		{"easy right, hard wrong", []Response{{easy, true}, {hard, false}}, -0.3, 0.3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theta, se := EstimateAbility(tt.responses)
+			if theta < tt.min || theta > tt.max {
+				t.Errorf("theta = %v, want within [%v, %v]", theta, tt.min, tt.max)
+			}
+			if se <= 0 || se > 1 {
+				t.Errorf("se = %v, want within (0, 1]", se)
+			}
+		})
+	}
+}
+
+func TestEstimateAbilityNarrowsWithResponses(t *testing.T) {
+	item := Item{Discrimination: 1.2, Difficulty: 0}
+	responses := []Response{}
+	_, prev := EstimateAbility(responses)
+	for i := 0; i < 6; i++ {
+		responses = append(responses, Response{Item: item, Correct: i%2 == 0})
+		_, se := EstimateAbility(responses)
+		if se >= prev {
+			t.Fatalf("se after %d responses = %v, want below %v", len(responses), se, prev)
+		}
+		prev = se
+	}
+}
+
+func TestEstimateAbilitySymmetry(t *testing.T) {
+	item := Item{Discrimination: 1, Difficulty: 0}
+	right, _ := EstimateAbility([]Response{{item, true}, {item, true}})
+	wrong, _ := EstimateAbility([]Response{{item, false}, {item, false}})
+	if math.Abs(right+wrong) > 1e-9 {
+		t.Errorf("all right = %v and all wrong = %v, want opposites", right, wrong)
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	tests := []struct {
+		name           string
+		correct        []bool
+		rest           []float64
+		discrimination float64
+		difficulty     float64
+	}{
+		{"no responses", nil, nil, DefaultDiscrimination, DefaultDifficulty},
+		{"half right", []bool{true, false, true, false}, []float64{2, 2, 2, 2}, minDiscrimination, 0},
+		{"everybody right stays finite", []bool{true, true, true}, []float64{1, 2, 3}, minDiscrimination, -math.Log(7)},
+		{"nobody right stays finite", []bool{false, false, false}, []float64{1, 2, 3}, minDiscrimination, math.Log(7)},
+		{"separates strong from weak", []bool{true, true, false, false}, []float64{3, 3, 1, 1}, maxDiscrimination, 0},
+		{"favours the weak", []bool{false, false, true, true}, []float64{3, 3, 1, 1}, minDiscrimination, 0},
+		{"partly separating", []bool{true, false, true, false}, []float64{3, 2, 2, 1}, 1.702, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := Calibrate(tt.correct, tt.rest)
+			if math.Abs(item.Discrimination-tt.discrimination) > 1e-3 {
+				t.Errorf("Discrimination = %v, want %v", item.Discrimination, tt.discrimination)
+			}
+			if math.Abs(item.Difficulty-tt.difficulty) > 1e-9 {
+				t.Errorf("Difficulty = %v, want %v", item.Difficulty, tt.difficulty)
+			}
+		})
+	}
+}
+
+func TestCalibrateDifficultyOrder(t *testing.T) {
+	rest := []float64{1, 2, 3, 4, 5}
+	easy := Calibrate([]bool{true, true, true, true, false}, rest)
+	hard := Calibrate([]bool{true, false, false, false, false}, rest)
+	if easy.Difficulty >= hard.Difficulty {
+		t.Errorf("easy difficulty %v, want below hard difficulty %v", easy.Difficulty, hard.Difficulty)
+	}
+}
+
+func TestScorePercent(t *testing.T) {
+	tests := []struct {
+		theta float64
+		want  int
+	}{
+		{0, 50},
+		{gridLimit, 100},
+		{-gridLimit, 0},
+		{1, 85},
+		{-1, 15},
+	}
+	for _, tt := range tests {
+		if got := ScorePercent(tt.theta); got != tt.want {
+			t.Errorf("ScorePercent(%v) = %d, want %d", tt.theta, got, tt.want)
+		}
+	}
+}
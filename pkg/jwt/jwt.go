@@ -10,26 +10,39 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var secretKey = []byte(config.InitConfig().SecretKey)
-
 type Claims struct {
 	UserID   uint   `json:"user_id"`
 	UserRole string `json:"user_role"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint, userRole string) (string, error) {
+// JWT signs and parses access tokens with the secret and lifespan taken from
+// the application config.
+type JWT struct {
+	secretKey     []byte
+	tokenLifespan time.Duration
+}
+
+// NewJWT creates a token issuer from an already validated config.
+func NewJWT(cfg *config.Config) *JWT {
+	return &JWT{
+		secretKey:     []byte(cfg.SecretKey),
+		tokenLifespan: time.Hour * time.Duration(cfg.TokenLifespan),
+	}
+}
+
+func (j *JWT) GenerateToken(userID uint, userRole string) (string, error) {
 	log.Println("Generating token with UserRole:", userRole)
 	claims := &Claims{
 		UserID:   userID,
 		UserRole: userRole,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(config.InitConfig().TokenLifespan))),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.tokenLifespan)),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString(secretKey)
+	signedToken, err := token.SignedString(j.secretKey)
 	if err != nil {
 		return "", err
 	}
@@ -37,10 +50,10 @@ func GenerateToken(userID uint, userRole string) (string, error) {
 	return signedToken, nil
 }
 
-func ParseToken(tokenString string) (*Claims, error) {
+func (j *JWT) ParseToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return secretKey, nil
+		return j.secretKey, nil
 	})
 
 	if err != nil {
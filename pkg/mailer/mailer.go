@@ -0,0 +1,22 @@
+package mailer
+
+import "log"
+
+// Mailer delivers transactional email such as verification links.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+type logMailer struct{}
+
+// NewLogMailer returns a Mailer that writes messages to the application log
+// instead of sending them. It is the default until an SMTP transport is
+// configured, and is handy in development to pick up verification links.
+func NewLogMailer() Mailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(to, subject, body string) error {
+	log.Printf("MAIL to=%s subject=%q\n%s", to, subject, body)
+	return nil
+}
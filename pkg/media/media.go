@@ -0,0 +1,133 @@
+// Package media recognizes uploaded images and audio by their content and
+// makes thumbnails of images.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"sort"
+
+	// Decoders for the accepted image types.
+	_ "image/gif"
+	_ "image/jpeg"
+
+	"github.com/gabriel-vasile/mimetype"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Kinds of media.
+const (
+	KindImage = "image"
+	KindAudio = "audio"
+)
+
+// ThumbnailSize is the width and height thumbnails are scaled down to fit.
+const ThumbnailSize = 256
+
+// MaxImagePixels caps the size of images that are decoded for a thumbnail,
+// so a small file can't expand into a huge bitmap.
+const MaxImagePixels = 40_000_000
+
+// ErrTooLarge is returned for images with more than MaxImagePixels pixels.
+var ErrTooLarge = errors.New("media: image dimensions are too large")
+
+// types maps each accepted MIME type to its kind and file extension.
+var types = map[string]struct{ kind, ext string }{
+	"image/png":   {KindImage, ".png"},
+	"image/jpeg":  {KindImage, ".jpg"},
+	"image/gif":   {KindImage, ".gif"},
+	"image/webp":  {KindImage, ".webp"},
+	"audio/mpeg":  {KindAudio, ".mp3"},
+	"audio/wav":   {KindAudio, ".wav"},
+	"audio/ogg":   {KindAudio, ".ogg"},
+	"audio/x-m4a": {KindAudio, ".m4a"},
+}
+
+// Type is what Detect found out about a file.
+type Type struct {
+	MIME string
+	Kind string
+	Ext  string
+}
+
+// Detect sniffs the type of the file from its first bytes, ignoring any
+// name or declared content type. ok is false for anything but the
+// accepted images and audio.
+func Detect(head []byte) (t Type, ok bool) {
+	for m := mimetype.Detect(head); m != nil; m = m.Parent() {
+		if known, found := types[m.String()]; found {
+			return Type{MIME: m.String(), Kind: known.kind, Ext: known.ext}, true
+		}
+		for mime, known := range types {
+			if m.Is(mime) {
+				return Type{MIME: mime, Kind: known.kind, Ext: known.ext}, true
+			}
+		}
+	}
+	return Type{}, false
+}
+
+// ContentType returns the MIME type of an accepted file extension, and
+// application/octet-stream for any other.
+func ContentType(ext string) string {
+	for mime, known := range types {
+		if known.ext == ext {
+			return mime
+		}
+	}
+	return "application/octet-stream"
+}
+
+// Accepted lists the accepted MIME types in order.
+func Accepted() []string {
+	list := []string{}
+	for mime := range types {
+		list = append(list, mime)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Thumbnail decodes the image in r and returns it scaled down to fit in a
+// size by size square as a PNG, together with the original dimensions.
+// Images that already fit are not enlarged.
+func Thumbnail(r io.ReadSeeker, size int) (thumb []byte, width, height int, err error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if cfg.Width*cfg.Height > MaxImagePixels {
+		return nil, 0, 0, ErrTooLarge
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, 0, 0, err
+	}
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(1, h*size/w)
+		} else {
+			w, h = max(1, w*size/h), size
+		}
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.Transparent), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, 0, 0, err
+	}
+	return buf.Bytes(), cfg.Width, cfg.Height, nil
+}
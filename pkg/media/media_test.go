@@ -0,0 +1,131 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encoded(t *testing.T, encode func(*bytes.Buffer, image.Image) error, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func pngOf(t *testing.T, w, h int) []byte {
+	return encoded(t, func(b *bytes.Buffer, m image.Image) error { return png.Encode(b, m) }, w, h)
+}
+
+// riff wraps a chunk of the given form type in a RIFF header.
+func riff(form, chunk string) []byte {
+	data := form + chunk + "\x10\x00\x00\x00" + string(make([]byte, 16))
+	size := len(data)
+	return []byte("RIFF" + string([]byte{byte(size), byte(size >> 8), 0, 0}) + data)
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		head []byte
+		want Type
+		ok   bool
+	}{
+		{"png", pngOf(t, 2, 2), Type{"image/png", KindImage, ".png"}, true},
+		{"jpeg", encoded(t, func(b *bytes.Buffer, m image.Image) error { return jpeg.Encode(b, m, nil) }, 2, 2), Type{"image/jpeg", KindImage, ".jpg"}, true},
+		{"gif", encoded(t, func(b *bytes.Buffer, m image.Image) error { return gif.Encode(b, m, nil) }, 2, 2), Type{"image/gif", KindImage, ".gif"}, true},
+		{"webp", riff("WEBP", "VP8 "), Type{"image/webp", KindImage, ".webp"}, true},
+		{"mp3", append([]byte("ID3\x03\x00\x00\x00\x00\x00\x00"), make([]byte, 32)...), Type{"audio/mpeg", KindAudio, ".mp3"}, true},
+		{"wav", riff("WAVE", "fmt "), Type{"audio/wav", KindAudio, ".wav"}, true},
+		{"ogg vorbis", append([]byte("OggS\x00\x02"+string(make([]byte, 22))+"\x01vorbis"), make([]byte, 16)...), Type{"audio/ogg", KindAudio, ".ogg"}, true},
+		{"m4a", []byte("\x00\x00\x00\x18ftypM4A \x00\x00\x00\x00M4A isom"), Type{"audio/x-m4a", KindAudio, ".m4a"}, true},
+
+		{"empty", nil, Type{}, false},
+		{"text", []byte("just some text"), Type{}, false},
+		{"html", []byte("<!DOCTYPE html><html><body><script>alert(1)</script></body></html>"), Type{}, false},
+		{"html without doctype", []byte("<script>alert(1)</script>"), Type{}, false},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"></svg>`), Type{}, false},
+		{"svg with xml prolog", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`), Type{}, false},
+		{"pdf", []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"), Type{}, false},
+		{"zip", []byte("PK\x03\x04\x14\x00\x00\x00\x08\x00"), Type{}, false},
+		{"executable", []byte("\x7fELF\x02\x01\x01\x00"), Type{}, false},
+		{"avi is not wav", riff("AVI ", "LIST"), Type{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Detect(tt.head)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("Detect = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestContentType(t *testing.T) {
+	for _, mime := range Accepted() {
+		if got := ContentType(types[mime].ext); got != mime {
+			t.Errorf("ContentType(%q) = %q, want %q", types[mime].ext, got, mime)
+		}
+	}
+	for _, ext := range []string{"", ".html", ".svg", ".PNG"} {
+		if got := ContentType(ext); got != "application/octet-stream" {
+			t.Errorf("ContentType(%q) = %q, want application/octet-stream", ext, got)
+		}
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		thumbW        int
+		thumbH        int
+	}{
+		{"small is not enlarged", 10, 20, 10, 20},
+		{"wide", 1024, 512, ThumbnailSize, ThumbnailSize / 2},
+		{"tall", 300, 1200, ThumbnailSize / 4, ThumbnailSize},
+		{"very thin keeps a pixel", 4000, 2, ThumbnailSize, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb, w, h, err := Thumbnail(bytes.NewReader(pngOf(t, tt.width, tt.height)), ThumbnailSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w != tt.width || h != tt.height {
+				t.Errorf("dimensions = %dx%d, want %dx%d", w, h, tt.width, tt.height)
+			}
+			cfg, err := png.DecodeConfig(bytes.NewReader(thumb))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Width != tt.thumbW || cfg.Height != tt.thumbH {
+				t.Errorf("thumbnail = %dx%d, want %dx%d", cfg.Width, cfg.Height, tt.thumbW, tt.thumbH)
+			}
+		})
+	}
+}
+
+func TestThumbnailRejects(t *testing.T) {
+	if _, _, _, err := Thumbnail(bytes.NewReader([]byte("<svg></svg>")), ThumbnailSize); err == nil {
+		t.Error("Thumbnail decoded an SVG")
+	}
+
+	// A PNG header claiming more pixels than allowed is refused before
+	// anything is decoded.
+	huge := pngOf(t, 1, 1)
+	copy(huge[16:24], []byte{0, 0, 0x27, 0x10, 0, 0, 0x27, 0x10})
+	binary.BigEndian.PutUint32(huge[29:33], crc32.ChecksumIEEE(huge[12:29]))
+	if _, _, _, err := Thumbnail(bytes.NewReader(huge), ThumbnailSize); err != ErrTooLarge {
+		t.Errorf("Thumbnail of a 10000x10000 image error = %v, want ErrTooLarge", err)
+	}
+}
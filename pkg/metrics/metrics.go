@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+const namespace = "quiz_api"
+
+// Registry holds every collector exposed on /metrics.
+var Registry = prometheus.NewRegistry()
+
+// HTTP metrics, labelled by the gin route template rather than the raw path
+// so that IDs in the URL do not explode the label cardinality.
+var (
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests handled, by route template, method and status.",
+	}, []string{"route", "method", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by route template, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+)
+
+// Domain metrics.
+var (
+	LoginsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts, by result (succeeded, failed).",
+	}, []string{"result"})
+
+	AttemptsStarted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "quiz_attempts_started_total",
+		Help:      "Quiz attempts started by participants.",
+	})
+
+	AttemptsFinished = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "quiz_attempts_finished_total",
+		Help:      "Quiz attempts submitted by participants.",
+	})
+)
+
+// Login results used as the LoginsTotal label.
+const (
+	LoginSucceeded = "succeeded"
+	LoginFailed    = "failed"
+)
+
+// InitMetrics registers the process, Go runtime, HTTP and domain collectors,
+// plus the connection pool statistics of db and the live session gauge,
+// which reads its value from liveSessions on every scrape.
+func InitMetrics(db *gorm.DB, liveSessions func() (int64, error)) {
+	Registry.MustRegister(
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewGoCollector(),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		LoginsTotal,
+		AttemptsStarted,
+		AttemptsFinished,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "live_sessions",
+			Help:      "Quiz attempts currently open (started but not finished).",
+		}, func() float64 {
+			return countLiveSessions(liveSessions)
+		}),
+	)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Printf("Failed to register DB pool metrics: %v", err)
+		return
+	}
+	Registry.MustRegister(collectors.NewDBStatsCollector(sqlDB, db.Name()))
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+func countLiveSessions(liveSessions func() (int64, error)) float64 {
+	count, err := liveSessions()
+	if err != nil {
+		log.Printf("Failed to count live sessions: %v", err)
+		return 0
+	}
+	return float64(count)
+}
@@ -0,0 +1,107 @@
+package richtext
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var kindMath = ast.NewNodeKind("Math")
+
+// mathNode is TeX source between $ or $$ delimiters.
+type mathNode struct {
+	ast.BaseInline
+	tex     []byte
+	display bool
+}
+
+func (n *mathNode) Kind() ast.NodeKind {
+	return kindMath
+}
+
+func (n *mathNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"TeX": string(n.tex)}, nil)
+}
+
+// mathParser reads $...$ and $$...$$, which may span lines. Like Pandoc,
+// it only takes a single $ as an opener when a non-space follows, and as a
+// closer when a non-space precedes and no digit follows, so prices such
+// as "$5 and $10" stay text. A backslash escapes the next character.
+type mathParser struct{}
+
+func (p *mathParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p *mathParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	delim := 1
+	if len(line) > 1 && line[1] == '$' {
+		delim = 2
+	}
+	if len(line) > delim && line[delim] == '$' {
+		return nil
+	}
+	if delim == 1 && (len(line) < 2 || util.IsSpace(line[1])) {
+		return nil
+	}
+
+	startLine, startPos := block.Position()
+	block.Advance(delim)
+	tex := []byte{}
+	for {
+		line, _ := block.PeekLine()
+		if line == nil {
+			block.SetPosition(startLine, startPos)
+			return nil
+		}
+		for i := 0; i < len(line); i++ {
+			switch {
+			case line[i] == '\\':
+				i++
+			case line[i] != '$':
+			case delim == 2 && i+1 < len(line) && line[i+1] == '$':
+				block.Advance(i + 2)
+				return &mathNode{tex: append(tex, line[:i]...), display: true}
+			case delim == 1 && i > 0 && !util.IsSpace(line[i-1]) && (i+1 >= len(line) || !isDigit(line[i+1])):
+				block.Advance(i + 1)
+				return &mathNode{tex: append(tex, line[:i]...)}
+			}
+		}
+		tex = append(tex, line...)
+		block.AdvanceLine()
+	}
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// mathRenderer writes math as escaped TeX in the delimiters KaTeX and
+// MathJax look for.
+type mathRenderer struct{}
+
+func (r *mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMath, r.render)
+}
+
+func (r *mathRenderer) render(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkSkipChildren, nil
+	}
+	n := node.(*mathNode)
+	if n.display {
+		w.WriteString(`<span class="math display">\[`)
+	} else {
+		w.WriteString(`<span class="math inline">\(`)
+	}
+	w.Write(util.EscapeHTML(n.tex))
+	if n.display {
+		w.WriteString(`\]</span>`)
+	} else {
+		w.WriteString(`\)</span>`)
+	}
+	return ast.WalkSkipChildren, nil
+}
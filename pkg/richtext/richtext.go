@@ -0,0 +1,94 @@
+// Package richtext renders the Markdown subset used in question text,
+// options and explanations to sanitized HTML.
+//
+// The subset covers paragraphs, line breaks, emphasis, strong, inline and
+// fenced code, lists, block quotes and links. Headings, rules, images and
+// raw HTML are not part of it: raw HTML is shown as text and an image as
+// its alt text; attachments carry a question's images. TeX between $
+// and $ (inline) or $$ and $$ (display) is passed through escaped, inside
+// <span class="math inline">\(...\)</span> or
+// <span class="math display">\[...\]</span>, for KaTeX or MathJax to
+// typeset in the browser.
+package richtext
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+var markdown = goldmark.New(
+	goldmark.WithParser(parser.NewParser(
+		parser.WithBlockParsers(
+			util.Prioritized(parser.NewListParser(), 300),
+			util.Prioritized(parser.NewListItemParser(), 400),
+			util.Prioritized(parser.NewCodeBlockParser(), 500),
+			util.Prioritized(parser.NewFencedCodeBlockParser(), 700),
+			util.Prioritized(parser.NewBlockquoteParser(), 800),
+			util.Prioritized(parser.NewParagraphParser(), 1000),
+		),
+		parser.WithInlineParsers(
+			util.Prioritized(parser.NewCodeSpanParser(), 100),
+			util.Prioritized(parser.NewLinkParser(), 200),
+			util.Prioritized(parser.NewAutoLinkParser(), 300),
+			util.Prioritized(&mathParser{}, 400),
+			util.Prioritized(parser.NewEmphasisParser(), 500),
+		),
+		parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+	)),
+	goldmark.WithRendererOptions(
+		html.WithHardWraps(),
+		renderer.WithNodeRenderers(
+			util.Prioritized(&mathRenderer{}, 100),
+			util.Prioritized(&imageRenderer{}, 100),
+		),
+	),
+)
+
+// imageRenderer writes an image as its alt text, which the renderer's
+// children walk writes out, instead of an <img> the policy would drop
+// along with the text.
+type imageRenderer struct{}
+
+func (r *imageRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindImage, func(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+		return ast.WalkContinue, nil
+	})
+}
+
+// policy allows only what the Markdown subset produces, as a second line
+// of defence behind the renderer never emitting raw HTML.
+var policy = func() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements("p", "br", "em", "strong", "code", "pre", "blockquote", "ul", "ol", "li", "span")
+	p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^math (inline|display)$`)).OnElements("span")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowURLSchemes("http", "https", "mailto")
+	p.RequireParseableURLs(true)
+	p.AllowRelativeURLs(false)
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}()
+
+// Render returns the source as sanitized HTML, or "" for empty source.
+func Render(source string) string {
+	if source == "" {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		// Only writing to buf could fail, and it doesn't.
+		return ""
+	}
+	return string(bytes.TrimSpace(policy.SanitizeBytes(buf.Bytes())))
+}
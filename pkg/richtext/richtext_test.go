@@ -0,0 +1,86 @@
+package richtext
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"empty", "", ""},
+		{"paragraph", "Plain text", "<p>Plain text</p>"},
+		{"hard wrap", "one\ntwo", "<p>one<br>\ntwo</p>"},
+		{"emphasis", "*a* and **b**", "<p><em>a</em> and <strong>b</strong></p>"},
+		{"nested emphasis", "*a **b** c*", "<p><em>a <strong>b</strong> c</em></p>"},
+		{"strong emphasis", "***both***", "<p><em><strong>both</strong></em></p>"},
+		{"code span", "`x < y`", "<p><code>x &lt; y</code></p>"},
+		{"fenced code", "```go\nfmt.Println(\"<b>\")\n```", "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;b&gt;&#34;)\n</code></pre>"},
+		{"list", "1. one\n2. two", "<ol>\n<li>one</li>\n<li>two</li>\n</ol>"},
+		{"block quote", "> quoted", "<blockquote>\n<p>quoted</p>\n</blockquote>"},
+		{"heading is text", "# Title", "<p># Title</p>"},
+
+		{"script", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"html block", "<div>\nhi\n</div>", "<p>&lt;div&gt;<br>\nhi<br>\n&lt;/div&gt;</p>"},
+		{"inline html", "a <b>bold</b> word", "<p>a &lt;b&gt;bold&lt;/b&gt; word</p>"},
+		{"event handler", `<img src=x onerror="alert(1)">`, "<p>&lt;img src=x onerror=&#34;alert(1)&#34;&gt;</p>"},
+		{"event handler in link title", `[x](https://example.com "a\" onerror=\"alert(1)")`, `<p><a href="https://example.com" rel="nofollow noopener" target="_blank">x</a></p>`},
+
+		{"https link", "[ok](https://example.com)", `<p><a href="https://example.com" rel="nofollow noopener" target="_blank">ok</a></p>`},
+		{"mailto link", "[mail](mailto:a@example.com)", `<p><a href="mailto:a@example.com" rel="nofollow">mail</a></p>`},
+		{"javascript link", "[x](javascript:alert(1))", "<p>x</p>"},
+		{"javascript link in caps", "[x](JaVaScRiPt:alert(1))", "<p>x</p>"},
+		{"data link", "[x](data:text/html;base64,PHNjcmlwdD4=)", "<p>x</p>"},
+		{"relative link", "[x](/admin)", "<p>x</p>"},
+		{"javascript autolink", "<javascript:alert(1)>", "<p>javascript:alert(1)</p>"},
+
+		{"image keeps its alt text", "![a *cat*](https://example.com/cat.png) here", "<p>a <em>cat</em> here</p>"},
+		{"javascript image", "![alt](javascript:alert(1))", "<p>alt</p>"},
+
+		{"inline math", "$x^2$", `<p><span class="math inline">\(x^2\)</span></p>`},
+		{"display math", "$$\\frac{a}{b}$$", `<p><span class="math display">\[\frac{a}{b}\]</span></p>`},
+		{"math is escaped", "$a<b$ and $$x<y & \"z\"$$", `<p><span class="math inline">\(a&lt;b\)</span> and <span class="math display">\[x&lt;y &amp; &#34;z&#34;\]</span></p>`},
+		{"math hides no html", "$</span><script>alert(1)</script>$", `<p><span class="math inline">\(&lt;/span&gt;&lt;script&gt;alert(1)&lt;/script&gt;\)</span></p>`},
+		{"prices are not math", "$5 and $10", "<p>$5 and $10</p>"},
+		{"escaped dollar", "\\$x$", "<p>$x$</p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.source); got != tt.want {
+				t.Errorf("Render(%q)\n got %q\nwant %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+// dangerous matches markup that would run script: an event handler
+// attribute or a script-capable URL inside a tag.
+var dangerous = regexp.MustCompile(`(?i)<[^>]*(\son\w+\s*=|javascript:|data:)|<script|<iframe|<object|<embed|<style|<img`)
+
+func TestRenderNeverEmitsScript(t *testing.T) {
+	sources := []string{
+		"<script>alert(1)</script>",
+		"<img src=x onerror=alert(1)>",
+		"<svg onload=alert(1)>",
+		"<iframe src=javascript:alert(1)></iframe>",
+		"<a href=\"javascript:alert(1)\">x</a>",
+		"[x](javascript:alert(1))",
+		"[x](data:text/html,<script>alert(1)</script>)",
+		"![x](data:image/svg+xml,<svg onload=alert(1)>)",
+		"[x](https://example.com \"\" onmouseover=\"alert(1)\")",
+		"<style>body{display:none}</style>",
+		"$<img src=x onerror=alert(1)>$",
+		"$$<script>alert(1)</script>$$",
+		"`<script>`",
+		"*<script>*alert(1)*</script>*",
+	}
+	for _, source := range sources {
+		if got := Render(source); dangerous.MatchString(got) {
+			t.Errorf("Render(%q) = %q, which can run script", source, got)
+		}
+	}
+}
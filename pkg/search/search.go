@@ -0,0 +1,115 @@
+// Package search turns a free-text query into search terms and highlights
+// them in result text.
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// MaxTerms caps how many terms of a query are searched for.
+const MaxTerms = 10
+
+// Terms splits a query into distinct lowercase words of letters and digits.
+// Everything else separates words, so the terms are safe to embed in any
+// provider's full-text query syntax.
+func Terms(query string) []string {
+	terms := []string{}
+	seen := map[string]bool{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(query), notWordRune) {
+		if !seen[word] && len(terms) < MaxTerms {
+			seen[word] = true
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// Highlight returns text as HTML with every word starting with one of the
+// terms wrapped in <mark>. Text longer than maxRunes is cut down to a
+// window around the first match, marked with ellipses. It returns "" when
+// maxRunes is positive and nothing matches, so callers can skip snippets
+// of fields the query didn't hit.
+func Highlight(text string, terms []string, maxRunes int) string {
+	runes := []rune(text)
+	words := wordSpans(runes)
+
+	first := -1
+	for _, w := range words {
+		if matches(runes[w[0]:w[1]], terms) {
+			first = w[0]
+			break
+		}
+	}
+	if first < 0 && maxRunes > 0 {
+		return ""
+	}
+
+	start, end := 0, len(runes)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		start = first - maxRunes/4
+		if start < 0 {
+			start = 0
+		}
+		end = start + maxRunes
+		if end > len(runes) {
+			end = len(runes)
+			start = end - maxRunes
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, w := range words {
+		if w[0] < start || w[1] > end || !matches(runes[w[0]:w[1]], terms) {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:w[0]])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[w[0]:w[1]])))
+		b.WriteString("</mark>")
+		pos = w[1]
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// wordSpans returns the [start, end) rune offsets of the words in runes.
+func wordSpans(runes []rune) [][2]int {
+	spans := [][2]int{}
+	start := -1
+	for i, r := range runes {
+		switch {
+		case !notWordRune(r) && start < 0:
+			start = i
+		case notWordRune(r) && start >= 0:
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(runes)})
+	}
+	return spans
+}
+
+func matches(word []rune, terms []string) bool {
+	lower := strings.ToLower(string(word))
+	for _, t := range terms {
+		if strings.HasPrefix(lower, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func notWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,71 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{}},
+		{"   ", []string{}},
+		{"Photosynthesis", []string{"photosynthesis"}},
+		{"cell Cell CELL division", []string{"cell", "division"}},
+		{`"quoted" OR title:x* -NOT (y)`, []string{"quoted", "or", "title", "x", "not", "y"}},
+		{"H2O, CO2; O'Brien", []string{"h2o", "co2", "o", "brien"}},
+		{"Ünïcode Straße 東京", []string{"ünïcode", "straße", "東京"}},
+		{"a b c d e f g h i j k l", []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}},
+	}
+
+	for _, tt := range tests {
+		if got := Terms(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		terms    []string
+		maxRunes int
+		want     string
+	}{
+		{"whole text", "The cell wall", []string{"cell"}, 0, "The <mark>cell</mark> wall"},
+		{"prefix match", "Cells divide", []string{"cell"}, 0, "<mark>Cells</mark> divide"},
+		{"not inside a word", "Excellent", []string{"cell"}, 0, "Excellent"},
+		{"several terms", "cell wall and cell membrane", []string{"cell", "membrane"}, 0, "<mark>cell</mark> wall and <mark>cell</mark> <mark>membrane</mark>"},
+		{"escapes html", "<b>cell</b> & co", []string{"cell"}, 0, "&lt;b&gt;<mark>cell</mark>&lt;/b&gt; &amp; co"},
+		{"no match without limit", "The wall", []string{"cell"}, 0, "The wall"},
+		{"no match with limit", "The wall", []string{"cell"}, 20, ""},
+		{"short text is not cut", "The cell wall", []string{"cell"}, 20, "The <mark>cell</mark> wall"},
+		{"window around match", "one two three four five six seven cell nine ten eleven twelve", []string{"cell"}, 20, "…even <mark>cell</mark> nine ten e…"},
+		{"window at the start", "cell two three four five six seven eight", []string{"cell"}, 12, "<mark>cell</mark> two thr…"},
+		{"window at the end", "one two three four five six seven cell", []string{"cell"}, 12, "…x seven <mark>cell</mark>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, tt.terms, tt.maxRunes); got != tt.want {
+				t.Errorf("Highlight(%q, %q, %d) = %q, want %q", tt.text, tt.terms, tt.maxRunes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightWindowLength(t *testing.T) {
+	text := strings.Repeat("word ", 100) + "target " + strings.Repeat("word ", 100)
+	got := Highlight(text, []string{"target"}, 40)
+	plain := strings.NewReplacer("<mark>", "", "</mark>", "", "…", "").Replace(got)
+	if n := len([]rune(plain)); n != 40 {
+		t.Errorf("window has %d runes, want 40: %q", n, got)
+	}
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("cut window %q lacks its ellipses", got)
+	}
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalSignedURL(t *testing.T) {
+	l := NewLocal(t.TempDir(), "https://quiz.example.com", "secret", time.Minute)
+	signed, err := l.SignedURL("quiz/a b.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(signed, "https://quiz.example.com/media/quiz/a%20b.png?") {
+		t.Errorf("SignedURL = %s, want it under /media", signed)
+	}
+	q := u.Query()
+	if !l.Verify("quiz/a b.png", q.Get("expires"), q.Get("signature")) {
+		t.Errorf("Verify rejects the URL SignedURL issued: %s", signed)
+	}
+}
+
+func TestLocalVerify(t *testing.T) {
+	l := NewLocal(t.TempDir(), "", "secret", time.Minute)
+	future := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+	past := strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		key       string
+		expires   string
+		signature string
+		want      bool
+	}{
+		{"valid", "a.png", future, l.sign("a.png", future), true},
+		{"expired", "a.png", past, l.sign("a.png", past), false},
+		{"tampered key", "b.png", future, l.sign("a.png", future), false},
+		{"extended expiry", "a.png", future, l.sign("a.png", past), false},
+		{"other secret", "a.png", future, NewLocal("", "", "other", time.Minute).sign("a.png", future), false},
+		{"no signature", "a.png", future, "", false},
+		{"bad expiry", "a.png", "soon", l.sign("a.png", "soon"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.Verify(tt.key, tt.expires, tt.signature); got != tt.want {
+				t.Errorf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Package storage keeps uploaded files, on the local filesystem or in an
+// S3-compatible bucket, and hands out signed URLs that fetch them for a
+// limited time.
+package storage
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Arasy41/go-gin-quiz-api/config"
+)
+
+// ErrNotFound is returned when opening an object that doesn't exist.
+var ErrNotFound = errors.New("storage: object not found")
+
+// ErrInvalidKey is returned for keys that are empty, absolute or climb out
+// of the storage root.
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// Storage stores objects under slash-separated keys.
+type Storage interface {
+	Put(key string, r io.Reader, size int64, contentType string) error
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes the object; deleting a missing object is not an error.
+	Delete(key string) error
+	// SignedURL returns a URL that fetches the object without further
+	// authentication until it expires.
+	SignedURL(key string) (string, error)
+}
+
+// New returns the storage selected by MEDIA_STORAGE in an already
+// validated config. Local storage signs its URLs with the secret key and
+// serves them under the public URL.
+func New(cfg *config.Config) (Storage, error) {
+	ttl := time.Duration(cfg.MediaURLTTLMinutes) * time.Minute
+	if cfg.MediaStorage == config.StorageS3 {
+		return NewS3(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+			TTL:       ttl,
+		})
+	}
+	return NewLocal(cfg.MediaDir, cfg.PublicURL, cfg.SecretKey, ttl), nil
+}
+
+// validKey reports whether key is a clean relative path without ".."
+// segments.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." || segment == "." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,23 @@
+package utils
+
+import "strings"
+
+// Slugify turns s into a URL slug: lowercase ASCII letters and digits
+// separated by single hyphens. Any other run of characters becomes one
+// hyphen, and leading or trailing hyphens are dropped.
+func Slugify(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
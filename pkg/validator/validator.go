@@ -1,20 +1,98 @@
 package validator
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/Arasy41/go-gin-quiz-api/pkg/apperror"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 )
 
 // NewValidator creates a new validator instance
 func NewValidator() *validator.Validate {
-	return validator.New()
+	v := validator.New()
+	v.RegisterTagNameFunc(jsonTagName)
+	return v
 }
 
 // ValidateStruct validates a struct using the provided validator instance
 func ValidateStruct(v *validator.Validate, s interface{}) error {
-	return v.Struct(s)
+	if err := v.Struct(s); err != nil {
+		return ValidationError(err)
+	}
+	return nil
+}
+
+// RegisterBindingTagNames makes gin's binding validator report fields by
+// their JSON names, matching ValidateStruct.
+func RegisterBindingTagNames() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonTagName)
+	}
 }
 
-// ValidateErrors validates the provided errors using the provided validator instance
-// func ValidateErrors(v *validator.Validate, errs validator.ValidationErrors) error {
-// 	return v.Struct(errs)
-// }
+// ValidationError converts a validator or request binding error into a
+// typed validation error with one entry per rejected field.
+func ValidationError(err error) error {
+	var validationErrs validator.ValidationErrors
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]apperror.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, apperror.FieldError{
+				Field:   fe.Field(),
+				Rule:    fe.Tag(),
+				Message: fieldMessage(fe),
+			})
+		}
+		return apperror.Validation("request validation failed", fields...)
+	case errors.Is(err, io.EOF):
+		return apperror.Validation("request body is required")
+	case errors.As(err, &syntaxErr):
+		return apperror.Validation("request body is not valid JSON")
+	case errors.As(err, &typeErr):
+		return apperror.Validation("request validation failed", apperror.FieldError{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type),
+		})
+	default:
+		return apperror.Validation("invalid request: " + err.Error())
+	}
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	default:
+		return "failed the " + fe.Tag() + " rule"
+	}
+}
+
+func jsonTagName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}